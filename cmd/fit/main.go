@@ -0,0 +1,187 @@
+package main
+
+import (
+	"MSCashItemExpected/cmd/internal/cli"
+	"MSCashItemExpected/internal/adapter"
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
+	"MSCashItemExpected/internal/usecase"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run 讀取抽取紀錄並檢定公告機率（或估計事後機率），回傳結束代碼
+func run(args []string) int {
+	fs := flag.NewFlagSet("fit", flag.ExitOnError)
+	eventPath := fs.String("event", "", "活動定義檔路徑（預設使用內嵌版本）")
+	logPath := fs.String("log", "", "抽取紀錄檔（CSV 或 JSON）")
+	pool := fs.String("pool", domain.PoolStage1, "紀錄未指定獎池時使用的獎池 (zodiac, stage1, stage2…)")
+	trials := fs.Int("trials", 0, "多項精確檢定無法列舉時的模擬次數（預設 10000）")
+	estimate := fs.Bool("estimate", false, "改以公告機率為先驗估計各道具的事後機率與可信區間")
+	priorWeight := fs.Float64("prior-weight", domain.DefaultPriorWeight, "公告機率先驗相當於的抽數（-estimate 時使用）")
+	credible := fs.Float64("credible", domain.DefaultCredibleLevel, "可信區間的機率（-estimate 時使用）")
+	seed := fs.Int64("seed", 0, "亂數種子（未指定時隨機產生，相同種子可重現結果）")
+	rngSource := fs.String("rng", usecase.SourceMathRand, "亂數來源 (mathrand, pcg)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "平行模擬的 worker 數量（不影響結果）")
+	formatName := fs.String("format", string(adapter.FormatTable), "輸出格式 (table, json, csv, markdown)")
+	fs.Parse(args)
+
+	format, err := adapter.ParseOutputFormat(*formatName)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	if *logPath == "" {
+		fmt.Println("請以 -log 指定抽取紀錄檔")
+		return 2
+	}
+
+	event, err := repository.LoadEvent(*eventPath)
+	if err != nil {
+		fmt.Printf("載入活動定義失敗: %v\n", err)
+		return 1
+	}
+	log, err := repository.LoadPullLog(*logPath, *pool)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	opts, err := cli.RNGOptions(fs, *seed, *rngSource)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	opts = append(opts, usecase.WithWorkers(*workers))
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	tester := usecase.NewRateTester(event, opts...)
+	if *estimate {
+		return runEstimate(tester, log, *priorWeight, *credible, format)
+	}
+
+	output, err := tester.Test(ctx, usecase.FitInput{Log: log, Trials: *trials})
+	if err != nil {
+		fmt.Printf("檢定失敗: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromFitOutput(output)
+		return cli.PrintReport(format, response, adapter.RateTestTables(response))
+	}
+
+	for _, test := range output.Tests {
+		printTest(test)
+	}
+	fmt.Printf("🎲 亂數種子: %d\n", output.Seed)
+	fmt.Println("  各道具 p 值為個別檢定，未做多重比較校正；p 值越小表示實際結果越不符合公告機率。")
+	fmt.Println()
+	return 0
+}
+
+// runEstimate 估計各獎池的事後機率並輸出
+func runEstimate(tester *usecase.RateTester, log domain.PullLog, priorWeight, credible float64, format adapter.OutputFormat) int {
+	output, err := tester.Estimate(usecase.EstimateInput{Log: log, PriorWeight: &priorWeight, Level: credible})
+	if err != nil {
+		fmt.Printf("估計失敗: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromEstimateOutput(output)
+		return cli.PrintReport(format, response, adapter.RateEstimateTables(response))
+	}
+
+	for _, posterior := range output.Posteriors {
+		printPosterior(posterior)
+	}
+	fmt.Println("  事後機率以公告機率為 Dirichlet 先驗，先驗強度越大越接近公告機率；可信區間為各道具的等尾區間。")
+	fmt.Println()
+	return 0
+}
+
+// printPosterior 印出單一獎池的事後機率
+func printPosterior(posterior domain.PosteriorRates) {
+	printSection(fmt.Sprintf("%s（%d 筆紀錄，共 %d 抽）", posterior.Name, posterior.Sessions, posterior.Total))
+
+	fmt.Printf("📐 先驗強度: 相當於 %.0f 抽符合公告機率的紀錄\n", posterior.PriorWeight)
+	fmt.Printf("🎯 可信區間: %.0f%%\n", posterior.Level*100)
+	fmt.Println()
+
+	fmt.Println("┌────────────────────────────────┬─────────┬────────┬──────────┬──────────┬─────────────────────┐")
+	fmt.Println("│ 道具名稱                       │ 公告機率│  實際  │ 事後平均 │  標準差  │      可信區間       │")
+	fmt.Println("├────────────────────────────────┼─────────┼────────┼──────────┼──────────┼─────────────────────┤")
+	for _, item := range posterior.Items {
+		fmt.Printf("│ %s │ %6.2f%% │ %6d │ %7.3f%% │ %7.3f%% │ %7.3f%% ~ %7.3f%% │\n",
+			truncateName(item.Item, 30),
+			item.Prior,
+			item.Observed,
+			item.Mean,
+			item.StdDev,
+			item.Lower,
+			item.Upper)
+	}
+	fmt.Println("└────────────────────────────────┴─────────┴────────┴──────────┴──────────┴─────────────────────┘")
+	fmt.Println()
+}
+
+// printTest 印出單一獎池的檢定結果
+func printTest(test domain.FitTest) {
+	printSection(fmt.Sprintf("%s（%d 筆紀錄，共 %d 抽）", test.Name, test.Sessions, test.Total))
+
+	fmt.Printf("📊 卡方檢定: χ² = %.3f（自由度 %d），p = %.4g\n", test.ChiSquare, test.DegreesOfFreedom, test.ChiSquarePValue)
+	if test.LowExpected > 0 {
+		fmt.Printf("   ⚠️ %d 個道具的期望數量低於 5，卡方近似可能不準確，請參考精確檢定\n", test.LowExpected)
+	}
+	if test.ExactMethod == domain.ExactMonteCarlo {
+		fmt.Printf("🎯 多項精確檢定: p = %.4g（蒙地卡羅模擬 %d 次）\n", test.ExactPValue, test.ExactTrials)
+	} else {
+		fmt.Printf("🎯 多項精確檢定: p = %.4g（列舉所有結果）\n", test.ExactPValue)
+	}
+	fmt.Println()
+
+	fmt.Println("┌────────────────────────────────┬─────────┬────────┬──────────┬─────────┬────────┬──────────┐")
+	fmt.Println("│ 道具名稱                       │ 公告機率│  實際  │   期望   │ 實際比例│  殘差  │  p 值    │")
+	fmt.Println("├────────────────────────────────┼─────────┼────────┼──────────┼─────────┼────────┼──────────┤")
+	for _, item := range test.Items {
+		fmt.Printf("│ %s │ %6.2f%% │ %6d │ %8.2f │ %6.2f%% │ %6.2f │ %8.4g │\n",
+			truncateName(item.Item, 30),
+			item.Probability,
+			item.Observed,
+			item.Expected,
+			item.ObservedRate,
+			item.Residual,
+			item.PValue)
+	}
+	fmt.Println("└────────────────────────────────┴─────────┴────────┴──────────┴─────────┴────────┴──────────┘")
+	fmt.Println()
+}
+
+func truncateName(name string, maxLen int) string {
+	runes := []rune(name)
+	if len(runes) <= maxLen {
+		return name + strings.Repeat(" ", maxLen-len(runes))
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+func printSection(title string) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Printf("  %s\n", title)
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Println()
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"MSCashItemExpected/cmd/internal/cli"
+	"MSCashItemExpected/internal/adapter"
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
+	"MSCashItemExpected/internal/usecase"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+)
+
+// 內建的活動模型
+const (
+	builtinStarlight = "starlight"
+	builtinZodiac    = "zodiac"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run 以多階段抽獎模型計算期望道具數量、精確分佈與模擬結果，回傳結束代碼
+func run(args []string) int {
+	fs := flag.NewFlagSet("gacha", flag.ExitOnError)
+	modelPath := fs.String("model", "", "抽獎模型定義檔（JSON），未指定時使用 -builtin 的活動")
+	builtin := fs.String("builtin", builtinStarlight, "未指定 -model 時使用的內建活動 (starlight, zodiac)")
+	eventPath := fs.String("event", "", "活動定義檔路徑（-builtin 使用，預設使用內嵌版本）")
+	draws := fs.Int("draws", 1000, "每次模擬抽入口獎池的次數")
+	pool := fs.String("pool", "", "計算精確分佈的獎池代號（預設為入口獎池）")
+	opens := fs.Int("opens", 0, "精確分佈的開啟次數（預設與 -draws 相同）")
+	trials := fs.Int("trials", 10000, "模擬次數")
+	pricesPath := fs.String("prices", repository.DefaultPricesPath, "道具價格檔（JSON 或 CSV）")
+	seed := fs.Int64("seed", 0, "亂數種子（未指定時隨機產生，相同種子可重現結果）")
+	rngSource := fs.String("rng", usecase.SourceMathRand, "亂數來源 (mathrand, pcg)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "平行模擬的 worker 數量（不影響結果）")
+	formatName := fs.String("format", string(adapter.FormatTable), "輸出格式 (table, json, csv, markdown)")
+	fs.Parse(args)
+
+	format, err := adapter.ParseOutputFormat(*formatName)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	if *draws < 0 || *trials <= 0 {
+		fmt.Println("抽數不可為負且模擬次數必須大於 0")
+		return 2
+	}
+
+	model, err := loadModel(*modelPath, *builtin, *eventPath)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	prices, err := repository.NewPriceFile(*pricesPath).Load()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	opts, err := cli.RNGOptions(fs, *seed, *rngSource)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	opts = append(opts, usecase.WithWorkers(*workers))
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// 1. 精確分佈（預設開啟入口獎池 -draws 次）
+	engine := usecase.NewGachaEngine(model, opts...)
+	if *pool == "" {
+		*pool = model.Entry
+	}
+	if *opens <= 0 {
+		*opens = *draws
+	}
+	dists, reach, err := engine.Distribution(*pool, *opens)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	// 2. 模擬抽 -draws 次並依配方合成
+	output, err := engine.Simulate(ctx, usecase.GachaSimulationInput{Draws: *draws, Trials: *trials, Prices: prices})
+	if err != nil {
+		fmt.Printf("模擬中斷: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromGachaOutput(model, *pool, *opens, dists, reach, output)
+		return cli.PrintReport(format, response, adapter.GachaTables(response))
+	}
+
+	printDistribution(model, *pool, *opens, dists, reach)
+	printSimulation(model, output)
+	return 0
+}
+
+// loadModel 載入抽獎模型：指定 -model 時讀取定義檔，否則由活動定義建立內建活動的模型
+func loadModel(modelPath, builtin, eventPath string) (domain.GachaModel, error) {
+	if modelPath != "" {
+		return repository.LoadGachaModel(modelPath)
+	}
+
+	event, err := repository.LoadEvent(eventPath)
+	if err != nil {
+		return domain.GachaModel{}, fmt.Errorf("載入活動定義失敗: %w", err)
+	}
+	switch builtin {
+	case builtinStarlight:
+		return event.Starlight.Model(), nil
+	case builtinZodiac:
+		return usecase.ZodiacModel(event.Zodiac), nil
+	default:
+		return domain.GachaModel{}, fmt.Errorf("未知的內建活動 %q（可用：%s, %s）", builtin, builtinStarlight, builtinZodiac)
+	}
+}
+
+// printDistribution 印出各獎池的抵達機率與開啟指定獎池的最終道具精確分佈
+func printDistribution(model domain.GachaModel, pool string, opens int, dists []domain.ItemDistribution, reach map[string]float64) {
+	name := pool
+	if p, ok := model.Pool(pool); ok {
+		name = p.Name
+	}
+	printSection(fmt.Sprintf("%s：開啟%s %d 次的精確分佈", model.Name, name, opens))
+
+	fmt.Println("┌────────────────────────────────┬────────────┐")
+	fmt.Println("│ 獎池                           │  抵達機率  │")
+	fmt.Println("├────────────────────────────────┼────────────┤")
+	for _, p := range model.Pools {
+		if r, ok := reach[p.ID]; ok {
+			fmt.Printf("│ %s │ %9.4f%% │\n", truncateName(p.Name, 30), r)
+		}
+	}
+	fmt.Println("└────────────────────────────────┴────────────┘")
+	fmt.Println()
+
+	fmt.Println("┌────────────────────────────────┬────────────┬──────────┬──────────┬──────────┐")
+	fmt.Println("│ 最終道具                       │  單次機率  │ 期望數量 │  標準差  │ 至少一個 │")
+	fmt.Println("├────────────────────────────────┼────────────┼──────────┼──────────┼──────────┤")
+	for _, dist := range dists {
+		fmt.Printf("│ %s │ %9.4f%% │ %8.2f │ %8.2f │ %7.2f%% │\n",
+			truncateName(dist.Name, 30),
+			dist.Probability,
+			dist.Count.Mean,
+			dist.Count.StdDev,
+			dist.Count.AtLeastOne)
+	}
+	fmt.Println("└────────────────────────────────┴────────────┴──────────┴──────────┴──────────┘")
+	fmt.Println()
+}
+
+// printSimulation 印出抽 Draws 次並合成後的期望數量、模擬數量分佈與總價值
+func printSimulation(model domain.GachaModel, output usecase.GachaSimulationOutput) {
+	printSection(fmt.Sprintf("%s：抽 %d 次並合成（模擬 %d 次）", model.Name, output.Draws, output.Trials))
+
+	fmt.Printf("💰 總成本: %.0f 點（每抽 %.0f 點）\n", float64(output.Draws)*model.CostPerDraw, model.CostPerDraw)
+	fmt.Println()
+
+	fmt.Println("┌────────────────────────────────┬──────────┬──────────┬──────────┬──────────┬──────────┐")
+	fmt.Println("│ 道具名稱                       │ 期望數量 │ 模擬平均 │  中位數  │    P5    │   P95    │")
+	fmt.Println("├────────────────────────────────┼──────────┼──────────┼──────────┼──────────┼──────────┤")
+	for _, item := range output.Items {
+		fmt.Printf("│ %s │ %8.2f │ %8.2f │ %8.0f │ %8.0f │ %8.0f │\n",
+			truncateName(item.Name, 30),
+			item.Expected,
+			item.Count.Mean,
+			item.Count.Median,
+			item.Count.Percentiles[5],
+			item.Count.Percentiles[95])
+	}
+	fmt.Println("└────────────────────────────────┴──────────┴──────────┴──────────┴──────────┴──────────┘")
+	fmt.Println()
+
+	fmt.Printf("📊 總價值: 平均 %.0f，中位數 %.0f，P5 %.0f，P95 %.0f\n",
+		output.Value.Mean, output.Value.Median, output.Value.Percentiles[5], output.Value.Percentiles[95])
+	fmt.Printf("🎲 亂數種子: %d\n", output.Seed)
+	fmt.Println("  期望數量將單一原料的配方視為可分割；需要多種原料的配方（如心願箱）只出現在模擬結果中。")
+	fmt.Println()
+}
+
+func truncateName(name string, maxLen int) string {
+	runes := []rune(name)
+	if len(runes) <= maxLen {
+		return name + strings.Repeat(" ", maxLen-len(runes))
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+func printSection(title string) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Printf("  %s\n", title)
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Println()
+}
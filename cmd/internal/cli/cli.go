@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"MSCashItemExpected/internal/adapter"
+	"MSCashItemExpected/internal/usecase"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// RNGOptions 依命令列 -seed 與 -rng 參數建立亂數選項，未指定 -seed 時隨機產生種子
+func RNGOptions(fs *flag.FlagSet, seed int64, source string) ([]usecase.Option, error) {
+	rngSource, err := usecase.ParseRNGSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []usecase.Option{usecase.WithSource(rngSource)}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			opts = append(opts, usecase.WithSeed(seed))
+		}
+	})
+	return opts, nil
+}
+
+// PrintReport 以表格以外的格式將結果寫至標準輸出（JSON 與 HTTP API 回應結構相同），回傳命令列結束代碼
+func PrintReport(format adapter.OutputFormat, v any, tables []adapter.Table) int {
+	if err := adapter.WriteReport(os.Stdout, format, v, tables); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"MSCashItemExpected/cmd/internal/cli"
+	"MSCashItemExpected/internal/adapter"
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
+	"MSCashItemExpected/internal/usecase"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run 依參數分配預算並印出結果，回傳結束代碼
+func run(args []string) int {
+	fs := flag.NewFlagSet("portfolio", flag.ExitOnError)
+	eventPath := fs.String("event", "", "活動定義檔路徑（預設使用內嵌版本）")
+	purchasePath := fs.String("purchase", "", "購買方式定義檔路徑（預設為不限面額的購買方式）")
+	budget := fs.Float64("budget", 10000, "總預算（台幣）")
+	method := fs.String("method", string(domain.MethodOriginal), "購買方式 (card, cardreader, original, gift)")
+	discount := fs.Float64("discount", 1, "點卡/送禮折數")
+	step := fs.Float64("step", 0, "分配金額的最小單位（預設為預算的 1/20）")
+	lambda := fs.Float64("lambda", 0, "風險趨避係數 λ，目標為 期望價值 − λ·標準差")
+	events := fs.String("events", usecase.PlanZodiac+","+usecase.PlanStarlight, "參與分配的活動（以逗號分隔）")
+	small := fs.Float64("small", 0, "小吉心願箱價值")
+	medium := fs.Float64("medium", 0, "中吉心願箱價值")
+	large := fs.Float64("large", 0, "大吉心願箱價值")
+	super := fs.Float64("super", 0, "超越心願箱價值")
+	pricesPath := fs.String("prices", repository.DefaultPricesPath, "道具價格檔（JSON 或 CSV），未指定 -small 等參數時亦以其中的心願箱價格為心願箱價值")
+	integerMerge := fs.Bool("integer-merge", false, "玲瓏星光以整數合成（不足一組保留並以其價格計價）")
+	trials := fs.Int("trials", 0, "新年氣息估計標準差的模擬次數（預設 500）")
+	seed := fs.Int64("seed", 0, "亂數種子（未指定時隨機產生，相同種子可重現結果）")
+	rngSource := fs.String("rng", usecase.SourceMathRand, "亂數來源 (mathrand, pcg)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "平行模擬的 worker 數量（不影響結果）")
+	formatName := fs.String("format", string(adapter.FormatTable), "輸出格式 (table, json, csv, markdown)")
+	fs.Parse(args)
+
+	format, err := adapter.ParseOutputFormat(*formatName)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	event, err := repository.LoadEvent(*eventPath)
+	if err != nil {
+		fmt.Printf("載入活動定義失敗: %v\n", err)
+		return 1
+	}
+	purchases, err := repository.LoadPurchases(*purchasePath)
+	if err != nil {
+		fmt.Printf("載入購買方式失敗: %v\n", err)
+		return 1
+	}
+	prices, err := repository.NewPriceFile(*pricesPath).Load()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	opts, err := cli.RNGOptions(fs, *seed, *rngSource)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	opts = append(opts, usecase.WithWorkers(*workers), usecase.WithPurchases(purchases))
+
+	// 建立各活動的價值模型
+	calculator := usecase.NewCalculator(event.Zodiac, opts...)
+	var models []usecase.ValueModel
+	for _, name := range strings.Split(*events, ",") {
+		switch strings.TrimSpace(name) {
+		case usecase.PlanZodiac:
+			values := boxValues(fs, prices, *small, *medium, *large, *super)
+			models = append(models, usecase.NewZodiacModel(calculator, values, domain.NewBreathCollection(), *trials))
+		case usecase.PlanStarlight:
+			models = append(models, usecase.NewStarlightModel(usecase.NewStarlightCalculator(event.Starlight, opts...), prices, *integerMerge))
+		default:
+			fmt.Printf("未知的活動 %q\n", name)
+			return 2
+		}
+	}
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	output, err := usecase.Allocate(ctx, usecase.AllocationInput{
+		Budget:       *budget,
+		Method:       domain.PurchaseMethod(*method),
+		Discount:     *discount,
+		Step:         *step,
+		RiskAversion: *lambda,
+	}, models)
+	if err != nil {
+		fmt.Printf("分配失敗: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromAllocationOutput(output)
+		response.Seed = calculator.Seed()
+		return cli.PrintReport(format, response, adapter.PortfolioTables(response))
+	}
+
+	printSection(fmt.Sprintf("預算分配（λ = %g）", *lambda))
+
+	fmt.Printf("💰 總預算: %.0f 元（分配單位 %.0f 元，比較 %d 種組合）\n", *budget, output.Step, output.Candidates)
+	fmt.Printf("🎲 亂數種子: %d\n", calculator.Seed())
+	fmt.Println()
+
+	fmt.Println("┌────────────┬────────────┬────────────┬──────────────┬──────────────┐")
+	fmt.Println("│ 活動       │  投入金額  │  實際花費  │   期望價值   │    標準差    │")
+	fmt.Println("├────────────┼────────────┼────────────┼──────────────┼──────────────┤")
+	for _, allocation := range output.Allocations {
+		fmt.Printf("│ %-10s │ %10.0f │ %10.0f │ %12.2f │ %12.2f │\n",
+			allocation.Event,
+			allocation.Investment,
+			allocation.Cost,
+			allocation.Mean,
+			allocation.StdDev)
+	}
+	fmt.Printf("│ 未花費     │ %10s │ %10.0f │ %12.2f │ %12s │\n", "-", output.Unspent, output.Unspent, "-")
+	fmt.Println("├────────────┼────────────┼────────────┼──────────────┼──────────────┤")
+	fmt.Printf("│ 合計       │ %10.0f │ %10s │ %12.2f │ %12.2f │\n", *budget, "-", output.Mean, output.StdDev)
+	fmt.Println("└────────────┴────────────┴────────────┴──────────────┴──────────────┘")
+	fmt.Println()
+
+	fmt.Printf("  期望報酬率（以實際花費計）: %.2f%%\n", output.ROI)
+	fmt.Printf("  目標值（期望總價值 − λ·標準差）: %.2f\n", output.Objective)
+	fmt.Println()
+
+	return 0
+}
+
+// boxValues 以價格檔中的心願箱價值為預設，命令列有指定的心願箱價值優先
+func boxValues(fs *flag.FlagSet, prices domain.PriceBook, small, medium, large, super float64) domain.BoxValues {
+	values := prices.BoxValues()
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "small":
+			values.Small = small
+		case "medium":
+			values.Medium = medium
+		case "large":
+			values.Large = large
+		case "super":
+			values.Super = super
+		}
+	})
+	return values
+}
+
+func printSection(title string) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Printf("  %s\n", title)
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Println()
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"MSCashItemExpected/cmd/internal/cli"
+	"MSCashItemExpected/internal/adapter"
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
+	"MSCashItemExpected/internal/usecase"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+)
+
+func printUsage() {
+	fmt.Println("新楓之谷 星光錦囊 期望值計算器 & 模擬器")
+	fmt.Println()
+	fmt.Println("用法:")
+	fmt.Println("  starlight [參數]             互動模式（依提示輸入投入金額與道具價值）")
+	fmt.Println("  starlight expected [參數]    計算投入金額展開所有階段後的期望道具、期望價值與報酬率")
+	fmt.Println("  starlight simulate [參數]    模擬開啟第一階段錦囊並以所得玲瓏星光開啟階梯")
+	fmt.Println("  starlight ladder [參數]      開啟星光結晶體的精確分佈與模擬")
+	fmt.Println("  starlight luck [參數]        計算實際結果在相同花費模擬分佈中的百分位")
+	fmt.Println("  starlight validate [參數]    驗證活動定義檔")
+	fmt.Println()
+	fmt.Println("執行 starlight <子命令> -h 查看參數說明")
+}
+
+// commandFlags 子命令共用參數
+type commandFlags struct {
+	fs           *flag.FlagSet
+	eventPath    *string
+	purchasePath *string
+	seed         *int64
+	rngSource    *string
+	workers      *int
+	format       *string
+	ratesLog     *string
+	rates        *string
+	priorWeight  *float64
+}
+
+// newCommandFlags 建立子命令的參數集並加入共用參數
+func newCommandFlags(name string) *commandFlags {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	return &commandFlags{
+		fs:           fs,
+		eventPath:    fs.String("event", "", "活動定義檔路徑（預設使用內嵌版本）"),
+		purchasePath: fs.String("purchase", "", "購買方式定義檔路徑（預設為不限面額的購買方式）"),
+		seed:         fs.Int64("seed", 0, "亂數種子（未指定時隨機產生，相同種子可重現結果）"),
+		rngSource:    fs.String("rng", usecase.SourceMathRand, "亂數來源 (mathrand, pcg)"),
+		workers:      fs.Int("workers", runtime.GOMAXPROCS(0), "平行模擬的 worker 數量（不影響結果）"),
+		format:       fs.String("format", string(adapter.FormatTable), "輸出格式 (table, json, csv, markdown)"),
+		ratesLog:     fs.String("rates-log", "", "抽取紀錄檔，指定時以事後機率取代紀錄中各獎池的公告機率"),
+		rates:        fs.String("rates", usecase.RatesMean, "使用 -rates-log 時的機率 (mean: 事後平均, sample: 由事後分佈抽樣)"),
+		priorWeight:  fs.Float64("prior-weight", domain.DefaultPriorWeight, "公告機率先驗相當於的抽數"),
+	}
+}
+
+// outputFormat 取得 -format 指定的輸出格式
+func (f *commandFlags) outputFormat() (adapter.OutputFormat, error) {
+	return adapter.ParseOutputFormat(*f.format)
+}
+
+// calculator 依共用參數載入活動定義與購買方式並建立計算器
+func (f *commandFlags) calculator() (*usecase.StarlightCalculator, error) {
+	event, err := repository.LoadEvent(*f.eventPath)
+	if err != nil {
+		return nil, fmt.Errorf("載入活動定義失敗: %w", err)
+	}
+	purchases, err := repository.LoadPurchases(*f.purchasePath)
+	if err != nil {
+		return nil, fmt.Errorf("載入購買方式失敗: %w", err)
+	}
+
+	opts, err := cli.RNGOptions(f.fs, *f.seed, *f.rngSource)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, usecase.WithWorkers(*f.workers), usecase.WithPurchases(purchases))
+
+	event, err = f.posteriorEvent(event, opts)
+	if err != nil {
+		return nil, err
+	}
+	return usecase.NewStarlightCalculator(event.Starlight, opts...), nil
+}
+
+// posteriorEvent 指定 -rates-log 時，以事後平均或抽樣機率取代紀錄中各獎池的公告機率
+func (f *commandFlags) posteriorEvent(event domain.EventDefinition, opts []usecase.Option) (domain.EventDefinition, error) {
+	if *f.ratesLog == "" {
+		return event, nil
+	}
+	log, err := repository.LoadPullLog(*f.ratesLog, domain.PoolStage1)
+	if err != nil {
+		return domain.EventDefinition{}, err
+	}
+	input := usecase.EstimateInput{Log: log, PriorWeight: f.priorWeight}
+	return usecase.NewRateTester(event, opts...).PosteriorEvent(context.Background(), input, *f.rates)
+}
+
+// runExpected 執行 expected 子命令
+func runExpected(args []string) int {
+	f := newCommandFlags("expected")
+	investment := f.fs.Float64("investment", 10000, "投入金額（台幣）")
+	method := f.fs.String("method", string(domain.MethodOriginal), "購買方式 (card, cardreader, original, gift)")
+	discount := f.fs.Float64("discount", 1, "點卡/送禮折數")
+	pricesPath := f.fs.String("prices", repository.DefaultPricesPath, "道具價格檔（JSON：道具名稱 -> 價格）")
+	trials := f.fs.Int("trials", 0, "估計百分位數的模擬次數（預設 2000）")
+	integerMerge := f.fs.Bool("integer-merge", false, "玲瓏星光以整數合成（不足一組保留並以其價格計價）")
+	integerDraws := f.fs.Bool("integer-draws", false, "以整數抽數計算（不足一抽的點數保留為剩餘點數）")
+	carryPoints := f.fs.Float64("carry", 0, "前次購買或其他活動結轉的點數")
+	f.fs.Parse(args)
+
+	format, err := f.outputFormat()
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	calculator, err := f.calculator()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	prices, err := repository.NewPriceFile(*pricesPath).Load()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	output, err := calculator.CalculateExpected(ctx, usecase.StarlightExpectedInput{
+		Investment:   *investment,
+		Method:       domain.PurchaseMethod(*method),
+		Discount:     *discount,
+		Prices:       prices,
+		Trials:       *trials,
+		Integer:      *integerMerge,
+		IntegerDraws: *integerDraws,
+		CarryPoints:  *carryPoints,
+	})
+	if err != nil {
+		fmt.Printf("計算中斷: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromStarlightExpectedOutput(output)
+		return cli.PrintReport(format, response, adapter.StarlightExpectedTables(response, prices))
+	}
+
+	printSection("期望值計算結果")
+
+	fmt.Printf("💰 投入金額: %.0f 元（實際花費 %.0f 元）\n", *investment, output.Purchase.Cost)
+	fmt.Printf("🎯 可得點數: %.0f 點\n", output.Points)
+	fmt.Printf("🎰 可抽次數: %.2f 次（每抽 %.2f 元）\n", output.DrawCount, output.CostPerDraw)
+	if *integerDraws {
+		fmt.Printf("🪙 剩餘點數: %.0f 點（可結轉至下次購買或其他活動）\n", output.Residual)
+	}
+	fmt.Printf("🎲 亂數種子: %d\n", output.Seed)
+	fmt.Println()
+
+	fmt.Println("┌────────────────────────────────┬────────────┬──────────┬────────────┐")
+	fmt.Println("│ 道具名稱                       │  期望數量  │  單價    │  期望價值  │")
+	fmt.Println("├────────────────────────────────┼────────────┼──────────┼────────────┤")
+	for _, item := range output.ExpectedItems {
+		priceStr := "-"
+		evStr := "-"
+		if price := prices[item.Name]; price > 0 {
+			priceStr = fmt.Sprintf("%d", price)
+			evStr = fmt.Sprintf("%.2f", item.Expected*float64(price))
+		}
+		fmt.Printf("│ %-30s │ %10.3f │ %8s │ %10s │\n",
+			truncateName(item.Name, 30),
+			item.Expected,
+			priceStr,
+			evStr)
+	}
+	fmt.Println("├────────────────────────────────┼────────────┼──────────┼────────────┤")
+	fmt.Printf("│ %-30s │    ---     │   ---    │ %10.2f │\n", "【期望總價值】", output.ExpectedValue)
+	fmt.Println("└────────────────────────────────┴────────────┴──────────┴────────────┘")
+	fmt.Println()
+
+	fmt.Println("【投資報酬分析】")
+	fmt.Printf("  實際花費: %.0f 元\n", output.Purchase.Cost)
+	fmt.Printf("  期望回收: %.2f 元\n", output.ExpectedValue)
+	fmt.Printf("  期望報酬率: %+.2f%%\n", output.ROI)
+	fmt.Println()
+
+	printMerge(output.Merge, calculator.Event().CrystalItem)
+	printRisk(output.ExpectedValue, output.Risk)
+	return 0
+}
+
+// runSimulate 執行 simulate 子命令
+func runSimulate(args []string) int {
+	f := newCommandFlags("simulate")
+	investment := f.fs.Float64("investment", 10000, "投入金額（台幣），未指定 -draws 時依此計算抽數")
+	method := f.fs.String("method", string(domain.MethodOriginal), "購買方式 (card, cardreader, original, gift)")
+	discount := f.fs.Float64("discount", 1, "點卡/送禮折數")
+	draws := f.fs.Int("draws", 0, "開啟次數（指定時忽略 -investment）")
+	f.fs.Parse(args)
+
+	format, err := f.outputFormat()
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	calculator, err := f.calculator()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	event := calculator.Event()
+
+	// 未指定開啟次數時，以投入金額可得點數計算整數抽數
+	drawCount := *draws
+	if drawCount <= 0 {
+		purchase := calculator.Purchases().Buy(*investment, domain.PurchaseMethod(*method), *discount)
+		drawCount = domain.PlanDraws(purchase.Points, float64(event.CostPerDraw)).Draws
+	}
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	simResult, err := calculator.SimulateStage1(ctx, drawCount, event.Stage1Pool)
+	if err != nil {
+		fmt.Printf("模擬中斷: %v\n", err)
+		return 1
+	}
+
+	// 以模擬所得玲瓏星光合成星光結晶體（整數合成，不足一組保留）
+	ladderCount := simResult.CrystalCount / event.CrystalsPerMerge
+	leftover := simResult.CrystalCount % event.CrystalsPerMerge
+	ladderResult, err := calculator.SimulateLadder(ctx, ladderCount)
+	if err != nil {
+		fmt.Printf("模擬中斷: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromSimulationResult(
+			simResult,
+			ladderResult,
+			calculator.CalculateSurvivalRate(ladderResult),
+			calculator.CalculateTheoreticalSurvival(),
+		)
+		response.LeftoverCrystals = leftover
+		response.Seed = calculator.Seed()
+		return cli.PrintReport(format, response, adapter.StarlightSimulateTables(response))
+	}
+
+	printSection(fmt.Sprintf("第一階段模擬器（模擬 %d 次開啟）", drawCount))
+	fmt.Printf("🎲 亂數種子: %d\n", calculator.Seed())
+	fmt.Println()
+	printStage1Result(simResult)
+
+	printSection(fmt.Sprintf("階梯模擬（%d 個星光結晶體，剩餘 %d 個%s）", ladderCount, leftover, event.CrystalItem))
+	if ladderCount > 0 {
+		printLadderResult(calculator, ladderResult)
+	}
+	return 0
+}
+
+// runLadder 執行 ladder 子命令
+func runLadder(args []string) int {
+	f := newCommandFlags("ladder")
+	crystals := f.fs.Int("crystals", 1000, "星光結晶體數量")
+	trials := f.fs.Int("trials", 1, "模擬開啟 crystals 個星光結晶體的次數（0 表示只計算精確分佈）")
+	f.fs.Parse(args)
+
+	if *crystals <= 0 || *trials < 0 {
+		fmt.Println("星光結晶體數量必須大於 0，模擬次數不可為負")
+		return 2
+	}
+
+	format, err := f.outputFormat()
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	calculator, err := f.calculator()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if format != adapter.FormatTable {
+		report := adapter.LadderReportDTO{
+			StarlightLadderResponse: adapter.FromLadderDistribution(calculator.CalculateLadderDistribution(*crystals)),
+			Simulations:             []adapter.LadderResponse{},
+			Seed:                    calculator.Seed(),
+		}
+		for t := 0; t < *trials; t++ {
+			result, err := calculator.SimulateLadder(ctx, *crystals)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "模擬中斷: %v\n", err)
+				return 1
+			}
+			report.Simulations = append(report.Simulations, adapter.FromLadderResult(
+				result,
+				calculator.CalculateSurvivalRate(result),
+				calculator.CalculateTheoreticalSurvival(),
+			))
+		}
+		return cli.PrintReport(format, report, adapter.StarlightLadderTables(report))
+	}
+
+	if *trials == 0 {
+		printSection(fmt.Sprintf("階梯精確分佈（%d 個星光結晶體）", *crystals))
+		printLadderDistribution(calculator.Event(), calculator.CalculateLadderDistribution(*crystals))
+		return 0
+	}
+
+	for t := 1; t <= *trials; t++ {
+		result, err := calculator.SimulateLadder(ctx, *crystals)
+		if err != nil {
+			fmt.Printf("模擬中斷: %v\n", err)
+			return 1
+		}
+		printSection(fmt.Sprintf("階梯模擬 %d/%d（%d 個星光結晶體，種子 %d）", t, *trials, *crystals, calculator.Seed()))
+		printLadderResult(calculator, result)
+	}
+	return 0
+}
+
+// printLadderDistribution 印出階梯各階段的到達機率與各最終獎品的期望數量
+func printLadderDistribution(event domain.StarlightEvent, dist usecase.LadderDistribution) {
+	fmt.Println("┌──────┬──────────────────────┬──────────────┬──────────────┐")
+	fmt.Println("│ 階段 │ 開啟道具             │   到達機率   │   升級機率   │")
+	fmt.Println("├──────┼──────────────────────┼──────────────┼──────────────┤")
+	for _, stage := range dist.Stages {
+		fmt.Printf("│ %4d │ %-20s │ %11.2f%% │ %11.2f%% │\n",
+			stage.Stage,
+			truncateName(event.StageItem(stage.Stage), 20),
+			stage.Reach,
+			stage.Upgrade)
+	}
+	fmt.Println("└──────┴──────────────────────┴──────────────┴──────────────┘")
+	fmt.Println()
+
+	fmt.Printf("  理論存活率: %.2f%%\n", dist.Survival)
+	fmt.Printf("  %s期望數量: %.2f 個（標準差 %.2f，至少一個機率 %.2f%%）\n",
+		dist.FinalItem, dist.Final.Mean, dist.Final.StdDev, dist.Final.AtLeastOne)
+	fmt.Println()
+
+	fmt.Println("┌────────────────────────────────┬────────────┬────────────┐")
+	fmt.Println("│ 道具名稱                       │  期望數量  │   標準差   │")
+	fmt.Println("├────────────────────────────────┼────────────┼────────────┤")
+	for _, reward := range dist.Rewards {
+		fmt.Printf("│ %-30s │ %10.2f │ %10.2f │\n",
+			truncateName(reward.Name, 30),
+			reward.Count.Mean,
+			reward.Count.StdDev)
+	}
+	fmt.Println("└────────────────────────────────┴────────────┴────────────┘")
+	fmt.Println()
+}
+
+// runLuck 執行 luck 子命令
+func runLuck(args []string) int {
+	f := newCommandFlags("luck")
+	investment := f.fs.Float64("investment", 10000, "投入金額（台幣），用於計算抽數與報酬率")
+	method := f.fs.String("method", string(domain.MethodOriginal), "購買方式 (card, cardreader, original, gift)")
+	discount := f.fs.Float64("discount", 1, "點卡/送禮折數")
+	draws := f.fs.Int("draws", 0, "實際開啟次數（預設依投入金額計算）")
+	outcomePath := f.fs.String("outcome", "", "實際結果檔（JSON 或 CSV：道具名稱 -> 數量，開啟所有星光結晶體之後）")
+	pricesPath := f.fs.String("prices", repository.DefaultPricesPath, "道具價格檔（JSON 或 CSV）")
+	trials := f.fs.Int("trials", 0, "模擬次數（預設 10000）")
+	integerMerge := f.fs.Bool("integer-merge", false, "未合成的玲瓏星光以其價格計價（否則以期望價值計）")
+	carryPoints := f.fs.Float64("carry", 0, "前次購買或其他活動結轉的點數")
+	f.fs.Parse(args)
+
+	format, err := f.outputFormat()
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	if *outcomePath == "" {
+		fmt.Println("請以 -outcome 指定實際結果檔")
+		return 2
+	}
+	calculator, err := f.calculator()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	outcome, err := repository.LoadOutcome(*outcomePath)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	prices, err := repository.NewPriceFile(*pricesPath).Load()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	output, err := calculator.Luck(ctx, usecase.StarlightLuckInput{
+		Investment:  *investment,
+		Method:      domain.PurchaseMethod(*method),
+		Discount:    *discount,
+		Draws:       *draws,
+		Outcome:     outcome,
+		Prices:      prices,
+		Trials:      *trials,
+		Integer:     *integerMerge,
+		CarryPoints: *carryPoints,
+	})
+	if err != nil {
+		fmt.Printf("計算失敗: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromStarlightLuckOutput(output)
+		return cli.PrintReport(format, response, adapter.LuckTables(response))
+	}
+
+	printSection(fmt.Sprintf("運氣百分位（%d 抽，模擬 %d 次）", output.DrawCount, output.Luck.Trials))
+	fmt.Printf("💰 實際花費: %.0f 元\n", output.Purchase.Cost)
+	fmt.Printf("🎲 亂數種子: %d\n", output.Seed)
+	fmt.Println()
+	printLuck(output.Luck)
+	return 0
+}
+
+// printLuck 印出實際結果在模擬分佈中的位置
+func printLuck(luck usecase.LuckReport) {
+	fmt.Println("【實際結果】")
+	fmt.Printf("  實際總價值: %.2f 元（報酬率 %+.2f%%）\n", luck.Realized, luck.RealizedROI)
+	fmt.Printf("  模擬平均: %.2f 元（報酬率 %+.2f%%）\n", luck.Value.Mean, luck.ROI.Mean)
+	fmt.Printf("  百分位: %.2f（%.2f%% 的模擬結果較差，%.2f%% 較好）\n", luck.Percentile, luck.Worse, luck.Better)
+	fmt.Println()
+
+	fmt.Println("┌────────┬──────────────┬──────────────┐")
+	fmt.Println("│ 百分位 │    總價值    │    報酬率    │")
+	fmt.Println("├────────┼──────────────┼──────────────┤")
+	for _, p := range domain.SummaryPercentiles {
+		fmt.Printf("│ P%-5d │ %12.2f │ %11.2f%% │\n", p, luck.Value.Percentiles[p], luck.ROI.Percentiles[p])
+	}
+	fmt.Println("└────────┴──────────────┴──────────────┘")
+	fmt.Println()
+}
@@ -1,39 +1,88 @@
 package main
 
 import (
+	"MSCashItemExpected/cmd/internal/cli"
 	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
 	"MSCashItemExpected/internal/usecase"
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 )
 
-// 需要輸入價值的道具（第一階段有價值道具）
-var valuableItems = []string{
-	"傳說潛在能力卷軸50%",
-	"傳說潛在能力卷軸100%",
-	"星力14星強化券",
-	"星力15星強化券",
-	"星力16星強化券",
-	"星力17星強化券",
-	"星力18星強化券",
-	"星力19星強化券",
-	"星力20星強化券",
-	"突破1星強化券100%(21星)",
-	"突破1星強化券100%(22星)",
-	"追加1星強化券30%(23星)",
-}
-
 func main() {
-	calculator := usecase.NewStarlightCalculator()
+	// 子命令（未指定子命令時進入互動模式）
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		switch os.Args[1] {
+		case "expected":
+			os.Exit(runExpected(os.Args[2:]))
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		case "ladder":
+			os.Exit(runLadder(os.Args[2:]))
+		case "luck":
+			os.Exit(runLuck(os.Args[2:]))
+		case "validate":
+			os.Exit(runValidate(os.Args[2:]))
+		default:
+			printUsage()
+			os.Exit(2)
+		}
+	}
+
+	eventPath := flag.String("event", "", "活動定義檔路徑（預設使用內嵌版本）")
+	seed := flag.Int64("seed", 0, "亂數種子（未指定時隨機產生，相同種子可重現結果）")
+	rngSource := flag.String("rng", usecase.SourceMathRand, "亂數來源 (mathrand, pcg)")
+	integerMerge := flag.Bool("integer-merge", false, "玲瓏星光以整數合成（不足一組保留並以其價格計價）")
+	workers := flag.Int("workers", runtime.GOMAXPROCS(0), "平行模擬的 worker 數量（不影響結果）")
+	integerDraws := flag.Bool("integer-draws", false, "以整數抽數計算（不足一抽的點數保留為剩餘點數）")
+	carryPoints := flag.Float64("carry", 0, "前次購買或其他活動結轉的點數")
+	pricesPath := flag.String("prices", repository.DefaultPricesPath, "道具價格檔（JSON 或 CSV），輸入價值時以檔案中的價格為預設值")
+	savePrices := flag.Bool("save-prices", false, "將輸入的道具價值寫回 -prices 指定的價格檔")
+	flag.Parse()
+
+	if *savePrices && *pricesPath == "" {
+		fmt.Println("-save-prices 需要以 -prices 指定價格檔")
+		os.Exit(2)
+	}
+
+	event, err := repository.LoadEvent(*eventPath)
+	if err != nil {
+		fmt.Printf("載入活動定義失敗: %v\n", err)
+		os.Exit(1)
+	}
+
+	book, err := repository.NewPriceFile(*pricesPath).Load()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	opts, err := cli.RNGOptions(flag.CommandLine, *seed, *rngSource)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	opts = append(opts, usecase.WithWorkers(*workers))
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	calculator := usecase.NewStarlightCalculator(event.Starlight, opts...)
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║          新楓之谷 星光錦囊 期望值計算器 & 模擬器              ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
+	fmt.Printf("🎲 亂數種子: %d\n", calculator.Seed())
 	fmt.Println()
 
 	// ===========================================
@@ -49,10 +98,21 @@ func main() {
 		fmt.Printf("使用預設值: %.0f 元\n", investment)
 	}
 
-	// 計算抽數（假設原價購買）
-	drawCount := investment / float64(domain.StarlightCost)
+	// 計算抽數（假設原價購買，加上結轉點數）
+	points := investment + *carryPoints
+	drawCount := points / float64(event.Starlight.CostPerDraw)
 	fmt.Printf("\n💰 投入金額: %.0f 元\n", investment)
-	fmt.Printf("🎰 預計抽數: %.2f 次\n", drawCount)
+	if *carryPoints > 0 {
+		fmt.Printf("🔁 結轉點數: %.0f 點\n", *carryPoints)
+	}
+	if *integerDraws {
+		plan := domain.PlanDraws(points, float64(event.Starlight.CostPerDraw))
+		drawCount = float64(plan.Draws)
+		fmt.Printf("🎰 可抽次數: %d 次\n", plan.Draws)
+		fmt.Printf("🪙 剩餘點數: %.0f 點（可結轉至下次購買或其他活動）\n", plan.Residual)
+	} else {
+		fmt.Printf("🎰 預計抽數: %.2f 次\n", drawCount)
+	}
 	fmt.Println()
 
 	// ===========================================
@@ -60,22 +120,55 @@ func main() {
 	// ===========================================
 	printSection("道具價值設定（台幣）")
 
-	fmt.Println("請輸入各道具的市場價值，直接按 Enter 表示價值為 0")
+	if *pricesPath != "" {
+		fmt.Println("請輸入各道具的市場價值，直接按 Enter 沿用價格檔中的價格（括號內，未設定時為 0）")
+	} else {
+		fmt.Println("請輸入各道具的市場價值，直接按 Enter 表示價值為 0")
+	}
 	fmt.Println()
 
 	prices := make(map[string]int)
 
-	for _, item := range valuableItems {
-		fmt.Printf("  %s: ", item)
+	// 依活動定義需要輸入價值的道具（含階梯獎品），整數合成時剩餘的玲瓏星光需另行計價
+	pricedItems := append([]string{}, event.Starlight.ValuableItems...)
+	if *integerMerge {
+		pricedItems = append(pricedItems, event.Starlight.CrystalItem)
+	}
+
+	for _, item := range pricedItems {
+		if price, ok := book[item]; ok {
+			fmt.Printf("  %s [%d]: ", item, price)
+		} else {
+			fmt.Printf("  %s: ", item)
+		}
 		priceStr, _ := reader.ReadString('\n')
 		priceStr = strings.TrimSpace(priceStr)
-		if priceStr != "" {
-			if price, err := strconv.Atoi(priceStr); err == nil && price > 0 {
-				prices[item] = price
+		if priceStr == "" {
+			if book[item] > 0 {
+				prices[item] = book[item]
 			}
+			continue
+		}
+		if price, err := strconv.Atoi(priceStr); err == nil && price > 0 {
+			prices[item] = price
 		}
 	}
 
+	// 將輸入的價值寫回價格檔（保留價格檔中其他道具的價格）
+	if *savePrices {
+		if book == nil {
+			book = domain.PriceBook{}
+		}
+		for _, item := range pricedItems {
+			book[item] = prices[item]
+		}
+		if err := repository.SavePrices(*pricesPath, book); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n💾 已儲存道具價格至 %s\n", *pricesPath)
+	}
+
 	// ===========================================
 	// 第三部分：期望值計算結果
 	// ===========================================
@@ -83,7 +176,7 @@ func main() {
 
 	// 建立機率對照表
 	rateMap := make(map[string]float64)
-	for _, r := range domain.Stage1Pool {
+	for _, r := range event.Starlight.Stage1Pool {
 		rateMap[r.Name] = r.Probability
 	}
 
@@ -91,9 +184,13 @@ func main() {
 	fmt.Println("│ 道具名稱                       │  機率   │  單價    │  期望價值  │")
 	fmt.Println("├────────────────────────────────┼─────────┼──────────┼────────────┤")
 
+	// 第一階段可直接抽到的有價值道具
 	var totalEV float64
-	for _, item := range valuableItems {
-		rate := rateMap[item]
+	for _, item := range event.Starlight.ValuableItems {
+		rate, ok := rateMap[item]
+		if !ok {
+			continue
+		}
 		price := prices[item]
 		expected := drawCount * (rate / 100)
 		itemEV := expected * float64(price)
@@ -131,6 +228,15 @@ func main() {
 	fmt.Printf("  期望報酬率: %s%.2f%%\n", roiSign, roi)
 	fmt.Println()
 
+	// 分散程度（展開玲瓏星光階梯）
+	risk, err := calculator.CalculateRisk(ctx, drawCount, investment, prices, 0, *integerMerge)
+	if err != nil {
+		fmt.Printf("模擬中斷: %v\n", err)
+		return
+	}
+	printMerge(calculator.CalculateMerge(drawCount, *integerMerge), event.Starlight.CrystalItem)
+	printRisk(calculator.CalculateMergedEV(drawCount, prices, *integerMerge), risk)
+
 	// ===========================================
 	// 第四部分：模擬器
 	// ===========================================
@@ -146,8 +252,58 @@ func main() {
 	// 第一階段模擬
 	printSection("第一階段模擬器（模擬 1000 次開啟）")
 
-	simResult := calculator.SimulateStage1(1000, domain.Stage1Pool)
+	simResult, err := calculator.SimulateStage1(ctx, 1000, event.Starlight.Stage1Pool)
+	if err != nil {
+		fmt.Printf("模擬中斷: %v\n", err)
+		return
+	}
+
+	printStage1Result(simResult)
+
+	// 階梯升級模擬器
+	printSection("大量階梯模擬（1000 個星光結晶體）")
+
+	largeLadderResult, err := calculator.SimulateLadder(ctx, 1000)
+	if err != nil {
+		fmt.Printf("模擬中斷: %v\n", err)
+		return
+	}
+	printLadderResult(calculator, largeLadderResult)
+}
+
+func printSection(title string) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Printf("  %s\n", title)
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Println()
+}
+
+func truncateName(name string, maxLen int) string {
+	runes := []rune(name)
+	if len(runes) <= maxLen {
+		return name + strings.Repeat(" ", maxLen-len(runes))
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// padLabel 以顯示寬度（全形字元佔兩格）將標籤補齊至 width 格
+func padLabel(label string, width int) string {
+	w := 0
+	for _, r := range label {
+		if r < 0x80 {
+			w++
+		} else {
+			w += 2
+		}
+	}
+	if w >= width {
+		return label
+	}
+	return label + strings.Repeat(" ", width-w)
+}
 
+func printStage1Result(simResult domain.SimulationResult) {
 	// 按數量排序
 	type itemCount struct {
 		name  string
@@ -186,70 +342,70 @@ func main() {
 	fmt.Printf("  實際獲得數量: %d 個\n", simResult.CrystalCount)
 	fmt.Printf("  偏差: %+.2f (%.2f%%)\n", deviation, deviationPct)
 	fmt.Println()
-
-	// 階梯升級模擬器
-	printSection("大量階梯模擬（1000 個星光結晶體）")
-
-	largeLadderResult := calculator.SimulateLadder(1000)
-	printLadderResult(calculator, largeLadderResult)
 }
 
-func printSection(title string) {
-	fmt.Println()
-	fmt.Println(strings.Repeat("=", 64))
-	fmt.Printf("  %s\n", title)
-	fmt.Println(strings.Repeat("=", 64))
-	fmt.Println()
-}
+func printLadderResult(calculator *usecase.StarlightCalculator, result domain.LadderResult) {
+	fmt.Println("【階段存活報告】")
+	dist := calculator.CalculateLadderDistribution(result.InitialCount)
+	reach := make(map[int]float64)
+	for _, stage := range dist.Stages {
+		reach[stage.Stage] = stage.Reach
+	}
 
-func truncateName(name string, maxLen int) string {
-	runes := []rune(name)
-	if len(runes) <= maxLen {
-		return name + strings.Repeat(" ", maxLen-len(runes))
+	fmt.Println("┌─────────────────────┬──────────┬──────────┬──────────────┬──────────────┐")
+	fmt.Println("│ 階段                │   進入   │   失敗   │   存活率     │  理論存活率  │")
+	fmt.Println("├─────────────────────┼──────────┼──────────┼──────────────┼──────────────┤")
+
+	for i, stage := range result.Stages {
+		label := padLabel(fmt.Sprintf("第%d層（%s）", stage.Stage, stage.Item), 19)
+		if i == len(result.Stages)-1 {
+			// 最終階段沒有升級道具，停留即為成功抵達
+			fmt.Printf("│ %s │ %8d │    ---   │ %11.2f%% │ %11.2f%% │\n",
+				label, stage.Entered, float64(stage.Entered)/float64(result.InitialCount)*100, reach[stage.Stage])
+			continue
+		}
+		// 理論存活率為抵達下一階段的機率
+		survived := stage.Entered - stage.Stops
+		fmt.Printf("│ %s │ %8d │ %8d │ %11.2f%% │ %11.2f%% │\n",
+			label, stage.Entered, stage.Stops, float64(survived)/float64(result.InitialCount)*100, reach[result.Stages[i+1].Stage])
 	}
-	return string(runes[:maxLen-3]) + "..."
-}
 
-func printLadderResult(calculator *usecase.StarlightCalculator, result domain.LadderResult) {
-	fmt.Println("【階段存活報告】")
-	fmt.Println("┌─────────────────────┬──────────┬──────────┬──────────────┐")
-	fmt.Println("│ 階段                │   進入   │   失敗   │   存活率     │")
-	fmt.Println("├─────────────────────┼──────────┼──────────┼──────────────┤")
-
-	stage2Survived := result.InitialCount - result.Stage2Failures
-	stage3Survived := stage2Survived - result.Stage3Failures
-	stage4Survived := stage3Survived - result.Stage4Failures
-
-	survivalRate2 := float64(stage2Survived) / float64(result.InitialCount) * 100
-	survivalRate3 := float64(stage3Survived) / float64(result.InitialCount) * 100
-	survivalRate4 := float64(stage4Survived) / float64(result.InitialCount) * 100
-	survivalRate5 := float64(result.Stage5Success) / float64(result.InitialCount) * 100
-
-	fmt.Printf("│ 第2層（星光結晶體） │ %8d │ %8d │ %11.2f%% │\n",
-		result.InitialCount, result.Stage2Failures, survivalRate2)
-	fmt.Printf("│ 第3層（星光原石）   │ %8d │ %8d │ %11.2f%% │\n",
-		stage2Survived, result.Stage3Failures, survivalRate3)
-	fmt.Printf("│ 第4層（星光水晶）   │ %8d │ %8d │ %11.2f%% │\n",
-		stage3Survived, result.Stage4Failures, survivalRate4)
-	fmt.Printf("│ 第5層（璀璨星光）   │ %8d │    ---   │ %11.2f%% │\n",
-		stage4Survived, survivalRate5)
-
-	fmt.Println("└─────────────────────┴──────────┴──────────┴──────────────┘")
+	fmt.Println("└─────────────────────┴──────────┴──────────┴──────────────┴──────────────┘")
 	fmt.Println()
 
 	actualRate := calculator.CalculateSurvivalRate(result)
-	theoreticalRate := calculator.CalculateTheoreticalSurvival()
+
+	// 理論存活率由各階段升級機率相乘
+	var factors []string
+	for _, stage := range dist.Stages {
+		if stage.Upgrade > 0 {
+			factors = append(factors, fmt.Sprintf("%g%%", stage.Upgrade))
+		}
+	}
 
 	fmt.Println("【存活率分析】")
-	fmt.Printf("  理論存活率: %.2f%% (0.5^3 = 12.5%%)\n", theoreticalRate)
+	fmt.Printf("  理論存活率: %.2f%% (%s)\n", dist.Survival, strings.Join(factors, " × "))
 	fmt.Printf("  實際存活率: %.2f%%\n", actualRate)
-	fmt.Printf("  結論: 僅有 %.2f%% 的星光結晶體成功轉化為璀璨星光\n", actualRate)
+	fmt.Printf("  結論: 僅有 %.2f%% 的星光結晶體成功轉化為%s\n", actualRate, dist.FinalItem)
+	fmt.Println()
+
+	fmt.Printf("【%s數量精確分佈（%d 個星光結晶體）】\n", dist.FinalItem, dist.Crystals)
+	fmt.Printf("  期望數量: %.2f 個（標準差 %.2f）\n", dist.Final.Mean, dist.Final.StdDev)
+	fmt.Printf("  至少一個機率: %.2f%%\n", dist.Final.AtLeastOne)
+	fmt.Printf("  P5 / P50 / P95: %d / %d / %d 個\n",
+		dist.Final.Percentiles[5], dist.Final.Percentiles[50], dist.Final.Percentiles[95])
+	fmt.Printf("  實際獲得: %d 個\n", result.Success())
 	fmt.Println()
 
 	fmt.Println("【獲得獎品統計】")
-	fmt.Println("┌────────────────────────────────┬──────────┐")
-	fmt.Println("│ 道具名稱                       │   數量   │")
-	fmt.Println("├────────────────────────────────┼──────────┤")
+	expected := make(map[string]float64)
+	for _, reward := range dist.Rewards {
+		expected[reward.Name] = reward.Count.Mean
+	}
+
+	fmt.Println("┌────────────────────────────────┬──────────┬────────────┐")
+	fmt.Println("│ 道具名稱                       │   數量   │  理論期望  │")
+	fmt.Println("├────────────────────────────────┼──────────┼────────────┤")
 
 	type itemCount struct {
 		name  string
@@ -264,10 +420,50 @@ func printLadderResult(calculator *usecase.StarlightCalculator, result domain.La
 	})
 
 	for _, item := range sorted {
-		fmt.Printf("│ %-30s │ %8d │\n",
+		fmt.Printf("│ %-30s │ %8d │ %10.2f │\n",
 			truncateName(item.name, 30),
-			item.count)
+			item.count,
+			expected[item.name])
+	}
+	fmt.Println("└────────────────────────────────┴──────────┴────────────┘")
+	fmt.Println()
+}
+
+func printMerge(merge usecase.CrystalMerge, crystalItem string) {
+	mode := "可分割（期望數量直接換算）"
+	if merge.Integer {
+		mode = "整數合成（不足一組保留）"
+	}
+
+	fmt.Printf("【%s合成】\n", crystalItem)
+	fmt.Printf("  合成方式: %s\n", mode)
+	fmt.Printf("  期望%s: %.2f 個\n", crystalItem, merge.Crystals)
+	fmt.Printf("  期望合成星光結晶體: %.2f 個\n", merge.Merges)
+	if merge.Integer {
+		fmt.Printf("  期望剩餘%s: %.2f 個\n", crystalItem, merge.Leftover)
+	}
+	fmt.Println()
+}
+
+func printRisk(expectedValue float64, risk usecase.RiskReport) {
+	method := "模擬估計"
+	if risk.Analytic {
+		method = "解析解"
+	}
+
+	fmt.Println("【價值分散程度（含玲瓏星光階梯展開）】")
+	fmt.Printf("  期望總價值: %.2f 元\n", expectedValue)
+	fmt.Printf("  標準差: %.2f 元（%s）\n", risk.StdDev, method)
+	fmt.Printf("  報酬率標準差: %.2f%%\n", risk.ROIStdDev)
+	fmt.Println()
+
+	fmt.Printf("  百分位數（模擬 %d 次）\n", risk.Trials)
+	fmt.Println("┌────────┬──────────────┬──────────────┐")
+	fmt.Println("│ 百分位 │    總價值    │    報酬率    │")
+	fmt.Println("├────────┼──────────────┼──────────────┤")
+	for _, p := range domain.SummaryPercentiles {
+		fmt.Printf("│ P%-5d │ %12.2f │ %11.2f%% │\n", p, risk.Value.Percentiles[p], risk.ROI.Percentiles[p])
 	}
-	fmt.Println("└────────────────────────────────┴──────────┘")
+	fmt.Println("└────────┴──────────────┴──────────────┘")
 	fmt.Println()
 }
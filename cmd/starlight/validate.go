@@ -0,0 +1,66 @@
+package main
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
+	"flag"
+	"fmt"
+	"math"
+)
+
+// runValidate 執行 validate 子命令，驗證活動定義檔並回傳結束代碼
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	eventPath := fs.String("event", "", "活動定義檔路徑（預設使用內嵌版本）")
+	fs.Parse(args)
+
+	event, err := repository.ReadEvent(*eventPath)
+	if err != nil {
+		fmt.Printf("載入活動定義失敗: %v\n", err)
+		return 1
+	}
+
+	report := event.Validate()
+
+	printSection(fmt.Sprintf("活動定義驗證（版本 %s）", event.Version))
+
+	fmt.Println("【獎池機率總和】")
+	fmt.Println("┌────────────────────────────────┬──────────┬─────────────┐")
+	fmt.Println("│ 獎池                           │  道具數  │  機率總和   │")
+	fmt.Println("├────────────────────────────────┼──────────┼─────────────┤")
+	for _, pool := range report.Pools {
+		mark := " "
+		if math.Abs(pool.Sum-100) > 1e-6 {
+			mark = "✗"
+		}
+		fmt.Printf("│ %-30s │ %8d │ %9.2f%% %s│\n",
+			truncateName(pool.Pool, 30),
+			pool.ItemCount,
+			pool.Sum,
+			mark)
+	}
+	fmt.Println("└────────────────────────────────┴──────────┴─────────────┘")
+	fmt.Println()
+
+	if len(report.Issues) == 0 {
+		fmt.Println("✓ 驗證通過，未發現問題")
+		return 0
+	}
+
+	fmt.Println("【發現問題】")
+	for _, issue := range report.Issues {
+		icon := "⚠"
+		if issue.Severity == domain.SeverityError {
+			icon = "✗"
+		}
+		fmt.Printf("  %s %s\n", icon, issue.String())
+	}
+	fmt.Println()
+
+	if report.HasErrors() {
+		fmt.Printf("✗ 驗證失敗：%d 個錯誤\n", len(report.Errors()))
+		return 1
+	}
+	fmt.Println("✓ 驗證通過（含警告）")
+	return 0
+}
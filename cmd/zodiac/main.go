@@ -0,0 +1,359 @@
+package main
+
+import (
+	"MSCashItemExpected/cmd/internal/cli"
+	"MSCashItemExpected/internal/adapter"
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
+	"MSCashItemExpected/internal/usecase"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	// 子命令
+	switch os.Args[1] {
+	case "simulate":
+		os.Exit(runSimulate(os.Args[2:]))
+	case "luck":
+		os.Exit(runLuck(os.Args[2:]))
+	default:
+		printUsage()
+		os.Exit(2)
+	}
+}
+
+func printUsage() {
+	fmt.Println("新楓之谷 新年氣息 模擬器")
+	fmt.Println()
+	fmt.Println("用法:")
+	fmt.Println("  zodiac simulate [參數]    模擬實際抽取並統計心願箱、總價值與報酬率分佈")
+	fmt.Println("  zodiac luck [參數]        計算實際結果在相同花費模擬分佈中的百分位")
+	fmt.Println()
+	fmt.Println("執行 zodiac <子命令> -h 查看參數說明")
+}
+
+// runSimulate 執行 simulate 子命令
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	eventPath := fs.String("event", "", "活動定義檔路徑（預設使用內嵌版本）")
+	investment := fs.Float64("investment", 10000, "投入金額（台幣）")
+	method := fs.String("method", string(domain.MethodOriginal), "購買方式 (card, cardreader, original, gift)")
+	discount := fs.Float64("discount", 1, "點卡/送禮折數")
+	purchasePath := fs.String("purchase", "", "購買方式定義檔路徑（預設為不限面額的購買方式）")
+	trials := fs.Int("trials", 10000, "模擬次數")
+	carryPoints := fs.Float64("carry", 0, "前次購買或其他活動結轉的點數")
+	small := fs.Float64("small", 0, "小吉心願箱價值")
+	medium := fs.Float64("medium", 0, "中吉心願箱價值")
+	large := fs.Float64("large", 0, "大吉心願箱價值")
+	super := fs.Float64("super", 0, "超越心願箱價值")
+	pricesPath := fs.String("prices", repository.DefaultPricesPath, "道具價格檔（JSON 或 CSV），未指定 -small 等參數時以其中小吉、中吉、大吉、超越的價格為心願箱價值")
+	seed := fs.Int64("seed", 0, "亂數種子（未指定時隨機產生，相同種子可重現結果）")
+	rngSource := fs.String("rng", usecase.SourceMathRand, "亂數來源 (mathrand, pcg)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "平行模擬的 worker 數量（不影響結果）")
+	formatName := fs.String("format", string(adapter.FormatTable), "輸出格式 (table, json, csv, markdown)")
+	ratesLog := fs.String("rates-log", "", "抽取紀錄檔，指定時以事後機率取代生肖的公告機率")
+	rates := fs.String("rates", usecase.RatesMean, "使用 -rates-log 時的機率 (mean: 事後平均, sample: 由事後分佈抽樣)")
+	priorWeight := fs.Float64("prior-weight", domain.DefaultPriorWeight, "公告機率先驗相當於的抽數")
+	fs.Parse(args)
+
+	format, err := adapter.ParseOutputFormat(*formatName)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	if *trials <= 0 {
+		fmt.Println("模擬次數必須大於 0")
+		return 2
+	}
+
+	event, err := repository.LoadEvent(*eventPath)
+	if err != nil {
+		fmt.Printf("載入活動定義失敗: %v\n", err)
+		return 1
+	}
+
+	purchases, err := repository.LoadPurchases(*purchasePath)
+	if err != nil {
+		fmt.Printf("載入購買方式失敗: %v\n", err)
+		return 1
+	}
+
+	prices, err := repository.NewPriceFile(*pricesPath).Load()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	opts, err := cli.RNGOptions(fs, *seed, *rngSource)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	opts = append(opts, usecase.WithWorkers(*workers), usecase.WithPurchases(purchases))
+	event, err = posteriorEvent(event, opts, *ratesLog, *rates, *priorWeight)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	simulator := usecase.NewZodiacSimulator(event.Zodiac, opts...)
+	output, err := simulator.Simulate(ctx, usecase.ZodiacSimulationInput{
+		Investment:  *investment,
+		Method:      domain.PurchaseMethod(*method),
+		Discount:    *discount,
+		BoxValues:   boxValues(fs, prices, *small, *medium, *large, *super),
+		Trials:      *trials,
+		CarryPoints: *carryPoints,
+	})
+	if err != nil {
+		fmt.Printf("模擬中斷: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		boxTypes := make([]string, len(event.Zodiac.BoxPriority))
+		for i, boxType := range event.Zodiac.BoxPriority {
+			boxTypes[i] = string(boxType)
+		}
+		response := adapter.FromZodiacSimulationOutput(output)
+		return cli.PrintReport(format, response, adapter.ZodiacSimulateTables(response, boxTypes))
+	}
+
+	printSection(fmt.Sprintf("新年氣息模擬（%d 次 × %d 抽）", output.Boxes.Trials, output.Boxes.DrawCount))
+
+	fmt.Printf("💰 投入金額: %.0f 元\n", *investment)
+	fmt.Printf("🎯 可得點數: %.0f 點\n", output.Points)
+	fmt.Printf("🎰 每次抽數: %d 次\n", output.Boxes.DrawCount)
+	fmt.Printf("🪙 剩餘點數: %.0f 點（可結轉至下次購買或其他活動）\n", output.Residual)
+	fmt.Printf("🎲 亂數種子: %d\n", output.Seed)
+	fmt.Println()
+	printPurchase(output.Purchase)
+
+	fmt.Println("【心願箱數量分佈】")
+	fmt.Println("┌────────┬──────────┬──────────┬──────────┬──────────┬──────────────┐")
+	fmt.Println("│ 心願箱 │   平均   │  中位數  │   P5     │   P95    │ 至少一個機率 │")
+	fmt.Println("├────────┼──────────┼──────────┼──────────┼──────────┼──────────────┤")
+	for _, boxType := range event.Zodiac.BoxPriority {
+		summary := output.Boxes.Boxes[boxType]
+		fmt.Printf("│ %s   │ %8.3f │ %8.1f │ %8.1f │ %8.1f │ %11.2f%% │\n",
+			boxType,
+			summary.Mean,
+			summary.Median,
+			summary.Percentiles[5],
+			summary.Percentiles[95],
+			output.Boxes.AtLeastOne[boxType])
+	}
+	fmt.Println("└────────┴──────────┴──────────┴──────────┴──────────┴──────────────┘")
+	fmt.Printf("  依心願箱價值調整湊箱（優於固定優先順序）的比例: %.2f%%\n", output.Boxes.Optimized)
+	fmt.Println()
+
+	fmt.Println("【總價值與報酬率分佈】")
+	fmt.Println("┌────────┬──────────────┬──────────────┐")
+	fmt.Println("│ 統計量 │    總價值    │    報酬率    │")
+	fmt.Println("├────────┼──────────────┼──────────────┤")
+	fmt.Printf("│ 平均   │ %12.2f │ %11.2f%% │\n", output.Value.Mean, output.ROI.Mean)
+	fmt.Printf("│ 標準差 │ %12.2f │ %11.2f%% │\n", output.Value.StdDev, output.ROI.StdDev)
+	fmt.Printf("│ 變異數 │ %12.0f │ %12.2f │\n", output.Value.Variance, output.ROI.Variance)
+	for _, p := range domain.SummaryPercentiles {
+		fmt.Printf("│ P%-5d │ %12.2f │ %11.2f%% │\n", p, output.Value.Percentiles[p], output.ROI.Percentiles[p])
+	}
+	fmt.Println("└────────┴──────────────┴──────────────┘")
+	fmt.Println()
+
+	fmt.Printf("  回本機率: %.2f%%\n", output.ProfitProbability)
+	fmt.Println()
+
+	return 0
+}
+
+// runLuck 執行 luck 子命令
+func runLuck(args []string) int {
+	fs := flag.NewFlagSet("luck", flag.ExitOnError)
+	eventPath := fs.String("event", "", "活動定義檔路徑（預設使用內嵌版本）")
+	investment := fs.Float64("investment", 10000, "投入金額（台幣），用於計算抽數與報酬率")
+	method := fs.String("method", string(domain.MethodOriginal), "購買方式 (card, cardreader, original, gift)")
+	discount := fs.Float64("discount", 1, "點卡/送禮折數")
+	purchasePath := fs.String("purchase", "", "購買方式定義檔路徑（預設為不限面額的購買方式）")
+	draws := fs.Int("draws", 0, "實際抽取次數（預設依投入金額計算）")
+	outcomePath := fs.String("outcome", "", "實際結果檔（JSON 或 CSV：心願箱或生肖名稱 -> 數量）")
+	trials := fs.Int("trials", 0, "模擬次數（預設 10000）")
+	carryPoints := fs.Float64("carry", 0, "前次購買或其他活動結轉的點數")
+	small := fs.Float64("small", 0, "小吉心願箱價值")
+	medium := fs.Float64("medium", 0, "中吉心願箱價值")
+	large := fs.Float64("large", 0, "大吉心願箱價值")
+	super := fs.Float64("super", 0, "超越心願箱價值")
+	pricesPath := fs.String("prices", repository.DefaultPricesPath, "道具價格檔（JSON 或 CSV），未指定 -small 等參數時以其中小吉、中吉、大吉、超越的價格為心願箱價值")
+	seed := fs.Int64("seed", 0, "亂數種子（未指定時隨機產生，相同種子可重現結果）")
+	rngSource := fs.String("rng", usecase.SourceMathRand, "亂數來源 (mathrand, pcg)")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "平行模擬的 worker 數量（不影響結果）")
+	formatName := fs.String("format", string(adapter.FormatTable), "輸出格式 (table, json, csv, markdown)")
+	ratesLog := fs.String("rates-log", "", "抽取紀錄檔，指定時以事後機率取代生肖的公告機率")
+	rates := fs.String("rates", usecase.RatesMean, "使用 -rates-log 時的機率 (mean: 事後平均, sample: 由事後分佈抽樣)")
+	priorWeight := fs.Float64("prior-weight", domain.DefaultPriorWeight, "公告機率先驗相當於的抽數")
+	fs.Parse(args)
+
+	format, err := adapter.ParseOutputFormat(*formatName)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	if *outcomePath == "" {
+		fmt.Println("請以 -outcome 指定實際結果檔")
+		return 2
+	}
+
+	event, err := repository.LoadEvent(*eventPath)
+	if err != nil {
+		fmt.Printf("載入活動定義失敗: %v\n", err)
+		return 1
+	}
+	purchases, err := repository.LoadPurchases(*purchasePath)
+	if err != nil {
+		fmt.Printf("載入購買方式失敗: %v\n", err)
+		return 1
+	}
+	outcome, err := repository.LoadOutcome(*outcomePath)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	prices, err := repository.NewPriceFile(*pricesPath).Load()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	opts, err := cli.RNGOptions(fs, *seed, *rngSource)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	opts = append(opts, usecase.WithWorkers(*workers), usecase.WithPurchases(purchases))
+	event, err = posteriorEvent(event, opts, *ratesLog, *rates, *priorWeight)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	// Ctrl+C 中斷進行中的模擬
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	output, err := usecase.NewZodiacSimulator(event.Zodiac, opts...).Luck(ctx, usecase.ZodiacLuckInput{
+		Investment:  *investment,
+		Method:      domain.PurchaseMethod(*method),
+		Discount:    *discount,
+		Draws:       *draws,
+		Outcome:     outcome,
+		BoxValues:   boxValues(fs, prices, *small, *medium, *large, *super),
+		Trials:      *trials,
+		CarryPoints: *carryPoints,
+	})
+	if err != nil {
+		fmt.Printf("計算失敗: %v\n", err)
+		return 1
+	}
+
+	if format != adapter.FormatTable {
+		response := adapter.FromZodiacLuckOutput(output)
+		return cli.PrintReport(format, response, adapter.LuckTables(response))
+	}
+
+	printSection(fmt.Sprintf("運氣百分位（%d 抽，模擬 %d 次）", output.DrawCount, output.Luck.Trials))
+	fmt.Printf("💰 實際花費: %.0f 元\n", output.Purchase.Cost)
+	fmt.Printf("🎲 亂數種子: %d\n", output.Seed)
+	fmt.Println()
+	printLuck(output.Luck)
+	return 0
+}
+
+// printLuck 印出實際結果在模擬分佈中的位置
+func printLuck(luck usecase.LuckReport) {
+	fmt.Println("【實際結果】")
+	fmt.Printf("  實際總價值: %.2f（報酬率 %+.2f%%）\n", luck.Realized, luck.RealizedROI)
+	fmt.Printf("  模擬平均: %.2f（報酬率 %+.2f%%）\n", luck.Value.Mean, luck.ROI.Mean)
+	fmt.Printf("  百分位: %.2f（%.2f%% 的模擬結果較差，%.2f%% 較好）\n", luck.Percentile, luck.Worse, luck.Better)
+	fmt.Println()
+
+	fmt.Println("┌────────┬──────────────┬──────────────┐")
+	fmt.Println("│ 百分位 │    總價值    │    報酬率    │")
+	fmt.Println("├────────┼──────────────┼──────────────┤")
+	for _, p := range domain.SummaryPercentiles {
+		fmt.Printf("│ P%-5d │ %12.2f │ %11.2f%% │\n", p, luck.Value.Percentiles[p], luck.ROI.Percentiles[p])
+	}
+	fmt.Println("└────────┴──────────────┴──────────────┘")
+	fmt.Println()
+}
+
+// printPurchase 印出點卡組合（可購買任意金額時不印出）
+func printPurchase(purchase domain.Purchase) {
+	if len(purchase.Cards) == 0 {
+		return
+	}
+
+	fmt.Println("【點卡組合】")
+	fmt.Println("┌──────────┬────────────┬──────────┬────────┐")
+	fmt.Println("│   售價   │    點數    │   贈送   │  張數  │")
+	fmt.Println("├──────────┼────────────┼──────────┼────────┤")
+	for _, card := range purchase.Cards {
+		fmt.Printf("│ %8d │ %10.0f │ %8.0f │ %6d │\n", card.Price, card.Points, card.Bonus, card.Count)
+	}
+	fmt.Println("└──────────┴────────────┴──────────┴────────┘")
+	fmt.Printf("  實際花費: %.0f 元，未使用金額: %.0f 元\n", purchase.Cost, purchase.Unspent)
+	fmt.Println()
+}
+
+// boxValues 以價格檔中的心願箱價值為預設，命令列有指定的心願箱價值優先
+func boxValues(fs *flag.FlagSet, prices domain.PriceBook, small, medium, large, super float64) domain.BoxValues {
+	values := prices.BoxValues()
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "small":
+			values.Small = small
+		case "medium":
+			values.Medium = medium
+		case "large":
+			values.Large = large
+		case "super":
+			values.Super = super
+		}
+	})
+	return values
+}
+
+// posteriorEvent 指定 -rates-log 時，以事後平均或抽樣機率取代紀錄中各獎池的公告機率
+func posteriorEvent(event domain.EventDefinition, opts []usecase.Option, logPath, mode string, priorWeight float64) (domain.EventDefinition, error) {
+	if logPath == "" {
+		return event, nil
+	}
+	log, err := repository.LoadPullLog(logPath, domain.PoolZodiac)
+	if err != nil {
+		return domain.EventDefinition{}, err
+	}
+	input := usecase.EstimateInput{Log: log, PriorWeight: &priorWeight}
+	return usecase.NewRateTester(event, opts...).PosteriorEvent(context.Background(), input, mode)
+}
+
+func printSection(title string) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Printf("  %s\n", title)
+	fmt.Println(strings.Repeat("=", 64))
+	fmt.Println()
+}
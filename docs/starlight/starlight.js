@@ -2,86 +2,7 @@
 // 星光錦囊 - 常數定義
 // ============================================
 
-// 每抽成本（點數）
-const STARLIGHT_COST = 45;
-
-// 星光錦囊主機率表 (%)
-const STARLIGHT_RATES = {
-    '靈魂艾爾達碎片交換券(10個)': 8.00,
-    '靈魂艾爾達': 6.00,
-    '永遠的輪迴星火': 14.40,
-    '暗黑輪迴星火': 13.70,
-    '特別附加潛在能力賦予卷軸': 7.80,
-    '傳說潛在能力卷軸50%': 0.85,
-    '傳說潛在能力卷軸100%': 0.55,
-    '星力14星強化券': 15.00,
-    '星力15星強化券': 10.00,
-    '星力16星強化券': 7.00,
-    '星力17星強化券': 3.40,
-    '星力18星強化券': 1.50,
-    '星力19星強化券': 0.60,
-    '星力20星強化券': 0.40,
-    '突破1星強化券100%(21星)': 0.45,
-    '突破1星強化券100%(22星)': 0.20,
-    '追加1星強化券30%(23星)': 0.15,
-    '玲瓏星光': 10.00
-};
-
-// 玲瓏星光 -> 星光結晶體 機率表 (%)
-const CRYSTAL_RATES = {
-    '星力18星強化券': 18.00,
-    '星力19星強化券': 12.00,
-    '星力20星強化券': 6.00,
-    '突破1星強化券30%(23星)': 10.00,
-    '突破1星強化券50%(23星)': 4.00,
-    '星光原石': 50.00
-};
-
-// 星光原石 機率表 (%)
-const ROUGH_RATES = {
-    '星力19星強化券': 10.00,
-    '星力20星強化券': 8.00,
-    '星力21星強化券': 2.00,
-    '突破1星強化券30%(23星)': 8.00,
-    '突破1星強化券50%(23星)': 6.00,
-    '突破1星強化券100%(23星)': 5.00,
-    '突破1星強化券30%(24星)': 7.00,
-    '突破1星強化券50%(24星)': 4.00,
-    '星光水晶': 50.00
-};
-
-// 星光水晶 機率表 (%)
-const PURE_RATES = {
-    '突破1星強化券50%(23星)': 20.00,
-    '突破1星強化券100%(23星)': 15.00,
-    '突破1星強化券30%(24星)': 8.00,
-    '突破1星強化券50%(24星)': 4.00,
-    '突破1星強化券100%(24星)': 2.00,
-    '突破1星強化券30%(25星)': 0.70,
-    '突破1星強化券50%(25星)': 0.30,
-    '璀璨星光': 50.00
-};
-
-// 璀璨星光 機率表 (%)
-const BRILLIANT_RATES = {
-    '突破1星強化券30%(24星)': 29.00,
-    '突破1星強化券50%(24星)': 19.00,
-    '突破1星強化券100%(24星)': 14.00,
-    '突破1星強化券30%(25星)': 20.00,
-    '突破1星強化券50%(25星)': 9.00,
-    '突破1星強化券100%(25星)': 4.00,
-    '突破1星強化券30%(26星)': 3.00,
-    '突破1星強化券50%(26星)': 2.00
-};
-
-// 價值為0的道具（不需要輸入）
-const ZERO_VALUE_ITEMS = [
-    '靈魂艾爾達碎片交換券(10個)',
-    '靈魂艾爾達',
-    '永遠的輪迴星火',
-    '暗黑輪迴星火',
-    '特別附加潛在能力賦予卷軸'
-];
+// 每抽成本、各階段獎池與價值為0的道具由活動定義（/api/event）取得
 
 // 道具到輸入框ID的映射（只有第一階段道具）
 const ITEM_INPUT_MAP = {
@@ -117,12 +38,12 @@ const RARE_ITEMS = [
 /**
  * 計算星光錦囊期望道具數量（僅第一階段，不展開玲瓏星光）
  */
-function calculateStarlightExpected(drawCount) {
+function calculateStarlightExpected(event, drawCount) {
     const items = {};
 
     // 只計算第一階段道具
-    for (const [item, rate] of Object.entries(STARLIGHT_RATES)) {
-        items[item] = drawCount * (rate / 100);
+    for (const reward of event.stage1_pool) {
+        items[reward.name] = (items[reward.name] || 0) + drawCount * (reward.probability / 100);
     }
 
     return items;
@@ -131,8 +52,8 @@ function calculateStarlightExpected(drawCount) {
 /**
  * 獲取道具價值
  */
-function getItemValue(itemName, itemValues) {
-    if (ZERO_VALUE_ITEMS.includes(itemName)) {
+function getItemValue(event, itemName, itemValues) {
+    if (event.zero_value_items.includes(itemName)) {
         return 0;
     }
     const inputId = ITEM_INPUT_MAP[itemName];
@@ -145,10 +66,10 @@ function getItemValue(itemName, itemValues) {
 /**
  * 計算星光錦囊期望總價值
  */
-function calculateStarlightValue(items, itemValues) {
+function calculateStarlightValue(event, items, itemValues) {
     let total = 0;
     for (const [item, count] of Object.entries(items)) {
-        total += count * getItemValue(item, itemValues);
+        total += count * getItemValue(event, item, itemValues);
     }
     return total;
 }
@@ -156,24 +77,28 @@ function calculateStarlightValue(items, itemValues) {
 /**
  * 星光錦囊主計算函數
  */
-function calculateStarlight(investment, method, discount, itemValues) {
-    // 1. 計算可得點數
-    const points = calculatePoints(investment, method, discount);
+async function calculateStarlight(investment, method, discount, itemValues) {
+    const event = (await loadEvent()).starlight;
+
+    // 1. 計算實際花費與可得點數
+    const purchase = await calculatePurchase(investment, method, discount);
+    const cost = purchase.cost;
+    const points = purchase.points;
 
     // 2. 計算可抽次數
-    const drawCount = points / STARLIGHT_COST;
+    const drawCount = points / event.cost_per_draw;
 
     // 3. 計算每抽實際成本
-    const costPerDraw = drawCount > 0 ? investment / drawCount : 0;
+    const costPerDraw = drawCount > 0 ? cost / drawCount : 0;
 
     // 4. 計算期望獲得各道具數量
-    const expectedItems = calculateStarlightExpected(drawCount);
+    const expectedItems = calculateStarlightExpected(event, drawCount);
 
     // 5. 計算期望總價值
-    const expectedValue = calculateStarlightValue(expectedItems, itemValues);
+    const expectedValue = calculateStarlightValue(event, expectedItems, itemValues);
 
     // 6. 計算報酬率
-    const roi = investment > 0 ? ((expectedValue - investment) / investment) * 100 : 0;
+    const roi = cost > 0 ? ((expectedValue - cost) / cost) * 100 : 0;
 
     return {
         points: points,
@@ -181,7 +106,8 @@ function calculateStarlight(investment, method, discount, itemValues) {
         cost_per_draw: costPerDraw,
         expected_items: expectedItems,
         expected_value: expectedValue,
-        roi: roi
+        roi: roi,
+        event: event
     };
 }
 
@@ -190,31 +116,31 @@ function calculateStarlight(investment, method, discount, itemValues) {
 // ============================================
 
 /**
- * 根據機率表隨機抽取一個道具
+ * 根據獎池機率隨機抽取一個道具
  */
-function drawFromRates(rates) {
+function drawFromPool(pool) {
     const roll = Math.random() * 100;
     let cumulative = 0;
 
-    for (const [item, rate] of Object.entries(rates)) {
-        cumulative += rate;
+    for (const reward of pool) {
+        cumulative += reward.probability;
         if (roll < cumulative) {
-            return item;
+            return reward.name;
         }
     }
 
     // 應該不會到這裡，但以防萬一返回最後一個
-    return Object.keys(rates)[Object.keys(rates).length - 1];
+    return pool[pool.length - 1].name;
 }
 
 /**
  * 模式一：星光錦囊模擬器（不展開玲瓏星光）
  */
-function simulateBagOnly(count) {
+function simulateBagOnly(event, count) {
     const results = {};
 
     for (let i = 0; i < count; i++) {
-        const item = drawFromRates(STARLIGHT_RATES);
+        const item = drawFromPool(event.stage1_pool);
         if (!results[item]) {
             results[item] = 0;
         }
@@ -226,62 +152,33 @@ function simulateBagOnly(count) {
 
 /**
  * 模式二：玲瓏星光模擬器（分階段）
+ * @param {object} event - 星光錦囊活動定義
  * @param {number} crystalCount - 消耗的玲瓏星光數量
- * @returns {object} 包含各階段結果
+ * @returns {object} 依階段順序的結果，每階段包含開啟次數與未升級的獎品
  */
-function simulateCrystalStages(crystalCount) {
-    const results = {
-        stage1: {},  // 星光結晶體階段
-        stage2: {},  // 星光原石階段
-        stage3: {},  // 星光水晶階段
-        stage4: {},  // 璀璨星光階段
-        roughCount: 0,
-        pureCount: 0,
-        brilliantCount: 0
-    };
-
-    // 輔助函數
-    function addToStage(stage, item) {
-        if (!results[stage][item]) {
-            results[stage][item] = 0;
-        }
-        results[stage][item]++;
-    }
-
-    // 階段1：星光結晶體
-    for (let i = 0; i < crystalCount; i++) {
-        const item = drawFromRates(CRYSTAL_RATES);
-        if (item === '星光原石') {
-            results.roughCount++;
-        } else {
-            addToStage('stage1', item);
-        }
-    }
-
-    // 階段2：星光原石
-    for (let i = 0; i < results.roughCount; i++) {
-        const item = drawFromRates(ROUGH_RATES);
-        if (item === '星光水晶') {
-            results.pureCount++;
-        } else {
-            addToStage('stage2', item);
-        }
-    }
-
-    // 階段3：星光水晶
-    for (let i = 0; i < results.pureCount; i++) {
-        const item = drawFromRates(PURE_RATES);
-        if (item === '璀璨星光') {
-            results.brilliantCount++;
-        } else {
-            addToStage('stage3', item);
+function simulateCrystalStages(event, crystalCount) {
+    const stages = Object.keys(event.stage_pools).map(Number).sort((a, b) => a - b);
+    const results = [];
+
+    // 每個階段開啟前一階段升級的數量，升級道具留待下一階段開啟
+    let opens = crystalCount;
+    for (const stage of stages) {
+        const upgrade = event.upgrade_items[stage];
+        const hasNext = event.stage_pools[stage + 1] !== undefined;
+        const items = {};
+        let upgrades = 0;
+
+        for (let i = 0; i < opens; i++) {
+            const item = drawFromPool(event.stage_pools[stage]);
+            if (hasNext && item === upgrade) {
+                upgrades++;
+            } else {
+                items[item] = (items[item] || 0) + 1;
+            }
         }
-    }
 
-    // 階段4：璀璨星光
-    for (let i = 0; i < results.brilliantCount; i++) {
-        const item = drawFromRates(BRILLIANT_RATES);
-        addToStage('stage4', item);
+        results.push({ stage: stage, opens: opens, items: items, upgrade: hasNext ? upgrade : '', upgrades: upgrades });
+        opens = upgrades;
     }
 
     return results;
@@ -296,11 +193,13 @@ document.addEventListener('DOMContentLoaded', function() {
     // 期望值計算機
     // ============================================
 
+    bindPriceBook(ITEM_INPUT_MAP, 'sl-save-prices-btn');
+
     const slCalculateBtn = document.getElementById('sl-calculate-btn');
     const slResultDiv = document.getElementById('sl-result');
 
     if (slCalculateBtn) {
-        slCalculateBtn.addEventListener('click', function() {
+        slCalculateBtn.addEventListener('click', async function() {
             const investment = parseFloat(document.getElementById('sl-investment').value) || 0;
             const method = document.querySelector('input[name="sl-method"]:checked').value;
 
@@ -325,12 +224,17 @@ document.addEventListener('DOMContentLoaded', function() {
                 return;
             }
 
-            const result = calculateStarlight(investment, method, discount, itemValues);
-            displayStarlightResult(result, itemValues);
+            try {
+                const result = await calculateStarlight(investment, method, discount, itemValues);
+                displayStarlightResult(result, itemValues);
+            } catch (e) {
+                alert('計算失敗：' + e.message);
+            }
         });
     }
 
     function displayStarlightResult(result, itemValues) {
+        const event = result.event;
         slResultDiv.style.display = 'block';
 
         // 基本資訊
@@ -349,9 +253,9 @@ document.addEventListener('DOMContentLoaded', function() {
         for (const [itemName, count] of sortedItems) {
             if (count < 0.0001) continue; // 跳過數量太小的
 
-            const value = getItemValue(itemName, itemValues);
+            const value = getItemValue(event, itemName, itemValues);
             const totalValue = count * value;
-            const isZeroValue = ZERO_VALUE_ITEMS.includes(itemName) || value === 0;
+            const isZeroValue = event.zero_value_items.includes(itemName) || value === 0;
 
             const row = document.createElement('div');
             row.className = 'item-row' + (isZeroValue ? ' zero-value' : '');
@@ -438,9 +342,17 @@ document.addEventListener('DOMContentLoaded', function() {
         slSimItems.innerHTML = '';
         slSimAnimation.textContent = '模擬中...';
 
+        let event;
+        try {
+            event = (await loadEvent()).starlight;
+        } catch (e) {
+            slSimAnimation.textContent = '讀取活動定義失敗：' + e.message;
+            return;
+        }
+
         await new Promise(resolve => setTimeout(resolve, 50));
 
-        const results = simulateBagOnly(count);
+        const results = simulateBagOnly(event, count);
 
         slSimAnimation.textContent = `模擬 ${count} 次完成！`;
 
@@ -448,7 +360,7 @@ document.addEventListener('DOMContentLoaded', function() {
             .sort((a, b) => b[1] - a[1]);
 
         for (const [itemName, itemCount] of sortedResults) {
-            const isRare = RARE_ITEMS.includes(itemName) || itemName === '玲瓏星光';
+            const isRare = RARE_ITEMS.includes(itemName) || itemName === event.crystal_item;
 
             const row = document.createElement('div');
             row.className = 'sim-item' + (isRare ? ' rare' : '');
@@ -479,17 +391,26 @@ document.addEventListener('DOMContentLoaded', function() {
         const slCrystalAnimation = document.getElementById('sl-crystal-animation');
         slCrystalAnimation.textContent = '模擬中...';
 
+        let event;
+        try {
+            event = (await loadEvent()).starlight;
+        } catch (e) {
+            slCrystalAnimation.textContent = '讀取活動定義失敗：' + e.message;
+            return;
+        }
+
         await new Promise(resolve => setTimeout(resolve, 50));
 
-        const results = simulateCrystalStages(crystalCount);
+        const results = simulateCrystalStages(event, crystalCount);
 
-        slCrystalAnimation.textContent = `消耗 ${crystalCount} 顆玲瓏星光完成！`;
+        slCrystalAnimation.textContent = `消耗 ${crystalCount} 顆${event.crystal_item}完成！`;
 
-        // 顯示各階段結果
-        displayStageResult('sl-crystal-stage1', results.stage1, `獲得 ${results.roughCount} 個星光原石`);
-        displayStageResult('sl-crystal-stage2', results.stage2, `獲得 ${results.pureCount} 個星光水晶`, 'sl-stage2-section', results.roughCount > 0);
-        displayStageResult('sl-crystal-stage3', results.stage3, `獲得 ${results.brilliantCount} 個璀璨星光`, 'sl-stage3-section', results.pureCount > 0);
-        displayStageResult('sl-crystal-stage4', results.stage4, '', 'sl-stage4-section', results.brilliantCount > 0);
+        // 顯示各階段結果（頁面依序提供 sl-crystal-stage1 起的區塊，第一個區塊固定顯示）
+        results.forEach((result, i) => {
+            const extraInfo = result.upgrade ? `獲得 ${result.upgrades} 個${result.upgrade}` : '';
+            const sectionId = i > 0 ? `sl-stage${i + 1}-section` : undefined;
+            displayStageResult(`sl-crystal-stage${i + 1}`, result.items, extraInfo, sectionId, result.opens > 0);
+        });
 
         slCrystalResultDiv.scrollIntoView({ behavior: 'smooth' });
     }
@@ -499,6 +420,7 @@ document.addEventListener('DOMContentLoaded', function() {
      */
     function displayStageResult(containerId, items, extraInfo, sectionId, show) {
         const container = document.getElementById(containerId);
+        if (!container) return;
         container.innerHTML = '';
 
         // 控制區段顯示
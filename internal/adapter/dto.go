@@ -3,14 +3,21 @@ package adapter
 import (
 	"MSCashItemExpected/internal/domain"
 	"MSCashItemExpected/internal/usecase"
+	"fmt"
 )
 
 // CalculateRequest API 請求 DTO
 type CalculateRequest struct {
-	Investment float64   `json:"investment"`
-	Method     string    `json:"method"`
-	Discount   float64   `json:"discount"`
-	BoxValues  BoxValues `json:"box_values"`
+	Investment float64            `json:"investment"`
+	Method     string             `json:"method"`
+	Discount   float64            `json:"discount"`
+	BoxValues  BoxValues          `json:"box_values"`
+	Inventory  map[string]float64 `json:"inventory"` // 已持有的氣息（生肖 -> 數量）
+	Trials     int                `json:"trials"`    // 蒙地卡羅模擬次數，0 表示使用預設值
+	Seed       *int64             `json:"seed"`      // 亂數種子，未指定時隨機產生
+	// 以整數抽數計算，不足一抽的點數回報為 residual_points
+	IntegerDraws bool    `json:"integer_draws"`
+	CarryPoints  float64 `json:"carry_points"` // 前次購買或其他活動結轉的點數
 }
 
 // BoxValues 心願箱價值 DTO
@@ -24,26 +31,147 @@ type BoxValues struct {
 // CalculateResponse API 回應 DTO
 type CalculateResponse struct {
 	Points          float64            `json:"points"`
+	Purchase        PurchaseDTO        `json:"purchase"`
 	DrawCount       float64            `json:"draw_count"`
+	Residual        float64            `json:"residual_points"`
 	CostPerBreath   float64            `json:"cost_per_breath"`
 	ExpectedBreaths map[string]float64 `json:"expected_breaths"`
 	ExpectedBoxes   map[string]float64 `json:"expected_boxes"`
+	InventoryBoxes  map[string]float64 `json:"inventory_boxes"`
+	NewBoxes        map[string]float64 `json:"new_boxes"`
 	ExpectedValue   float64            `json:"expected_value"`
+	InventoryValue  float64            `json:"inventory_value"`
+	AddedValue      float64            `json:"added_value"`
 	ROI             float64            `json:"roi"`
+	Strategy        string             `json:"strategy"`
+	GreedyValue     float64            `json:"greedy_value"`
+	Distribution    DistributionDTO    `json:"distribution"`
+	Risk            RiskDTO            `json:"risk"`
+	Seed            int64              `json:"seed"`
+}
+
+// RiskDTO 總價值與報酬率分散程度 DTO
+type RiskDTO struct {
+	Analytic  bool       `json:"analytic"`
+	Variance  float64    `json:"variance"`
+	StdDev    float64    `json:"std_dev"`
+	ROIStdDev float64    `json:"roi_std_dev"`
+	Trials    int        `json:"trials"`
+	Value     SummaryDTO `json:"value"`
+	ROI       SummaryDTO `json:"roi"`
+}
+
+// DistributionDTO 心願箱數量分佈 DTO
+type DistributionDTO struct {
+	Trials     int                   `json:"trials"`
+	DrawCount  int                   `json:"draw_count"`
+	Boxes      map[string]SummaryDTO `json:"boxes"`
+	AtLeastOne map[string]float64    `json:"at_least_one"`
+	Optimized  float64               `json:"optimized"` // 最佳組合優於固定優先順序的模擬比例 (%)
+}
+
+// SummaryDTO 統計摘要 DTO
+type SummaryDTO struct {
+	Mean        float64            `json:"mean"`
+	Variance    float64            `json:"variance"`
+	StdDev      float64            `json:"std_dev"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Median      float64            `json:"median"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// ZodiacSimulateRequest 新年氣息模擬 API 請求 DTO
+type ZodiacSimulateRequest struct {
+	Investment float64            `json:"investment"`
+	Method     string             `json:"method"`
+	Discount   float64            `json:"discount"`
+	BoxValues  BoxValues          `json:"box_values"`
+	Inventory  map[string]float64 `json:"inventory"`
+	Trials     int                `json:"trials"`
+	Seed       *int64             `json:"seed"`
+	// 前次購買或其他活動結轉的點數
+	CarryPoints float64 `json:"carry_points"`
+}
+
+// ZodiacSimulateResponse 新年氣息模擬 API 回應 DTO
+type ZodiacSimulateResponse struct {
+	Points            float64         `json:"points"`
+	Purchase          PurchaseDTO     `json:"purchase"`
+	Residual          float64         `json:"residual_points"`
+	Boxes             DistributionDTO `json:"boxes"`
+	Value             SummaryDTO      `json:"value"`
+	ROI               SummaryDTO      `json:"roi"`
+	ProfitProbability float64         `json:"profit_probability"`
+	Seed              int64           `json:"seed"`
 }
 
 // ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
 func (r CalculateRequest) ToUseCaseInput() usecase.CalculatorInput {
 	return usecase.CalculatorInput{
-		Investment: r.Investment,
-		Method:     domain.PurchaseMethod(r.Method),
-		Discount:   r.Discount,
-		BoxValues: domain.BoxValues{
-			Small:  r.BoxValues.Small,
-			Medium: r.BoxValues.Medium,
-			Large:  r.BoxValues.Large,
-			Super:  r.BoxValues.Super,
-		},
+		Investment:   r.Investment,
+		Method:       domain.PurchaseMethod(r.Method),
+		Discount:     r.Discount,
+		Trials:       r.Trials,
+		BoxValues:    r.BoxValues.toDomain(),
+		Inventory:    toBreathCollection(r.Inventory),
+		IntegerDraws: r.IntegerDraws,
+		CarryPoints:  r.CarryPoints,
+	}
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r ZodiacSimulateRequest) ToUseCaseInput() usecase.ZodiacSimulationInput {
+	return usecase.ZodiacSimulationInput{
+		Investment:  r.Investment,
+		Method:      domain.PurchaseMethod(r.Method),
+		Discount:    r.Discount,
+		BoxValues:   r.BoxValues.toDomain(),
+		Inventory:   toBreathCollection(r.Inventory),
+		Trials:      r.Trials,
+		CarryPoints: r.CarryPoints,
+	}
+}
+
+// toDomain 將心願箱價值 DTO 轉換為領域模型
+func (v BoxValues) toDomain() domain.BoxValues {
+	return domain.BoxValues{
+		Small:  v.Small,
+		Medium: v.Medium,
+		Large:  v.Large,
+		Super:  v.Super,
+	}
+}
+
+// toBreathCollection 將氣息數量 DTO 轉換為領域模型
+func toBreathCollection(breaths map[string]float64) domain.BreathCollection {
+	collection := domain.NewBreathCollection()
+	for zodiac, count := range breaths {
+		collection[domain.Zodiac(zodiac)] = count
+	}
+	return collection
+}
+
+// fromBoxCollection 將心願箱收集轉換為 map[string]float64
+func fromBoxCollection(collection domain.BoxCollection) map[string]float64 {
+	boxes := make(map[string]float64)
+	for boxType, count := range collection {
+		boxes[string(boxType)] = count
+	}
+	return boxes
+}
+
+// FromZodiacSimulationOutput 將模擬輸出轉換為 DTO
+func FromZodiacSimulationOutput(output usecase.ZodiacSimulationOutput) ZodiacSimulateResponse {
+	return ZodiacSimulateResponse{
+		Points:            output.Points,
+		Purchase:          FromPurchase(output.Purchase),
+		Residual:          output.Residual,
+		Boxes:             *fromBoxDistribution(output.Boxes),
+		Value:             FromSummary(output.Value),
+		ROI:               FromSummary(output.ROI),
+		ProfitProbability: output.ProfitProbability,
+		Seed:              output.Seed,
 	}
 }
 
@@ -55,19 +183,74 @@ func FromUseCaseOutput(output usecase.CalculatorOutput) CalculateResponse {
 		breaths[string(zodiac)] = count
 	}
 
-	// 轉換 BoxCollection 為 map[string]float64
-	boxes := make(map[string]float64)
-	for boxType, count := range output.ExpectedBoxes {
-		boxes[string(boxType)] = count
-	}
-
-	return CalculateResponse{
+	response := CalculateResponse{
 		Points:          output.Points,
+		Purchase:        FromPurchase(output.Purchase),
 		DrawCount:       output.DrawCount,
+		Residual:        output.Residual,
 		CostPerBreath:   output.CostPerBreath,
 		ExpectedBreaths: breaths,
-		ExpectedBoxes:   boxes,
+		ExpectedBoxes:   fromBoxCollection(output.ExpectedBoxes),
+		InventoryBoxes:  fromBoxCollection(output.InventoryBoxes),
+		NewBoxes:        fromBoxCollection(output.NewBoxes),
 		ExpectedValue:   output.ExpectedValue,
+		InventoryValue:  output.InventoryValue,
+		AddedValue:      output.AddedValue,
 		ROI:             output.ROI,
+		Strategy:        string(output.Strategy),
+		GreedyValue:     output.GreedyValue,
+		Distribution:    *fromBoxDistribution(output.Distribution),
+		Risk:            FromRiskReport(output.Risk),
+		Seed:            output.Seed,
+	}
+
+	return response
+}
+
+// FromRiskReport 將風險報告轉換為 DTO
+func FromRiskReport(report usecase.RiskReport) RiskDTO {
+	return RiskDTO{
+		Analytic:  report.Analytic,
+		Variance:  report.Variance,
+		StdDev:    report.StdDev,
+		ROIStdDev: report.ROIStdDev,
+		Trials:    report.Trials,
+		Value:     FromSummary(report.Value),
+		ROI:       FromSummary(report.ROI),
+	}
+}
+
+// fromBoxDistribution 將心願箱分佈轉換為 DTO
+func fromBoxDistribution(distribution usecase.BoxDistribution) *DistributionDTO {
+	dto := &DistributionDTO{
+		Trials:     distribution.Trials,
+		DrawCount:  distribution.DrawCount,
+		Boxes:      make(map[string]SummaryDTO),
+		AtLeastOne: make(map[string]float64),
+		Optimized:  distribution.Optimized,
+	}
+	for boxType, summary := range distribution.Boxes {
+		dto.Boxes[string(boxType)] = FromSummary(summary)
+	}
+	for boxType, probability := range distribution.AtLeastOne {
+		dto.AtLeastOne[string(boxType)] = probability
+	}
+	return dto
+}
+
+// FromSummary 將統計摘要轉換為 DTO
+func FromSummary(summary domain.Summary) SummaryDTO {
+	percentiles := make(map[string]float64)
+	for p, v := range summary.Percentiles {
+		percentiles[fmt.Sprintf("p%d", p)] = v
+	}
+	return SummaryDTO{
+		Mean:        summary.Mean,
+		Variance:    summary.Variance,
+		StdDev:      summary.StdDev,
+		Min:         summary.Min,
+		Max:         summary.Max,
+		Median:      summary.Median,
+		Percentiles: percentiles,
 	}
 }
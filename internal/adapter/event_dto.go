@@ -0,0 +1,99 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+)
+
+// EventResponse 活動定義 API 回應 DTO（欄位與活動定義檔相同，網頁以此取得機率與獎池）
+type EventResponse struct {
+	Version   string            `json:"version"`
+	Name      string            `json:"name"`
+	Zodiac    ZodiacEventDTO    `json:"zodiac"`
+	Starlight StarlightEventDTO `json:"starlight"`
+}
+
+// ZodiacEventDTO 新年氣息活動定義 DTO
+type ZodiacEventDTO struct {
+	CostPerDraw     float64             `json:"cost_per_draw"`
+	Rates           map[string]float64  `json:"rates"`
+	BoxRequirements map[string][]string `json:"box_requirements"`
+	BoxPriority     []string            `json:"box_priority"`
+}
+
+// StarlightEventDTO 星光錦囊活動定義 DTO
+type StarlightEventDTO struct {
+	CostPerDraw      int                 `json:"cost_per_draw"`
+	CrystalItem      string              `json:"crystal_item"`
+	CrystalsPerMerge int                 `json:"crystals_per_merge"`
+	MergedItem       string              `json:"merged_item"`
+	Stage1Pool       []RewardDTO         `json:"stage1_pool"`
+	StagePools       map[int][]RewardDTO `json:"stage_pools"`
+	UpgradeItems     map[int]string      `json:"upgrade_items"`
+	ValuableItems    []string            `json:"valuable_items"`
+	ZeroValueItems   []string            `json:"zero_value_items"`
+}
+
+// RewardDTO 獎品 DTO
+type RewardDTO struct {
+	Name        string  `json:"name"`
+	Probability float64 `json:"probability"`
+	MarketPrice int     `json:"market_price,omitempty"`
+}
+
+// FromEvent 將活動定義轉換為 DTO
+func FromEvent(event domain.EventDefinition) EventResponse {
+	zodiac := ZodiacEventDTO{
+		CostPerDraw:     event.Zodiac.CostPerDraw,
+		Rates:           make(map[string]float64, len(event.Zodiac.Rates)),
+		BoxRequirements: make(map[string][]string, len(event.Zodiac.BoxRequirements)),
+		BoxPriority:     make([]string, 0, len(event.Zodiac.BoxPriority)),
+	}
+	for z, rate := range event.Zodiac.Rates {
+		zodiac.Rates[string(z)] = rate
+	}
+	for boxType, zodiacs := range event.Zodiac.BoxRequirements {
+		names := make([]string, 0, len(zodiacs))
+		for _, z := range zodiacs {
+			names = append(names, string(z))
+		}
+		zodiac.BoxRequirements[string(boxType)] = names
+	}
+	for _, boxType := range event.Zodiac.BoxPriority {
+		zodiac.BoxPriority = append(zodiac.BoxPriority, string(boxType))
+	}
+
+	stagePools := make(map[int][]RewardDTO, len(event.Starlight.StagePools))
+	for stage, pool := range event.Starlight.StagePools {
+		stagePools[stage] = fromRewards(pool)
+	}
+
+	return EventResponse{
+		Version: event.Version,
+		Name:    event.Name,
+		Zodiac:  zodiac,
+		Starlight: StarlightEventDTO{
+			CostPerDraw:      event.Starlight.CostPerDraw,
+			CrystalItem:      event.Starlight.CrystalItem,
+			CrystalsPerMerge: event.Starlight.CrystalsPerMerge,
+			MergedItem:       event.Starlight.MergedItem,
+			Stage1Pool:       fromRewards(event.Starlight.Stage1Pool),
+			StagePools:       stagePools,
+			UpgradeItems:     event.Starlight.UpgradeItems,
+			ValuableItems:    event.Starlight.ValuableItems,
+			ZeroValueItems:   event.Starlight.ZeroValueItems,
+		},
+	}
+}
+
+// fromRewards 將獎池轉換為 DTO
+func fromRewards(pool []domain.Reward) []RewardDTO {
+	rewards := make([]RewardDTO, 0, len(pool))
+	for _, reward := range pool {
+		rewards = append(rewards, RewardDTO{
+			Name:        reward.Name,
+			Probability: reward.Probability,
+			MarketPrice: reward.MarketPrice,
+		})
+	}
+	return rewards
+}
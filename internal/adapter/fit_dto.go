@@ -0,0 +1,173 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+)
+
+// RateTestRequest 公告機率檢定 API 請求 DTO
+type RateTestRequest struct {
+	Sessions []PullSessionDTO `json:"sessions"`
+	Trials   int              `json:"trials"` // 多項精確檢定無法列舉時的模擬次數，0 表示使用預設值
+	Seed     *int64           `json:"seed"`   // 亂數種子，未指定時隨機產生
+}
+
+// RateEstimateRequest 事後機率估計 API 請求 DTO
+type RateEstimateRequest struct {
+	Sessions    []PullSessionDTO `json:"sessions"`
+	PriorWeight *float64         `json:"prior_weight"`   // 公告機率先驗相當於的抽數，未指定時使用預設值，0 表示僅依紀錄
+	Level       float64          `json:"credible_level"` // 可信區間的機率（0~1），0 表示使用預設值
+}
+
+// PullSessionDTO 單次開啟紀錄 DTO
+type PullSessionDTO struct {
+	Session string         `json:"session"`
+	Pool    string         `json:"pool"` // zodiac、stage1、stage2…
+	Counts  map[string]int `json:"counts"`
+}
+
+// RateTestResponse 公告機率檢定 API 回應 DTO
+type RateTestResponse struct {
+	Tests []FitTestDTO `json:"tests"`
+	Seed  int64        `json:"seed"`
+}
+
+// FitTestDTO 單一獎池的適合度檢定結果 DTO
+type FitTestDTO struct {
+	Pool             string       `json:"pool"`
+	Name             string       `json:"name"`
+	Sessions         int          `json:"sessions"`
+	Total            int          `json:"total"`
+	ChiSquare        float64      `json:"chi_square"`
+	DegreesOfFreedom int          `json:"degrees_of_freedom"`
+	ChiSquarePValue  float64      `json:"chi_square_p_value"`
+	LowExpected      int          `json:"low_expected"` // 期望數量低於 5 的道具數
+	ExactPValue      float64      `json:"exact_p_value"`
+	ExactMethod      string       `json:"exact_method"` // exact 或 monte_carlo
+	ExactTrials      int          `json:"exact_trials"`
+	Items            []ItemFitDTO `json:"items"`
+}
+
+// RateEstimateResponse 事後機率估計 API 回應 DTO
+type RateEstimateResponse struct {
+	Posteriors []PosteriorDTO `json:"posteriors"`
+}
+
+// PosteriorDTO 單一獎池的事後分佈 DTO
+type PosteriorDTO struct {
+	Pool        string            `json:"pool"`
+	Name        string            `json:"name"`
+	Sessions    int               `json:"sessions"`
+	Total       int               `json:"total"`
+	PriorWeight float64           `json:"prior_weight"`
+	Level       float64           `json:"credible_level"`
+	Items       []RateEstimateDTO `json:"items"`
+}
+
+// RateEstimateDTO 單一道具的事後機率 DTO（機率皆為 %）
+type RateEstimateDTO struct {
+	Item     string  `json:"item"`
+	Prior    float64 `json:"prior"`
+	Observed int     `json:"observed"`
+	Alpha    float64 `json:"alpha"`
+	Mean     float64 `json:"mean"`
+	StdDev   float64 `json:"std_dev"`
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+}
+
+// ItemFitDTO 單一道具的偏差 DTO
+type ItemFitDTO struct {
+	Item         string  `json:"item"`
+	Probability  float64 `json:"probability"`
+	Observed     int     `json:"observed"`
+	Expected     float64 `json:"expected"`
+	ObservedRate float64 `json:"observed_rate"`
+	Deviation    float64 `json:"deviation"`
+	Residual     float64 `json:"residual"`
+	PValue       float64 `json:"p_value"`
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r RateTestRequest) ToUseCaseInput() usecase.FitInput {
+	return usecase.FitInput{Log: toPullLog(r.Sessions), Trials: r.Trials}
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r RateEstimateRequest) ToUseCaseInput() usecase.EstimateInput {
+	return usecase.EstimateInput{Log: toPullLog(r.Sessions), PriorWeight: r.PriorWeight, Level: r.Level}
+}
+
+// toPullLog 將開啟紀錄 DTO 轉換為抽取紀錄
+func toPullLog(sessions []PullSessionDTO) domain.PullLog {
+	log := make(domain.PullLog, len(sessions))
+	for i, session := range sessions {
+		log[i] = domain.PullSession{Name: session.Session, Pool: session.Pool, Counts: session.Counts}
+	}
+	return log
+}
+
+// FromFitOutput 將 UseCase 輸出轉換為 DTO
+func FromFitOutput(output usecase.FitOutput) RateTestResponse {
+	response := RateTestResponse{Tests: make([]FitTestDTO, len(output.Tests)), Seed: output.Seed}
+	for i, test := range output.Tests {
+		dto := FitTestDTO{
+			Pool:             test.Pool,
+			Name:             test.Name,
+			Sessions:         test.Sessions,
+			Total:            test.Total,
+			ChiSquare:        test.ChiSquare,
+			DegreesOfFreedom: test.DegreesOfFreedom,
+			ChiSquarePValue:  test.ChiSquarePValue,
+			LowExpected:      test.LowExpected,
+			ExactPValue:      test.ExactPValue,
+			ExactMethod:      test.ExactMethod,
+			ExactTrials:      test.ExactTrials,
+			Items:            make([]ItemFitDTO, len(test.Items)),
+		}
+		for j, item := range test.Items {
+			dto.Items[j] = ItemFitDTO{
+				Item:         item.Item,
+				Probability:  item.Probability,
+				Observed:     item.Observed,
+				Expected:     item.Expected,
+				ObservedRate: item.ObservedRate,
+				Deviation:    item.Deviation,
+				Residual:     item.Residual,
+				PValue:       item.PValue,
+			}
+		}
+		response.Tests[i] = dto
+	}
+	return response
+}
+
+// FromEstimateOutput 將事後機率估計輸出轉換為 DTO
+func FromEstimateOutput(output usecase.EstimateOutput) RateEstimateResponse {
+	response := RateEstimateResponse{Posteriors: make([]PosteriorDTO, len(output.Posteriors))}
+	for i, posterior := range output.Posteriors {
+		dto := PosteriorDTO{
+			Pool:        posterior.Pool,
+			Name:        posterior.Name,
+			Sessions:    posterior.Sessions,
+			Total:       posterior.Total,
+			PriorWeight: posterior.PriorWeight,
+			Level:       posterior.Level,
+			Items:       make([]RateEstimateDTO, len(posterior.Items)),
+		}
+		for j, item := range posterior.Items {
+			dto.Items[j] = RateEstimateDTO{
+				Item:     item.Item,
+				Prior:    item.Prior,
+				Observed: item.Observed,
+				Alpha:    item.Alpha,
+				Mean:     item.Mean,
+				StdDev:   item.StdDev,
+				Lower:    item.Lower,
+				Upper:    item.Upper,
+			}
+		}
+		response.Posteriors[i] = dto
+	}
+	return response
+}
@@ -0,0 +1,76 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+)
+
+// GachaResponse 多階段抽獎模型計算結果 DTO（CLI gacha 輸出）
+type GachaResponse struct {
+	Model       string            `json:"model"`
+	CostPerDraw float64           `json:"cost_per_draw"`
+	Pool        string            `json:"pool"`  // 精確分佈開啟的獎池代號
+	Opens       int               `json:"opens"` // 精確分佈的開啟次數
+	Reach       []GachaReachDTO   `json:"reach"`
+	Outcomes    []GachaOutcomeDTO `json:"outcomes"`
+	Draws       int               `json:"draws"`
+	Trials      int               `json:"trials"`
+	Items       []GachaItemDTO    `json:"items"`
+	Value       SummaryDTO        `json:"value"`
+	Seed        int64             `json:"seed"`
+}
+
+// GachaReachDTO 開啟一次時抵達各獎池的機率 DTO
+type GachaReachDTO struct {
+	Pool  string  `json:"pool"`
+	Name  string  `json:"name"`
+	Reach float64 `json:"reach"`
+}
+
+// GachaOutcomeDTO 單一最終道具的精確分佈 DTO
+type GachaOutcomeDTO struct {
+	Name        string               `json:"name"`
+	Probability float64              `json:"probability"`
+	Count       CountDistributionDTO `json:"count"`
+}
+
+// GachaItemDTO 單一道具的期望數量與模擬分佈 DTO
+type GachaItemDTO struct {
+	Name     string     `json:"name"`
+	Expected float64    `json:"expected"`
+	Count    SummaryDTO `json:"count"`
+}
+
+// FromGachaOutput 將多階段抽獎模型的精確分佈與模擬結果轉換為 DTO
+func FromGachaOutput(model domain.GachaModel, pool string, opens int, dists []domain.ItemDistribution, reach map[string]float64, output usecase.GachaSimulationOutput) GachaResponse {
+	response := GachaResponse{
+		Model:       model.Name,
+		CostPerDraw: model.CostPerDraw,
+		Pool:        pool,
+		Opens:       opens,
+		Draws:       output.Draws,
+		Trials:      output.Trials,
+		Value:       FromSummary(output.Value),
+		Seed:        output.Seed,
+	}
+	for _, p := range model.Pools {
+		if r, ok := reach[p.ID]; ok {
+			response.Reach = append(response.Reach, GachaReachDTO{Pool: p.ID, Name: p.Name, Reach: r})
+		}
+	}
+	for _, dist := range dists {
+		response.Outcomes = append(response.Outcomes, GachaOutcomeDTO{
+			Name:        dist.Name,
+			Probability: dist.Probability,
+			Count:       FromCountDistribution(dist.Count),
+		})
+	}
+	for _, item := range output.Items {
+		response.Items = append(response.Items, GachaItemDTO{
+			Name:     item.Name,
+			Expected: item.Expected,
+			Count:    FromSummary(item.Count),
+		})
+	}
+	return response
+}
@@ -1,20 +1,55 @@
 package adapter
 
 import (
+	"MSCashItemExpected/internal/domain"
 	"MSCashItemExpected/internal/usecase"
+	"context"
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
 )
 
+// maxSimulateCount 單次模擬請求的抽數上限
+const maxSimulateCount = 1000000
+
+// maxTrials 單次請求的蒙地卡羅模擬次數上限
+const maxTrials = 100000
+
+// maxSimulatedDraws 單次請求模擬的總抽數上限（每次模擬的抽數 × 模擬次數）
+const maxSimulatedDraws = 200_000_000
+
+// maxInvestment 單次請求的投入金額與結轉點數上限
+const maxInvestment = 10_000_000
+
+// maxBodyBytes 單次請求內容的大小上限
+const maxBodyBytes = 1 << 20
+
 // Handler HTTP 處理器
 type Handler struct {
-	calculator *usecase.Calculator
+	event               domain.EventDefinition
+	calculator          *usecase.Calculator
+	starlightCalculator *usecase.StarlightCalculator
+	zodiacSimulator     *usecase.ZodiacSimulator
+	planner             *usecase.Planner
+	prices              *usecase.PriceStore
+	history             *usecase.PriceHistoryStore
+	valuer              *usecase.HistoryValuer
+	rateTester          *usecase.RateTester
 }
 
 // NewHandler 建立 Handler
-func NewHandler(calculator *usecase.Calculator) *Handler {
+func NewHandler(event domain.EventDefinition, calculator *usecase.Calculator, starlightCalculator *usecase.StarlightCalculator, zodiacSimulator *usecase.ZodiacSimulator, planner *usecase.Planner, prices *usecase.PriceStore, history *usecase.PriceHistoryStore, rateTester *usecase.RateTester) *Handler {
 	return &Handler{
-		calculator: calculator,
+		event:               event,
+		calculator:          calculator,
+		starlightCalculator: starlightCalculator,
+		zodiacSimulator:     zodiacSimulator,
+		planner:             planner,
+		prices:              prices,
+		history:             history,
+		valuer:              usecase.NewHistoryValuer(calculator, starlightCalculator),
+		rateTester:          rateTester,
 	}
 }
 
@@ -27,21 +62,773 @@ func (h *Handler) Calculate(w http.ResponseWriter, r *http.Request) {
 
 	// 解析請求
 	var req CalculateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Trials < 0 || req.Trials > maxTrials {
+		http.Error(w, "Invalid trials", http.StatusBadRequest)
+		return
+	}
+	if !validAmounts(req.Investment, req.CarryPoints) {
+		http.Error(w, "Invalid investment", http.StatusBadRequest)
+		return
+	}
+	if !h.validInventory(req.Inventory) {
+		http.Error(w, "Invalid inventory", http.StatusBadRequest)
 		return
 	}
 
 	// 轉換為 UseCase 輸入
 	input := req.ToUseCaseInput()
+	draws := h.calculator.DrawCount(input.Investment, input.Method, input.Discount, input.CarryPoints)
+	if !withinWorkload(draws, orDefault(input.Trials, usecase.DefaultRiskTrials)) {
+		http.Error(w, "Simulation too large", http.StatusBadRequest)
+		return
+	}
 
-	// 執行計算
-	output := h.calculator.Calculate(input)
+	// 執行計算（每個請求使用獨立種子的計算器）
+	output, err := h.calculator.WithSeed(resolveSeed(req.Seed)).Calculate(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 
 	// 轉換為回應 DTO
 	response := FromUseCaseOutput(output)
 
 	// 回傳 JSON
+	writeJSON(w, response)
+}
+
+// ZodiacSimulate 處理新年氣息模擬請求
+func (h *Handler) ZodiacSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req ZodiacSimulateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Trials <= 0 || req.Trials > maxTrials {
+		http.Error(w, "Invalid trials", http.StatusBadRequest)
+		return
+	}
+	if !validAmounts(req.Investment, req.CarryPoints) {
+		http.Error(w, "Invalid investment", http.StatusBadRequest)
+		return
+	}
+	if !h.validInventory(req.Inventory) {
+		http.Error(w, "Invalid inventory", http.StatusBadRequest)
+		return
+	}
+
+	input := req.ToUseCaseInput()
+	if !withinWorkload(h.zodiacSimulator.DrawCount(input.Investment, input.Method, input.Discount, input.CarryPoints), input.Trials) {
+		http.Error(w, "Simulation too large", http.StatusBadRequest)
+		return
+	}
+
+	// 執行模擬
+	output, err := h.zodiacSimulator.WithSeed(resolveSeed(req.Seed)).Simulate(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromZodiacSimulationOutput(output))
+}
+
+// ZodiacTarget 處理新年氣息目標反推請求
+func (h *Handler) ZodiacTarget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req ZodiacTargetRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Trials > maxTrials || req.MaxDraws < 0 || req.MaxDraws > maxSimulateCount {
+		http.Error(w, "Invalid simulation count", http.StatusBadRequest)
+		return
+	}
+	if !h.validInventory(req.Inventory) {
+		http.Error(w, "Invalid inventory", http.StatusBadRequest)
+		return
+	}
+	if !withinWorkload(orDefault(req.MaxDraws, usecase.DefaultMaxTargetDraws), req.Trials) {
+		http.Error(w, "Simulation too large", http.StatusBadRequest)
+		return
+	}
+
+	// 執行反推
+	output, err := h.calculator.WithSeed(resolveSeed(req.Seed)).SolveTarget(r.Context(), req.ToUseCaseInput())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromTargetOutput(output))
+}
+
+// StarlightExpected 處理星光錦囊期望值請求
+func (h *Handler) StarlightExpected(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req StarlightExpectedRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Trials < 0 || req.Trials > maxTrials {
+		http.Error(w, "Invalid trials", http.StatusBadRequest)
+		return
+	}
+	if !validAmounts(req.Investment, req.CarryPoints) {
+		http.Error(w, "Invalid investment", http.StatusBadRequest)
+		return
+	}
+
+	input := req.ToUseCaseInput()
+	draws := h.starlightCalculator.DrawCount(input.Investment, input.Method, input.Discount, input.CarryPoints)
+	if !withinWorkload(draws, orDefault(input.Trials, usecase.DefaultRiskTrials)) {
+		http.Error(w, "Simulation too large", http.StatusBadRequest)
+		return
+	}
+
+	if input.Integer {
+		prices, err := h.crystalPrice(input.Prices)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		input.Prices = prices
+	}
+
+	// 執行計算
+	output, err := h.starlightCalculator.WithSeed(resolveSeed(req.Seed)).CalculateExpected(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromStarlightExpectedOutput(output))
+}
+
+// StarlightSimulate 處理星光錦囊模擬請求
+func (h *Handler) StarlightSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req StarlightSimulateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.DrawCount < 0 || req.DrawCount > maxSimulateCount ||
+		req.LadderCount < 0 || req.LadderCount > maxSimulateCount {
+		http.Error(w, "Invalid simulation count", http.StatusBadRequest)
+		return
+	}
+
+	calculator := h.starlightCalculator.WithSeed(resolveSeed(req.Seed))
+	simResult, err := calculator.SimulateStage1(r.Context(), req.DrawCount, calculator.Event().Stage1Pool)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 未指定時以模擬所得玲瓏星光合成星光結晶體（整數合成，不足一組保留）
+	ladderCount := req.LadderCount
+	leftover := 0
+	if ladderCount == 0 {
+		perMerge := calculator.Event().CrystalsPerMerge
+		ladderCount = simResult.CrystalCount / perMerge
+		leftover = simResult.CrystalCount % perMerge
+	}
+	ladderResult, err := calculator.SimulateLadder(r.Context(), ladderCount)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := FromSimulationResult(
+		simResult,
+		ladderResult,
+		calculator.CalculateSurvivalRate(ladderResult),
+		calculator.CalculateTheoreticalSurvival(),
+	)
+	response.LeftoverCrystals = leftover
+	response.Seed = calculator.Seed()
+
+	// 回傳 JSON
+	writeJSON(w, response)
+}
+
+// StarlightLadder 處理星光錦囊階梯精確分佈請求
+func (h *Handler) StarlightLadder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req StarlightLadderRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Crystals < 0 || req.Crystals > maxSimulateCount {
+		http.Error(w, "Invalid crystal count", http.StatusBadRequest)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromLadderDistribution(h.starlightCalculator.CalculateLadderDistribution(req.Crystals)))
+}
+
+// StarlightPolicy 處理星光錦囊階梯最佳策略請求
+func (h *Handler) StarlightPolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req StarlightPolicyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Crystals < 0 || req.Crystals > maxSimulateCount {
+		http.Error(w, "Invalid crystal count", http.StatusBadRequest)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromLadderPolicy(h.starlightCalculator.CalculateLadderPolicy(req.Crystals, req.Prices)))
+}
+
+// StarlightTarget 處理星光錦囊目標反推請求
+func (h *Handler) StarlightTarget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req StarlightTargetRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Trials > maxTrials || req.MaxDraws < 0 || req.MaxDraws > maxSimulateCount {
+		http.Error(w, "Invalid simulation count", http.StatusBadRequest)
+		return
+	}
+	if !withinWorkload(orDefault(req.MaxDraws, usecase.DefaultMaxTargetDraws), req.Trials) {
+		http.Error(w, "Simulation too large", http.StatusBadRequest)
+		return
+	}
+
+	// 執行反推
+	output, err := h.starlightCalculator.WithSeed(resolveSeed(req.Seed)).SolveTarget(r.Context(), req.ToUseCaseInput())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromTargetOutput(output))
+}
+
+// Plan 處理點數計畫請求
+func (h *Handler) Plan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req PlanRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validAmounts(req.Investment, req.CarryPoints) {
+		http.Error(w, "Invalid investment", http.StatusBadRequest)
+		return
+	}
+	for _, step := range req.Steps {
+		if !validAmounts(step.Points) {
+			http.Error(w, "Invalid step points", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// 執行計畫
+	output, err := h.planner.Plan(req.ToUseCaseInput())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromPlanOutput(output))
+}
+
+// Portfolio 處理多活動預算分配請求
+func (h *Handler) Portfolio(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析請求
+	var req PortfolioRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !validAmounts(req.Budget) {
+		http.Error(w, "Invalid budget", http.StatusBadRequest)
+		return
+	}
+	if req.Trials < 0 || req.Trials > maxTrials {
+		http.Error(w, "Invalid simulation count", http.StatusBadRequest)
+		return
+	}
+	if req.Zodiac != nil && !h.validInventory(req.Zodiac.Inventory) {
+		http.Error(w, "Invalid inventory", http.StatusBadRequest)
+		return
+	}
+	if req.Starlight != nil && req.Starlight.IntegerMerge {
+		prices, err := h.crystalPrice(req.Starlight.Prices)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		req.Starlight.Prices = prices
+	}
+
+	// 執行分配（新年氣息以指定種子模擬）
+	calculator := h.calculator.WithSeed(resolveSeed(req.Seed))
+	output, err := usecase.Allocate(r.Context(), req.ToUseCaseInput(), req.ToValueModels(calculator, h.starlightCalculator))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	response := FromAllocationOutput(output)
+	response.Seed = calculator.Seed()
+	writeJSON(w, response)
+}
+
+// Event 處理活動定義請求，回傳目前載入的機率與獎池（網頁依此計算，不另行寫死）
+func (h *Handler) Event(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromEvent(h.event))
+}
+
+// Purchases 處理購買方式列表請求
+func (h *Handler) Purchases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromPurchaseRegistry(h.starlightCalculator.Purchases()))
+}
+
+// Prices 處理道具價格表請求：GET 讀取，PUT 以請求內容取代並保存，PATCH 只更新請求中的道具
+func (h *Handler) Prices(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		prices, err := h.prices.Prices()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, FromPriceBook(prices, h.prices.Items()))
+	case http.MethodPut, http.MethodPatch:
+		if !isJSON(w, r) {
+			return
+		}
+		var req PricesRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		prices := req.ToDomain()
+		if err := prices.Validate(); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// 保存價格表
+		update := h.prices.Update
+		if r.Method == http.MethodPatch {
+			update = h.prices.Merge
+		}
+		saved, err := update(prices)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, FromPriceBook(saved, h.prices.Items()))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// PriceHistory 處理價格歷史請求：GET 讀取所有快照，POST 記錄某日的道具價格
+func (h *Handler) PriceHistory(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		history, err := h.history.History()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, FromPriceHistory(history))
+	case http.MethodPost:
+		if !isJSON(w, r) {
+			return
+		}
+		var req PriceSnapshotRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		date, prices, err := req.ToDomain()
+		if err == nil {
+			err = prices.Validate()
+		}
+		if err == nil && len(prices) == 0 {
+			err = errors.New("價格快照至少需要一個道具")
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// 記錄快照
+		history, err := h.history.Record(date, prices)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, FromPriceHistory(history))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ValueHistory 處理價值時間序列請求：以價格歷史評估固定投入金額在各快照日期的期望價值與報酬率
+func (h *Handler) ValueHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ValueSeriesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !validAmounts(req.Investment) {
+		http.Error(w, "Invalid investment", http.StatusBadRequest)
+		return
+	}
+	if !h.validInventory(req.Inventory) {
+		http.Error(w, "Invalid inventory", http.StatusBadRequest)
+		return
+	}
+	input, err := req.ToUseCaseInput()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	history, err := h.history.History()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 執行計算
+	output, err := h.valuer.Series(history, input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromSeriesOutput(output))
+}
+
+// RateTest 處理公告機率檢定請求：以抽取紀錄對各獎池進行卡方與精確檢定
+func (h *Handler) RateTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RateTestRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// 驗證參數
+	if req.Trials < 0 || req.Trials > maxTrials {
+		http.Error(w, "Invalid trials", http.StatusBadRequest)
+		return
+	}
+	input := req.ToUseCaseInput()
+	if err := usecase.CheckFitLog(input.Log); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 執行檢定
+	output, err := h.rateTester.WithSeed(resolveSeed(req.Seed)).Test(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromFitOutput(output))
+}
+
+// resolveSeed 取得請求指定的亂數種子，未指定時隨機產生
+func resolveSeed(seed *int64) int64 {
+	if seed != nil {
+		return *seed
+	}
+	return usecase.NewSeed()
+}
+
+// decodeJSON 解析請求內容（限制大小為 maxBodyBytes），失敗時回傳錯誤並回報 false
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// isJSON 確認寫入檔案的請求以 JSON 送出，否則回傳 415
+// 跨站網頁無法在未經 CORS 預檢的情況下送出 JSON 請求，可避免其他網站透過瀏覽器改寫本機的價格檔
+func isJSON(w http.ResponseWriter, r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+	return true
+}
+
+// writeError 回傳錯誤：請求中斷時回傳 503，其餘視為請求參數錯誤
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		http.Error(w, "Request canceled", http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// validAmounts 檢查投入金額與結轉點數皆介於 0 與 maxInvestment 之間
+func validAmounts(amounts ...float64) bool {
+	for _, amount := range amounts {
+		if amount < 0 || amount > maxInvestment {
+			return false
+		}
+	}
+	return true
+}
+
+// withinWorkload 檢查每次模擬的抽數 × 模擬次數不超過 maxSimulatedDraws
+func withinWorkload(draws, trials int) bool {
+	return float64(draws)*float64(trials) <= maxSimulatedDraws
+}
+
+// validInventory 檢查已持有的氣息皆為活動中的生肖且數量不為負
+func (h *Handler) validInventory(inventory map[string]float64) bool {
+	zodiacs := make(map[string]bool)
+	for _, zodiac := range h.zodiacSimulator.Event().Zodiacs() {
+		zodiacs[string(zodiac)] = true
+	}
+	for name, count := range inventory {
+		if !zodiacs[name] || count < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// crystalPrice 整數合成時剩餘的玲瓏星光需要價格：請求未指定時沿用價格表保存的價格（網頁表單的玲瓏星光欄位）
+func (h *Handler) crystalPrice(prices map[string]int) (map[string]int, error) {
+	crystal := h.starlightCalculator.Event().CrystalItem
+	if _, ok := prices[crystal]; ok {
+		return prices, nil
+	}
+	saved, err := h.prices.Prices()
+	if err != nil {
+		return nil, err
+	}
+	price, ok := saved[crystal]
+	if !ok {
+		return prices, nil
+	}
+
+	merged := map[string]int{crystal: price}
+	for name, p := range prices {
+		merged[name] = p
+	}
+	return merged, nil
+}
+
+// orDefault 未指定（0 或負數）的模擬次數或抽數上限改用 UseCase 的預設值
+func orDefault(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// writeJSON 以 JSON 格式回傳資料
+func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(v)
+}
+
+// RateEstimate 處理事後機率估計請求：以公告機率為先驗，結合抽取紀錄估計各道具機率與可信區間
+func (h *Handler) RateEstimate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RateEstimateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// 執行估計
+	output, err := h.rateTester.Estimate(req.ToUseCaseInput())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, FromEstimateOutput(output))
+}
+
+// Luck 處理運氣百分位請求：計算實際結果在相同花費模擬分佈中的位置
+func (h *Handler) Luck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LuckRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// 驗證參數
+	if req.Trials < 0 || req.Trials > maxTrials {
+		http.Error(w, "Invalid trials", http.StatusBadRequest)
+		return
+	}
+	if req.Draws < 0 {
+		http.Error(w, "Invalid draws", http.StatusBadRequest)
+		return
+	}
+	if !validAmounts(req.Investment, req.CarryPoints) {
+		http.Error(w, "Invalid investment", http.StatusBadRequest)
+		return
+	}
+
+	// 未指定實際抽數時依投入金額計算，限制抽數 × 模擬次數
+	draws := req.Draws
+	if draws == 0 {
+		switch req.Event {
+		case usecase.PlanStarlight:
+			input := req.ToStarlightInput()
+			draws = h.starlightCalculator.DrawCount(input.Investment, input.Method, input.Discount, input.CarryPoints)
+		case usecase.PlanZodiac:
+			input := req.ToZodiacInput()
+			draws = h.zodiacSimulator.DrawCount(input.Investment, input.Method, input.Discount, input.CarryPoints)
+		}
+	}
+	if !withinWorkload(draws, orDefault(req.Trials, usecase.DefaultLuckTrials)) {
+		http.Error(w, "Simulation too large", http.StatusBadRequest)
+		return
+	}
+
+	// 依活動執行模擬
+	seed := resolveSeed(req.Seed)
+	var response LuckResponse
+	switch req.Event {
+	case usecase.PlanStarlight:
+		input := req.ToStarlightInput()
+		if input.Integer {
+			prices, err := h.crystalPrice(input.Prices)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			input.Prices = prices
+		}
+		output, err := h.starlightCalculator.WithSeed(seed).Luck(r.Context(), input)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		response = FromStarlightLuckOutput(output)
+	case usecase.PlanZodiac:
+		output, err := h.zodiacSimulator.WithSeed(seed).Luck(r.Context(), req.ToZodiacInput())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		response = FromZodiacLuckOutput(output)
+	default:
+		http.Error(w, "Invalid event", http.StatusBadRequest)
+		return
+	}
+
+	// 回傳 JSON
+	writeJSON(w, response)
 }
@@ -0,0 +1,158 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+	"fmt"
+)
+
+// StarlightLadderRequest 星光錦囊階梯精確分佈 API 請求 DTO
+type StarlightLadderRequest struct {
+	Crystals int `json:"crystals"` // 星光結晶體數量
+}
+
+// StarlightLadderResponse 星光錦囊階梯精確分佈 API 回應 DTO
+type StarlightLadderResponse struct {
+	Crystals  int                  `json:"crystals"`
+	Stages    []LadderStageDTO     `json:"stages"`
+	FinalItem string               `json:"final_item"`
+	Survival  float64              `json:"survival"`
+	Final     CountDistributionDTO `json:"final"`
+	Rewards   []LadderRewardDTO    `json:"rewards"`
+}
+
+// LadderReportDTO 階梯精確分佈與模擬結果 DTO（CLI ladder 子命令輸出）
+// 精確分佈欄位與 StarlightLadderResponse 相同，另附各次模擬結果
+type LadderReportDTO struct {
+	StarlightLadderResponse
+	Simulations []LadderResponse `json:"simulations"`
+	Seed        int64            `json:"seed"`
+}
+
+// LadderStageDTO 階梯單一階段機率 DTO
+type LadderStageDTO struct {
+	Stage   int     `json:"stage"`
+	Reach   float64 `json:"reach"`
+	Upgrade float64 `json:"upgrade"`
+	Stop    float64 `json:"stop"`
+}
+
+// LadderRewardDTO 最終獎品分佈 DTO
+type LadderRewardDTO struct {
+	Name        string               `json:"name"`
+	Probability float64              `json:"probability"`
+	Count       CountDistributionDTO `json:"count"`
+}
+
+// CountDistributionDTO 數量精確分佈 DTO
+type CountDistributionDTO struct {
+	Mean        float64        `json:"mean"`
+	Variance    float64        `json:"variance"`
+	StdDev      float64        `json:"std_dev"`
+	AtLeastOne  float64        `json:"at_least_one"`
+	Percentiles map[string]int `json:"percentiles"`
+	Mass        []CountMassDTO `json:"mass"`
+}
+
+// CountMassDTO 數量機率質量 DTO
+type CountMassDTO struct {
+	Count       int     `json:"count"`
+	Probability float64 `json:"probability"`
+	Cumulative  float64 `json:"cumulative"`
+}
+
+// StarlightPolicyRequest 星光錦囊階梯最佳策略 API 請求 DTO
+type StarlightPolicyRequest struct {
+	Crystals int            `json:"crystals"` // 星光結晶體數量（用於計算總增益）
+	Prices   map[string]int `json:"prices"`   // 中間道具與最終獎品的價格
+}
+
+// StarlightPolicyResponse 星光錦囊階梯最佳策略 API 回應 DTO
+type StarlightPolicyResponse struct {
+	Crystals     int                 `json:"crystals"`
+	Decisions    []LadderDecisionDTO `json:"decisions"`
+	AlwaysOpenEV float64             `json:"always_open_ev"`
+	OptimalEV    float64             `json:"optimal_ev"`
+	Gain         float64             `json:"gain"`
+}
+
+// LadderDecisionDTO 階梯單一階段決策 DTO
+type LadderDecisionDTO struct {
+	Stage     int     `json:"stage"`
+	Item      string  `json:"item"`
+	SellValue float64 `json:"sell_value"`
+	OpenValue float64 `json:"open_value"`
+	Open      bool    `json:"open"`
+}
+
+// FromLadderPolicy 將階梯最佳策略轉換為 DTO
+func FromLadderPolicy(policy usecase.LadderPolicy) StarlightPolicyResponse {
+	response := StarlightPolicyResponse{
+		Crystals:     policy.Crystals,
+		AlwaysOpenEV: policy.AlwaysOpenEV,
+		OptimalEV:    policy.OptimalEV,
+		Gain:         policy.Gain,
+	}
+	for _, decision := range policy.Decisions {
+		response.Decisions = append(response.Decisions, LadderDecisionDTO{
+			Stage:     decision.Stage,
+			Item:      decision.Item,
+			SellValue: decision.SellValue,
+			OpenValue: decision.OpenValue,
+			Open:      decision.Open,
+		})
+	}
+	return response
+}
+
+// FromLadderDistribution 將階梯精確分佈轉換為 DTO
+func FromLadderDistribution(dist usecase.LadderDistribution) StarlightLadderResponse {
+	response := StarlightLadderResponse{
+		Crystals:  dist.Crystals,
+		FinalItem: dist.FinalItem,
+		Survival:  dist.Survival,
+		Final:     FromCountDistribution(dist.Final),
+	}
+	for _, stage := range dist.Stages {
+		response.Stages = append(response.Stages, LadderStageDTO{
+			Stage:   stage.Stage,
+			Reach:   stage.Reach,
+			Upgrade: stage.Upgrade,
+			Stop:    stage.Stop,
+		})
+	}
+	for _, reward := range dist.Rewards {
+		response.Rewards = append(response.Rewards, LadderRewardDTO{
+			Name:        reward.Name,
+			Probability: reward.Probability,
+			Count:       FromCountDistribution(reward.Count),
+		})
+	}
+	return response
+}
+
+// FromCountDistribution 將數量精確分佈轉換為 DTO
+func FromCountDistribution(dist domain.CountDistribution) CountDistributionDTO {
+	percentiles := make(map[string]int)
+	for p, v := range dist.Percentiles {
+		percentiles[fmt.Sprintf("p%d", p)] = v
+	}
+
+	mass := make([]CountMassDTO, len(dist.Mass))
+	for i, m := range dist.Mass {
+		mass[i] = CountMassDTO{
+			Count:       m.Count,
+			Probability: m.Probability,
+			Cumulative:  m.Cumulative,
+		}
+	}
+
+	return CountDistributionDTO{
+		Mean:        dist.Mean,
+		Variance:    dist.Variance,
+		StdDev:      dist.StdDev,
+		AtLeastOne:  dist.AtLeastOne,
+		Percentiles: percentiles,
+		Mass:        mass,
+	}
+}
@@ -0,0 +1,99 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+)
+
+// LuckRequest 運氣百分位 API 請求 DTO
+type LuckRequest struct {
+	Event      string  `json:"event"` // zodiac 或 starlight
+	Investment float64 `json:"investment"`
+	Method     string  `json:"method"`
+	Discount   float64 `json:"discount"`
+	Draws      int     `json:"draws"` // 實際抽數，0 表示依投入金額計算
+	// 實際結果：星光錦囊為道具名稱 -> 數量，新年氣息為心願箱或生肖名稱 -> 數量
+	Outcome   map[string]int `json:"outcome"`
+	Prices    map[string]int `json:"prices"`     // 星光錦囊道具價格
+	BoxValues BoxValues      `json:"box_values"` // 新年氣息心願箱價值
+	Trials    int            `json:"trials"`     // 模擬次數，0 表示使用預設值
+	Seed      *int64         `json:"seed"`       // 亂數種子，未指定時隨機產生
+	// 未合成的玲瓏星光以 prices 中的價格計價
+	IntegerMerge bool    `json:"integer_merge"`
+	CarryPoints  float64 `json:"carry_points"` // 前次購買或其他活動結轉的點數
+}
+
+// LuckResponse 運氣百分位 API 回應 DTO
+type LuckResponse struct {
+	Event       string      `json:"event"`
+	Points      float64     `json:"points"`
+	Purchase    PurchaseDTO `json:"purchase"`
+	DrawCount   int         `json:"draw_count"`
+	Realized    float64     `json:"realized_value"`
+	RealizedROI float64     `json:"realized_roi"`
+	Percentile  float64     `json:"percentile"` // 越高表示運氣越好
+	Better      float64     `json:"better"`     // 模擬結果優於實際結果的比例 (%)
+	Worse       float64     `json:"worse"`      // 模擬結果差於實際結果的比例 (%)
+	Trials      int         `json:"trials"`
+	Value       SummaryDTO  `json:"value"`
+	ROI         SummaryDTO  `json:"roi"`
+	Seed        int64       `json:"seed"`
+}
+
+// ToStarlightInput 將 DTO 轉換為星光錦囊 UseCase 輸入
+func (r LuckRequest) ToStarlightInput() usecase.StarlightLuckInput {
+	return usecase.StarlightLuckInput{
+		Investment:  r.Investment,
+		Method:      domain.PurchaseMethod(r.Method),
+		Discount:    r.Discount,
+		Draws:       r.Draws,
+		Outcome:     r.Outcome,
+		Prices:      r.Prices,
+		Trials:      r.Trials,
+		Integer:     r.IntegerMerge,
+		CarryPoints: r.CarryPoints,
+	}
+}
+
+// ToZodiacInput 將 DTO 轉換為新年氣息 UseCase 輸入
+func (r LuckRequest) ToZodiacInput() usecase.ZodiacLuckInput {
+	return usecase.ZodiacLuckInput{
+		Investment:  r.Investment,
+		Method:      domain.PurchaseMethod(r.Method),
+		Discount:    r.Discount,
+		Draws:       r.Draws,
+		Outcome:     r.Outcome,
+		BoxValues:   r.BoxValues.toDomain(),
+		Trials:      r.Trials,
+		CarryPoints: r.CarryPoints,
+	}
+}
+
+// FromStarlightLuckOutput 將星光錦囊運氣百分位輸出轉換為 DTO
+func FromStarlightLuckOutput(output usecase.StarlightLuckOutput) LuckResponse {
+	return fromLuckReport(usecase.PlanStarlight, output.Points, output.Purchase, output.DrawCount, output.Luck, output.Seed)
+}
+
+// FromZodiacLuckOutput 將新年氣息運氣百分位輸出轉換為 DTO
+func FromZodiacLuckOutput(output usecase.ZodiacLuckOutput) LuckResponse {
+	return fromLuckReport(usecase.PlanZodiac, output.Points, output.Purchase, output.DrawCount, output.Luck, output.Seed)
+}
+
+// fromLuckReport 組合運氣百分位回應
+func fromLuckReport(event string, points float64, purchase domain.Purchase, drawCount int, report usecase.LuckReport, seed int64) LuckResponse {
+	return LuckResponse{
+		Event:       event,
+		Points:      points,
+		Purchase:    FromPurchase(purchase),
+		DrawCount:   drawCount,
+		Realized:    report.Realized,
+		RealizedROI: report.RealizedROI,
+		Percentile:  report.Percentile,
+		Better:      report.Better,
+		Worse:       report.Worse,
+		Trials:      report.Trials,
+		Value:       FromSummary(report.Value),
+		ROI:         FromSummary(report.ROI),
+		Seed:        seed,
+	}
+}
@@ -0,0 +1,75 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+)
+
+// PlanRequest 點數計畫 API 請求 DTO
+type PlanRequest struct {
+	Investment  float64       `json:"investment"`
+	Method      string        `json:"method"`
+	Discount    float64       `json:"discount"`
+	CarryPoints float64       `json:"carry_points"` // 前次購買結轉的點數
+	Steps       []PlanStepDTO `json:"steps"`        // 依序使用點數的活動
+}
+
+// PlanStepDTO 點數計畫活動 DTO
+type PlanStepDTO struct {
+	Event  string  `json:"event"`  // 活動代號（zodiac、starlight）
+	Points float64 `json:"points"` // 分配的點數，0 表示使用所有剩餘點數
+}
+
+// PlanResponse 點數計畫 API 回應 DTO
+type PlanResponse struct {
+	Purchase PurchaseDTO   `json:"purchase"`
+	Points   float64       `json:"points"` // 可用點數總和（含結轉點數）
+	Steps    []DrawPlanDTO `json:"steps"`
+	Residual float64       `json:"residual_points"` // 可結轉至下次購買的剩餘點數
+}
+
+// DrawPlanDTO 活動整數抽數 DTO
+type DrawPlanDTO struct {
+	Event       string  `json:"event"`
+	Points      float64 `json:"points"`
+	CostPerDraw float64 `json:"cost_per_draw"`
+	Draws       int     `json:"draws"`
+	Spent       float64 `json:"spent"`
+	Residual    float64 `json:"residual_points"`
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r PlanRequest) ToUseCaseInput() usecase.PlanInput {
+	steps := make([]usecase.PlanStep, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		steps = append(steps, usecase.PlanStep{Event: step.Event, Points: step.Points})
+	}
+	return usecase.PlanInput{
+		Investment:  r.Investment,
+		Method:      domain.PurchaseMethod(r.Method),
+		Discount:    r.Discount,
+		CarryPoints: r.CarryPoints,
+		Steps:       steps,
+	}
+}
+
+// FromPlanOutput 將 UseCase 輸出轉換為 DTO
+func FromPlanOutput(output usecase.PlanOutput) PlanResponse {
+	steps := make([]DrawPlanDTO, 0, len(output.Plan.Steps))
+	for _, step := range output.Plan.Steps {
+		steps = append(steps, DrawPlanDTO{
+			Event:       step.Name,
+			Points:      step.Points,
+			CostPerDraw: step.CostPerDraw,
+			Draws:       step.Draws,
+			Spent:       step.Spent,
+			Residual:    step.Residual,
+		})
+	}
+	return PlanResponse{
+		Purchase: FromPurchase(output.Purchase),
+		Points:   output.Plan.Points,
+		Steps:    steps,
+		Residual: output.Plan.Residual,
+	}
+}
@@ -0,0 +1,103 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+)
+
+// PortfolioRequest 預算分配 API 請求 DTO
+type PortfolioRequest struct {
+	Budget       float64 `json:"budget"`
+	Method       string  `json:"method"`
+	Discount     float64 `json:"discount"`
+	Step         float64 `json:"step"`          // 分配金額的最小單位，0 表示預算的 1/20
+	RiskAversion float64 `json:"risk_aversion"` // 風險趨避係數 λ（目標為 期望價值 − λ·標準差）
+	Trials       int     `json:"trials"`        // 新年氣息估計標準差的模擬次數，0 表示使用預設值
+	Seed         *int64  `json:"seed"`
+	// 參與分配的活動，未指定的活動不分配
+	Zodiac    *PortfolioZodiacDTO    `json:"zodiac"`
+	Starlight *PortfolioStarlightDTO `json:"starlight"`
+}
+
+// PortfolioZodiacDTO 新年氣息價值模型 DTO
+type PortfolioZodiacDTO struct {
+	BoxValues BoxValues          `json:"box_values"`
+	Inventory map[string]float64 `json:"inventory"`
+}
+
+// PortfolioStarlightDTO 星光錦囊價值模型 DTO
+type PortfolioStarlightDTO struct {
+	Prices       map[string]int `json:"prices"`
+	IntegerMerge bool           `json:"integer_merge"`
+}
+
+// PortfolioResponse 預算分配 API 回應 DTO
+type PortfolioResponse struct {
+	Allocations   []AllocationDTO `json:"allocations"`
+	Unspent       float64         `json:"unspent"`
+	ExpectedValue float64         `json:"expected_value"` // 期望總價值（含未花費的預算）
+	StdDev        float64         `json:"std_dev"`
+	Objective     float64         `json:"objective"`
+	ROI           float64         `json:"roi"`
+	Step          float64         `json:"step"`
+	Candidates    int             `json:"candidates"`
+	Seed          int64           `json:"seed"`
+}
+
+// AllocationDTO 單一活動分配結果 DTO
+type AllocationDTO struct {
+	Event         string  `json:"event"`
+	Investment    float64 `json:"investment"`
+	Cost          float64 `json:"cost"`
+	Points        float64 `json:"points"`
+	ExpectedValue float64 `json:"expected_value"`
+	StdDev        float64 `json:"std_dev"`
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r PortfolioRequest) ToUseCaseInput() usecase.AllocationInput {
+	return usecase.AllocationInput{
+		Budget:       r.Budget,
+		Method:       domain.PurchaseMethod(r.Method),
+		Discount:     r.Discount,
+		Step:         r.Step,
+		RiskAversion: r.RiskAversion,
+	}
+}
+
+// ToValueModels 依請求中的活動建立價值模型
+func (r PortfolioRequest) ToValueModels(calculator *usecase.Calculator, starlightCalculator *usecase.StarlightCalculator) []usecase.ValueModel {
+	var models []usecase.ValueModel
+	if r.Zodiac != nil {
+		models = append(models, usecase.NewZodiacModel(calculator, r.Zodiac.BoxValues.toDomain(), toBreathCollection(r.Zodiac.Inventory), r.Trials))
+	}
+	if r.Starlight != nil {
+		models = append(models, usecase.NewStarlightModel(starlightCalculator, r.Starlight.Prices, r.Starlight.IntegerMerge))
+	}
+	return models
+}
+
+// FromAllocationOutput 將 UseCase 輸出轉換為 DTO
+func FromAllocationOutput(output usecase.AllocationOutput) PortfolioResponse {
+	allocations := make([]AllocationDTO, 0, len(output.Allocations))
+	for _, allocation := range output.Allocations {
+		allocations = append(allocations, AllocationDTO{
+			Event:         allocation.Event,
+			Investment:    allocation.Investment,
+			Cost:          allocation.Cost,
+			Points:        allocation.Points,
+			ExpectedValue: allocation.Mean,
+			StdDev:        allocation.StdDev,
+		})
+	}
+	return PortfolioResponse{
+		Allocations:   allocations,
+		Unspent:       output.Unspent,
+		ExpectedValue: output.Mean,
+		StdDev:        output.StdDev,
+		Objective:     output.Objective,
+		ROI:           output.ROI,
+		Step:          output.Step,
+		Candidates:    output.Candidates,
+	}
+}
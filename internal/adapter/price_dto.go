@@ -0,0 +1,33 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+)
+
+// PricesRequest 道具價格表更新 API 請求 DTO（取代整份價格表）
+type PricesRequest struct {
+	Prices map[string]int `json:"prices"`
+}
+
+// PricesResponse 道具價格表 API 回應 DTO
+type PricesResponse struct {
+	Prices map[string]int `json:"prices"`
+	Items  []string       `json:"items"` // 道具名稱（依活動定義順序，其餘依名稱排序）
+}
+
+// ToDomain 將 DTO 轉換為價格表
+func (r PricesRequest) ToDomain() domain.PriceBook {
+	prices := make(domain.PriceBook, len(r.Prices))
+	for item, price := range r.Prices {
+		prices[item] = price
+	}
+	return prices
+}
+
+// FromPriceBook 將價格表轉換為 DTO，order 為道具名稱的排列順序
+func FromPriceBook(prices domain.PriceBook, order []string) PricesResponse {
+	return PricesResponse{
+		Prices: prices,
+		Items:  prices.Items(order),
+	}
+}
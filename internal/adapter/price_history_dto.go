@@ -0,0 +1,126 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+	"fmt"
+	"time"
+)
+
+// PriceSnapshotRequest 價格快照記錄 API 請求 DTO
+type PriceSnapshotRequest struct {
+	Date   string         `json:"date"`   // YYYY-MM-DD，未指定時為今日
+	Prices map[string]int `json:"prices"` // 當日的道具價格（可只包含有變動的道具）
+}
+
+// PriceHistoryResponse 價格歷史 API 回應 DTO
+type PriceHistoryResponse struct {
+	Snapshots []PriceSnapshotDTO `json:"snapshots"`
+}
+
+// PriceSnapshotDTO 價格快照 DTO
+type PriceSnapshotDTO struct {
+	Date   string         `json:"date"`
+	Prices map[string]int `json:"prices"`
+}
+
+// ValueSeriesRequest 價值時間序列 API 請求 DTO
+type ValueSeriesRequest struct {
+	Event      string             `json:"event"` // zodiac 或 starlight
+	Investment float64            `json:"investment"`
+	Method     string             `json:"method"`
+	Discount   float64            `json:"discount"`
+	Inventory  map[string]float64 `json:"inventory"` // 新年氣息已持有的氣息（生肖 -> 數量）
+	From       string             `json:"from"`      // 起始日期（含），YYYY-MM-DD
+	To         string             `json:"to"`        // 結束日期（含），YYYY-MM-DD
+}
+
+// ValueSeriesResponse 價值時間序列 API 回應 DTO
+type ValueSeriesResponse struct {
+	Event     string           `json:"event"`
+	Purchase  PurchaseDTO      `json:"purchase"`
+	DrawCount float64          `json:"draw_count"`
+	Points    []SeriesPointDTO `json:"points"`
+}
+
+// SeriesPointDTO 單一快照日期的期望價值 DTO
+type SeriesPointDTO struct {
+	Date          string  `json:"date"`
+	ExpectedValue float64 `json:"expected_value"`
+	ROI           float64 `json:"roi"`
+}
+
+// ToDomain 將 DTO 轉換為快照日期與價格
+func (r PriceSnapshotRequest) ToDomain() (time.Time, domain.PriceBook, error) {
+	date := domain.TruncateDate(time.Now())
+	if r.Date != "" {
+		parsed, err := parseDate(r.Date)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		date = parsed
+	}
+	return date, PricesRequest{Prices: r.Prices}.ToDomain(), nil
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r ValueSeriesRequest) ToUseCaseInput() (usecase.SeriesInput, error) {
+	input := usecase.SeriesInput{
+		Event:      r.Event,
+		Investment: r.Investment,
+		Method:     domain.PurchaseMethod(r.Method),
+		Discount:   r.Discount,
+		Inventory:  toBreathCollection(r.Inventory),
+	}
+	var err error
+	if r.From != "" {
+		if input.From, err = parseDate(r.From); err != nil {
+			return usecase.SeriesInput{}, err
+		}
+	}
+	if r.To != "" {
+		if input.To, err = parseDate(r.To); err != nil {
+			return usecase.SeriesInput{}, err
+		}
+	}
+	return input, nil
+}
+
+// FromPriceHistory 將價格歷史轉換為 DTO
+func FromPriceHistory(history domain.PriceHistory) PriceHistoryResponse {
+	response := PriceHistoryResponse{Snapshots: make([]PriceSnapshotDTO, len(history))}
+	for i, snapshot := range history {
+		response.Snapshots[i] = PriceSnapshotDTO{
+			Date:   snapshot.Date.Format(domain.DateLayout),
+			Prices: snapshot.Prices,
+		}
+	}
+	return response
+}
+
+// FromSeriesOutput 將 UseCase 輸出轉換為 DTO
+func FromSeriesOutput(output usecase.SeriesOutput) ValueSeriesResponse {
+	response := ValueSeriesResponse{
+		Event:     output.Event,
+		Purchase:  FromPurchase(output.Purchase),
+		DrawCount: output.DrawCount,
+		Points:    make([]SeriesPointDTO, len(output.Points)),
+	}
+	for i, point := range output.Points {
+		response.Points[i] = SeriesPointDTO{
+			Date:          point.Date.Format(domain.DateLayout),
+			ExpectedValue: point.ExpectedValue,
+			ROI:           point.ROI,
+		}
+	}
+	return response
+}
+
+// parseDate 解析 YYYY-MM-DD 格式的日期
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse(domain.DateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期 %q 格式錯誤（應為 YYYY-MM-DD）", value)
+	}
+	return date, nil
+}
@@ -0,0 +1,90 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+)
+
+// PurchaseDTO 購買結果 DTO
+type PurchaseDTO struct {
+	Method   string    `json:"method"`
+	Cards    []CardDTO `json:"cards"` // 使用的點卡，可購買任意金額時為空
+	Cost     float64   `json:"cost"`
+	Points   float64   `json:"points"`
+	Unspent  float64   `json:"unspent"`  // 投入金額中未使用的金額
+	Leftover float64   `json:"leftover"` // 超出所需點數的點數
+}
+
+// CardDTO 點卡與張數 DTO
+type CardDTO struct {
+	Price  int     `json:"price"`
+	Points float64 `json:"points"`
+	Bonus  float64 `json:"bonus"`
+	Count  int     `json:"count"`
+}
+
+// MethodDTO 購買方式 DTO
+type MethodDTO struct {
+	Method        string            `json:"method"`
+	Name          string            `json:"name"`
+	Denominations []DenominationDTO `json:"denominations"`
+	UseDiscount   bool              `json:"use_discount"`
+	BonusRate     float64           `json:"bonus_rate"`
+	Rounding      string            `json:"rounding"`
+	MaxInvestment float64           `json:"max_investment"`
+}
+
+// DenominationDTO 點卡面額 DTO
+type DenominationDTO struct {
+	Price  int     `json:"price"`
+	Points float64 `json:"points"`
+	Bonus  float64 `json:"bonus"`
+	Limit  int     `json:"limit"`
+}
+
+// FromPurchase 將購買結果轉換為 DTO
+func FromPurchase(purchase domain.Purchase) PurchaseDTO {
+	cards := make([]CardDTO, 0, len(purchase.Cards))
+	for _, card := range purchase.Cards {
+		cards = append(cards, CardDTO{
+			Price:  card.Price,
+			Points: card.Points,
+			Bonus:  card.Bonus,
+			Count:  card.Count,
+		})
+	}
+	return PurchaseDTO{
+		Method:   string(purchase.Method),
+		Cards:    cards,
+		Cost:     purchase.Cost,
+		Points:   purchase.Points,
+		Unspent:  purchase.Unspent,
+		Leftover: purchase.Leftover,
+	}
+}
+
+// FromPurchaseRegistry 將購買方式規則表轉換為 DTO（依代號排序）
+func FromPurchaseRegistry(registry domain.PurchaseRegistry) []MethodDTO {
+	methods := make([]MethodDTO, 0, len(registry))
+	for _, method := range registry.Methods() {
+		rule := registry.Rule(method)
+		denominations := make([]DenominationDTO, 0, len(rule.Denominations))
+		for _, d := range rule.Denominations {
+			denominations = append(denominations, DenominationDTO{
+				Price:  d.Price,
+				Points: d.Points,
+				Bonus:  d.Bonus,
+				Limit:  d.Limit,
+			})
+		}
+		methods = append(methods, MethodDTO{
+			Method:        string(rule.Method),
+			Name:          rule.Name,
+			Denominations: denominations,
+			UseDiscount:   rule.UseDiscount,
+			BonusRate:     rule.BonusRate,
+			Rounding:      string(rule.Rounding),
+			MaxInvestment: rule.MaxInvestment,
+		})
+	}
+	return methods
+}
@@ -0,0 +1,368 @@
+package adapter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OutputFormat 命令列輸出格式
+type OutputFormat string
+
+const (
+	FormatTable    OutputFormat = "table"    // 方框表格（預設，供閱讀）
+	FormatJSON     OutputFormat = "json"     // 與 HTTP API 回應相同結構的 JSON
+	FormatCSV      OutputFormat = "csv"      // 逗號分隔，多個表格以空行分隔
+	FormatMarkdown OutputFormat = "markdown" // Markdown 表格
+)
+
+// OutputFormats 所有輸出格式
+var OutputFormats = []OutputFormat{FormatTable, FormatJSON, FormatCSV, FormatMarkdown}
+
+// ParseOutputFormat 依名稱取得輸出格式
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	for _, format := range OutputFormats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("未知的輸出格式 %q", name)
+}
+
+// Table 表格資料，欄位名稱與 JSON 欄位名稱一致
+type Table struct {
+	Name    string
+	Columns []string
+	Rows    [][]string
+}
+
+// addRow 加入一列，數值欄位以 formatValue 轉為字串
+func (t *Table) addRow(values ...any) {
+	row := make([]string, len(values))
+	for i, v := range values {
+		row[i] = formatValue(v)
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// formatValue 將欄位值轉為字串（浮點數保留至小數第 4 位，絕對值更小的數以 4 位有效數字表示）
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case float64:
+		if v != 0 && math.Abs(v) < 1e-4 {
+			return strconv.FormatFloat(v, 'g', 4, 64)
+		}
+		return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// WriteReport 依輸出格式寫出結果：JSON 直接編碼 v，CSV 與 Markdown 寫出 tables
+func WriteReport(w io.Writer, format OutputFormat, v any, tables []Table) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatCSV:
+		return writeCSV(w, tables)
+	case FormatMarkdown:
+		return writeMarkdown(w, tables)
+	default:
+		return fmt.Errorf("輸出格式 %q 不支援寫出報告", format)
+	}
+}
+
+// writeCSV 寫出 CSV，多個表格以空行分隔
+func writeCSV(w io.Writer, tables []Table) error {
+	for i, table := range tables {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		writer := csv.NewWriter(w)
+		writer.Write(table.Columns)
+		writer.WriteAll(table.Rows)
+		if err := writer.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown 寫出 Markdown 表格，每個表格前加上標題
+func writeMarkdown(w io.Writer, tables []Table) error {
+	var b strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n\n", table.Name)
+		b.WriteString("| " + strings.Join(table.Columns, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(table.Columns)) + "\n")
+		for _, row := range table.Rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				cells[j] = strings.ReplaceAll(cell, "|", "\\|")
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// StarlightExpectedTables 星光錦囊期望值的表格（道具期望數量與摘要）
+func StarlightExpectedTables(response StarlightExpectedResponse, prices map[string]int) []Table {
+	items := Table{Name: "expected_items", Columns: []string{"item", "expected", "price", "value"}}
+	names := make([]string, 0, len(response.ExpectedItems))
+	for name := range response.ExpectedItems {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := response.ExpectedItems[names[i]], response.ExpectedItems[names[j]]
+		if a != b {
+			return a > b
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		expected := response.ExpectedItems[name]
+		items.addRow(name, expected, prices[name], expected*float64(prices[name]))
+	}
+
+	summary := Table{Name: "summary", Columns: []string{"field", "value"}}
+	summary.addRow("cost", response.Purchase.Cost)
+	summary.addRow("points", response.Points)
+	summary.addRow("draw_count", response.DrawCount)
+	summary.addRow("residual_points", response.Residual)
+	summary.addRow("cost_per_draw", response.CostPerDraw)
+	summary.addRow("expected_value", response.ExpectedValue)
+	summary.addRow("roi", response.ROI)
+	summary.addRow("std_dev", response.Risk.StdDev)
+	summary.addRow("roi_std_dev", response.Risk.ROIStdDev)
+	summary.addRow("merges", response.Merge.Merges)
+	summary.addRow("seed", response.Seed)
+
+	return []Table{items, summary, summaryTable("value_percentiles", response.Risk.Value)}
+}
+
+// StarlightSimulateTables 星光錦囊模擬的表格（第一階段數量、階梯存活與階梯獎品）
+func StarlightSimulateTables(response StarlightSimulateResponse) []Table {
+	results := Table{Name: "results", Columns: []string{"item", "count", "share"}}
+	for _, name := range sortedCounts(response.Results) {
+		share := 0.0
+		if response.DrawCount > 0 {
+			share = float64(response.Results[name]) / float64(response.DrawCount) * 100
+		}
+		results.addRow(name, response.Results[name], share)
+	}
+
+	summary := Table{Name: "summary", Columns: []string{"field", "value"}}
+	summary.addRow("draw_count", response.DrawCount)
+	summary.addRow("crystal_count", response.CrystalCount)
+	summary.addRow("leftover_crystals", response.LeftoverCrystals)
+	summary.addRow("theoretical_crystal", response.TheoreticalCrystal)
+	summary.addRow("total_cost", response.TotalCost)
+	summary.addRow("seed", response.Seed)
+
+	return append([]Table{results, summary}, ladderResultTables(response.Ladder, "")...)
+}
+
+// StarlightLadderTables 星光錦囊階梯的表格（精確分佈與各次模擬結果）
+func StarlightLadderTables(report LadderReportDTO) []Table {
+	stages := Table{Name: "stages", Columns: []string{"stage", "reach", "upgrade", "stop"}}
+	for _, stage := range report.Stages {
+		stages.addRow(stage.Stage, stage.Reach, stage.Upgrade, stage.Stop)
+	}
+
+	rewards := Table{Name: "rewards", Columns: []string{"name", "probability", "mean", "std_dev", "at_least_one"}}
+	rewards.addRow(report.FinalItem, report.Survival, report.Final.Mean, report.Final.StdDev, report.Final.AtLeastOne)
+	for _, reward := range report.Rewards {
+		rewards.addRow(reward.Name, reward.Probability, reward.Count.Mean, reward.Count.StdDev, reward.Count.AtLeastOne)
+	}
+
+	tables := []Table{stages, rewards}
+	for i, simulation := range report.Simulations {
+		tables = append(tables, ladderResultTables(simulation, fmt.Sprintf("_%d", i+1))...)
+	}
+	return tables
+}
+
+// ladderResultTables 階梯模擬結果的表格（各階段存活與獎品數量），suffix 附加於表格名稱
+func ladderResultTables(ladder LadderResponse, suffix string) []Table {
+	survival := Table{Name: "ladder" + suffix, Columns: []string{"stage", "entered", "failures", "survival_rate"}}
+	for i, stage := range ladder.Stages {
+		// 最終階段沒有升級道具，停留即為成功抵達
+		failures := stage.Stops
+		if i == len(ladder.Stages)-1 {
+			failures = 0
+		}
+		survival.addRow(stage.Stage, stage.Entered, failures, rate(stage.Entered-failures, ladder.InitialCount))
+	}
+
+	rewards := Table{Name: "ladder_rewards" + suffix, Columns: []string{"item", "count"}}
+	for _, name := range sortedCounts(ladder.Rewards) {
+		rewards.addRow(name, ladder.Rewards[name])
+	}
+	return []Table{survival, rewards}
+}
+
+// ZodiacSimulateTables 新年氣息模擬的表格（心願箱數量分佈與總價值、報酬率百分位數）
+func ZodiacSimulateTables(response ZodiacSimulateResponse, boxTypes []string) []Table {
+	boxes := Table{Name: "boxes", Columns: []string{"box", "mean", "median", "p5", "p95", "at_least_one"}}
+	for _, boxType := range boxTypes {
+		summary := response.Boxes.Boxes[boxType]
+		boxes.addRow(boxType, summary.Mean, summary.Median, summary.Percentiles["p5"], summary.Percentiles["p95"], response.Boxes.AtLeastOne[boxType])
+	}
+
+	summary := Table{Name: "summary", Columns: []string{"field", "value"}}
+	summary.addRow("points", response.Points)
+	summary.addRow("cost", response.Purchase.Cost)
+	summary.addRow("draw_count", response.Boxes.DrawCount)
+	summary.addRow("residual_points", response.Residual)
+	summary.addRow("trials", response.Boxes.Trials)
+	summary.addRow("profit_probability", response.ProfitProbability)
+	summary.addRow("seed", response.Seed)
+
+	return []Table{boxes, summary, summaryTable("value", response.Value), summaryTable("roi", response.ROI)}
+}
+
+// LuckTables 運氣百分位的表格
+func LuckTables(response LuckResponse) []Table {
+	summary := Table{Name: "summary", Columns: []string{"field", "value"}}
+	summary.addRow("event", response.Event)
+	summary.addRow("points", response.Points)
+	summary.addRow("cost", response.Purchase.Cost)
+	summary.addRow("draw_count", response.DrawCount)
+	summary.addRow("realized_value", response.Realized)
+	summary.addRow("realized_roi", response.RealizedROI)
+	summary.addRow("percentile", response.Percentile)
+	summary.addRow("better", response.Better)
+	summary.addRow("worse", response.Worse)
+	summary.addRow("trials", response.Trials)
+	summary.addRow("seed", response.Seed)
+
+	return []Table{summary, summaryTable("value", response.Value), summaryTable("roi", response.ROI)}
+}
+
+// PortfolioTables 預算分配的表格
+func PortfolioTables(response PortfolioResponse) []Table {
+	allocations := Table{Name: "allocations", Columns: []string{"event", "investment", "cost", "points", "expected_value", "std_dev"}}
+	for _, allocation := range response.Allocations {
+		allocations.addRow(allocation.Event, allocation.Investment, allocation.Cost, allocation.Points, allocation.ExpectedValue, allocation.StdDev)
+	}
+
+	summary := Table{Name: "summary", Columns: []string{"field", "value"}}
+	summary.addRow("unspent", response.Unspent)
+	summary.addRow("expected_value", response.ExpectedValue)
+	summary.addRow("std_dev", response.StdDev)
+	summary.addRow("objective", response.Objective)
+	summary.addRow("roi", response.ROI)
+	summary.addRow("step", response.Step)
+	summary.addRow("candidates", response.Candidates)
+	summary.addRow("seed", response.Seed)
+
+	return []Table{allocations, summary}
+}
+
+// RateTestTables 公告機率檢定的表格（各獎池的檢定摘要與各道具偏差）
+func RateTestTables(response RateTestResponse) []Table {
+	tests := Table{Name: "tests", Columns: []string{"pool", "name", "sessions", "total", "chi_square", "degrees_of_freedom", "chi_square_p_value", "low_expected", "exact_p_value", "exact_method", "exact_trials"}}
+	items := Table{Name: "items", Columns: []string{"pool", "item", "probability", "observed", "expected", "observed_rate", "deviation", "residual", "p_value"}}
+	for _, test := range response.Tests {
+		tests.addRow(test.Pool, test.Name, test.Sessions, test.Total, test.ChiSquare, test.DegreesOfFreedom, test.ChiSquarePValue, test.LowExpected, test.ExactPValue, test.ExactMethod, test.ExactTrials)
+		for _, item := range test.Items {
+			items.addRow(test.Pool, item.Item, item.Probability, item.Observed, item.Expected, item.ObservedRate, item.Deviation, item.Residual, item.PValue)
+		}
+	}
+	return []Table{tests, items}
+}
+
+// RateEstimateTables 事後機率估計的表格
+func RateEstimateTables(response RateEstimateResponse) []Table {
+	posteriors := Table{Name: "posteriors", Columns: []string{"pool", "name", "sessions", "total", "prior_weight", "credible_level"}}
+	items := Table{Name: "items", Columns: []string{"pool", "item", "prior", "observed", "alpha", "mean", "std_dev", "lower", "upper"}}
+	for _, posterior := range response.Posteriors {
+		posteriors.addRow(posterior.Pool, posterior.Name, posterior.Sessions, posterior.Total, posterior.PriorWeight, posterior.Level)
+		for _, item := range posterior.Items {
+			items.addRow(posterior.Pool, item.Item, item.Prior, item.Observed, item.Alpha, item.Mean, item.StdDev, item.Lower, item.Upper)
+		}
+	}
+	return []Table{posteriors, items}
+}
+
+// GachaTables 多階段抽獎模型的表格（獎池抵達機率、最終道具精確分佈、道具數量與總價值百分位數）
+func GachaTables(response GachaResponse) []Table {
+	reach := Table{Name: "reach", Columns: []string{"pool", "name", "reach"}}
+	for _, pool := range response.Reach {
+		reach.addRow(pool.Pool, pool.Name, pool.Reach)
+	}
+
+	outcomes := Table{Name: "outcomes", Columns: []string{"name", "probability", "mean", "std_dev", "at_least_one"}}
+	for _, outcome := range response.Outcomes {
+		outcomes.addRow(outcome.Name, outcome.Probability, outcome.Count.Mean, outcome.Count.StdDev, outcome.Count.AtLeastOne)
+	}
+
+	items := Table{Name: "items", Columns: []string{"name", "expected", "mean", "median", "p5", "p95"}}
+	for _, item := range response.Items {
+		items.addRow(item.Name, item.Expected, item.Count.Mean, item.Count.Median, item.Count.Percentiles["p5"], item.Count.Percentiles["p95"])
+	}
+	return []Table{reach, outcomes, items, summaryTable("value", response.Value)}
+}
+
+// summaryTable 統計摘要的表格（平均、標準差與百分位數）
+func summaryTable(name string, summary SummaryDTO) Table {
+	table := Table{Name: name, Columns: []string{"statistic", "value"}}
+	table.addRow("mean", summary.Mean)
+	table.addRow("std_dev", summary.StdDev)
+	table.addRow("min", summary.Min)
+	table.addRow("median", summary.Median)
+	table.addRow("max", summary.Max)
+
+	keys := make([]string, 0, len(summary.Percentiles))
+	for key := range summary.Percentiles {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(keys[i], "p"))
+		b, _ := strconv.Atoi(strings.TrimPrefix(keys[j], "p"))
+		return a < b
+	})
+	for _, key := range keys {
+		table.addRow(key, summary.Percentiles[key])
+	}
+	return table
+}
+
+// sortedCounts 依數量由多到少排序道具名稱（數量相同時依名稱）
+func sortedCounts(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// rate 計算比例 (%)
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
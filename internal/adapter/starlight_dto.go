@@ -0,0 +1,156 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+)
+
+// StarlightExpectedRequest 星光錦囊期望值 API 請求 DTO
+type StarlightExpectedRequest struct {
+	Investment float64        `json:"investment"`
+	Method     string         `json:"method"`
+	Discount   float64        `json:"discount"`
+	Prices     map[string]int `json:"prices"`
+	Trials     int            `json:"trials"` // 估計百分位數的模擬次數，0 表示使用預設值
+	Seed       *int64         `json:"seed"`   // 亂數種子，未指定時隨機產生
+	// 玲瓏星光以整數合成，剩餘的玲瓏星光以 prices 中的價格計價
+	IntegerMerge bool `json:"integer_merge"`
+	// 以整數抽數計算，不足一抽的點數回報為 residual_points
+	IntegerDraws bool    `json:"integer_draws"`
+	CarryPoints  float64 `json:"carry_points"` // 前次購買或其他活動結轉的點數
+}
+
+// StarlightExpectedResponse 星光錦囊期望值 API 回應 DTO
+type StarlightExpectedResponse struct {
+	Points        float64            `json:"points"`
+	Purchase      PurchaseDTO        `json:"purchase"`
+	DrawCount     float64            `json:"draw_count"`
+	Residual      float64            `json:"residual_points"`
+	CostPerDraw   float64            `json:"cost_per_draw"`
+	ExpectedItems map[string]float64 `json:"expected_items"`
+	ExpectedValue float64            `json:"expected_value"`
+	ROI           float64            `json:"roi"`
+	Merge         MergeDTO           `json:"merge"`
+	Risk          RiskDTO            `json:"risk"`
+	Seed          int64              `json:"seed"`
+}
+
+// MergeDTO 玲瓏星光合成結果 DTO
+type MergeDTO struct {
+	Integer  bool    `json:"integer"`
+	Crystals float64 `json:"crystals"`
+	Merges   float64 `json:"merges"`
+	Leftover float64 `json:"leftover"`
+}
+
+// StarlightSimulateRequest 星光錦囊模擬 API 請求 DTO
+type StarlightSimulateRequest struct {
+	DrawCount   int    `json:"draw_count"`
+	LadderCount int    `json:"ladder_count"` // 星光結晶體數量，0 表示以模擬所得玲瓏星光 ÷ 4 計算
+	Seed        *int64 `json:"seed"`         // 亂數種子，未指定時隨機產生
+}
+
+// StarlightSimulateResponse 星光錦囊模擬 API 回應 DTO
+type StarlightSimulateResponse struct {
+	DrawCount          int            `json:"draw_count"`
+	Results            map[string]int `json:"results"`
+	CrystalCount       int            `json:"crystal_count"`
+	LeftoverCrystals   int            `json:"leftover_crystals"` // 合成後剩餘的玲瓏星光（指定 ladder_count 時為 0）
+	TheoreticalCrystal float64        `json:"theoretical_crystal"`
+	TotalCost          int            `json:"total_cost"`
+	Ladder             LadderResponse `json:"ladder"`
+	Seed               int64          `json:"seed"`
+}
+
+// LadderResponse 階梯模擬結果 DTO
+type LadderResponse struct {
+	InitialCount        int                    `json:"initial_count"`
+	Stages              []LadderStageResultDTO `json:"stages"`
+	Success             int                    `json:"success"` // 成功抵達最終階段的次數
+	Rewards             map[string]int         `json:"rewards"`
+	SurvivalRate        float64                `json:"survival_rate"`
+	TheoreticalSurvival float64                `json:"theoretical_survival"`
+}
+
+// LadderStageResultDTO 階梯模擬單一階段結果 DTO
+type LadderStageResultDTO struct {
+	Stage   int    `json:"stage"`
+	Item    string `json:"item"`
+	Entered int    `json:"entered"`
+	Stops   int    `json:"stops"` // 停留在此階段的次數（最終階段即為成功抵達）
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r StarlightExpectedRequest) ToUseCaseInput() usecase.StarlightExpectedInput {
+	return usecase.StarlightExpectedInput{
+		Investment:   r.Investment,
+		Method:       domain.PurchaseMethod(r.Method),
+		Discount:     r.Discount,
+		Prices:       r.Prices,
+		Trials:       r.Trials,
+		Integer:      r.IntegerMerge,
+		IntegerDraws: r.IntegerDraws,
+		CarryPoints:  r.CarryPoints,
+	}
+}
+
+// FromStarlightExpectedOutput 將 UseCase 輸出轉換為 DTO
+func FromStarlightExpectedOutput(output usecase.StarlightExpectedOutput) StarlightExpectedResponse {
+	// 轉換 []ExpandedItem 為 map[string]float64
+	items := make(map[string]float64)
+	for _, item := range output.ExpectedItems {
+		items[item.Name] = item.Expected
+	}
+
+	return StarlightExpectedResponse{
+		Points:        output.Points,
+		Purchase:      FromPurchase(output.Purchase),
+		DrawCount:     output.DrawCount,
+		Residual:      output.Residual,
+		CostPerDraw:   output.CostPerDraw,
+		ExpectedItems: items,
+		ExpectedValue: output.ExpectedValue,
+		ROI:           output.ROI,
+		Merge: MergeDTO{
+			Integer:  output.Merge.Integer,
+			Crystals: output.Merge.Crystals,
+			Merges:   output.Merge.Merges,
+			Leftover: output.Merge.Leftover,
+		},
+		Risk: FromRiskReport(output.Risk),
+		Seed: output.Seed,
+	}
+}
+
+// FromSimulationResult 將模擬結果轉換為 DTO
+func FromSimulationResult(sim domain.SimulationResult, ladder domain.LadderResult, survivalRate, theoreticalSurvival float64) StarlightSimulateResponse {
+	return StarlightSimulateResponse{
+		DrawCount:          sim.DrawCount,
+		Results:            sim.Results,
+		CrystalCount:       sim.CrystalCount,
+		TheoreticalCrystal: sim.TheoreticalEV,
+		TotalCost:          sim.TotalCost,
+		Ladder:             FromLadderResult(ladder, survivalRate, theoreticalSurvival),
+	}
+}
+
+// FromLadderResult 將階梯模擬結果轉換為 DTO
+func FromLadderResult(ladder domain.LadderResult, survivalRate, theoreticalSurvival float64) LadderResponse {
+	stages := make([]LadderStageResultDTO, 0, len(ladder.Stages))
+	for _, stage := range ladder.Stages {
+		stages = append(stages, LadderStageResultDTO{
+			Stage:   stage.Stage,
+			Item:    stage.Item,
+			Entered: stage.Entered,
+			Stops:   stage.Stops,
+		})
+	}
+	return LadderResponse{
+		InitialCount:        ladder.InitialCount,
+		Stages:              stages,
+		Success:             ladder.Success(),
+		Rewards:             ladder.Rewards,
+		SurvivalRate:        survivalRate,
+		TheoreticalSurvival: theoreticalSurvival,
+	}
+}
@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/usecase"
+)
+
+// ZodiacTargetRequest 新年氣息目標反推 API 請求 DTO
+type ZodiacTargetRequest struct {
+	Method     string             `json:"method"`
+	Discount   float64            `json:"discount"`
+	BoxType    string             `json:"box_type"`   // 目標心願箱（小吉、中吉、大吉、超越）
+	Count      int                `json:"count"`      // 目標數量
+	Confidence float64            `json:"confidence"` // 信心水準 (%)
+	Inventory  map[string]float64 `json:"inventory"`
+	Trials     int                `json:"trials"`
+	MaxDraws   int                `json:"max_draws"`
+	Seed       *int64             `json:"seed"`
+}
+
+// StarlightTargetRequest 星光錦囊目標反推 API 請求 DTO
+type StarlightTargetRequest struct {
+	Method     string  `json:"method"`
+	Discount   float64 `json:"discount"`
+	Item       string  `json:"item"`       // 目標道具
+	Count      int     `json:"count"`      // 目標數量
+	Confidence float64 `json:"confidence"` // 信心水準 (%)
+	Trials     int     `json:"trials"`
+	MaxDraws   int     `json:"max_draws"`
+	Seed       *int64  `json:"seed"`
+}
+
+// TargetResponse 目標反推 API 回應 DTO
+type TargetResponse struct {
+	Reached     bool        `json:"reached"`
+	DrawCount   int         `json:"draw_count"`
+	Points      float64     `json:"points"`
+	Investment  float64     `json:"investment"`
+	Purchase    PurchaseDTO `json:"purchase"`
+	Probability float64     `json:"probability"`
+	MeanDraws   float64     `json:"mean_draws"`
+	Trials      int         `json:"trials"`
+	Seed        int64       `json:"seed"`
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r ZodiacTargetRequest) ToUseCaseInput() usecase.ZodiacTargetInput {
+	return usecase.ZodiacTargetInput{
+		Method:     domain.PurchaseMethod(r.Method),
+		Discount:   r.Discount,
+		BoxType:    domain.BoxType(r.BoxType),
+		Count:      r.Count,
+		Confidence: r.Confidence,
+		Inventory:  toBreathCollection(r.Inventory),
+		Trials:     r.Trials,
+		MaxDraws:   r.MaxDraws,
+	}
+}
+
+// ToUseCaseInput 將 DTO 轉換為 UseCase 輸入
+func (r StarlightTargetRequest) ToUseCaseInput() usecase.StarlightTargetInput {
+	return usecase.StarlightTargetInput{
+		Method:     domain.PurchaseMethod(r.Method),
+		Discount:   r.Discount,
+		Item:       r.Item,
+		Count:      r.Count,
+		Confidence: r.Confidence,
+		Trials:     r.Trials,
+		MaxDraws:   r.MaxDraws,
+	}
+}
+
+// FromTargetOutput 將 UseCase 輸出轉換為 DTO
+func FromTargetOutput(output usecase.TargetOutput) TargetResponse {
+	return TargetResponse{
+		Reached:     output.Reached,
+		DrawCount:   output.DrawCount,
+		Points:      output.Points,
+		Investment:  output.Investment,
+		Purchase:    FromPurchase(output.Purchase),
+		Probability: output.Probability,
+		MeanDraws:   output.MeanDraws,
+		Trials:      output.Trials,
+		Seed:        output.Seed,
+	}
+}
@@ -10,17 +10,6 @@ const (
 	BoxSuper  BoxType = "超越"
 )
 
-// BoxRequirements 心願箱所需生肖（需各1個湊齊）
-var BoxRequirements = map[BoxType][]Zodiac{
-	BoxSmall:  {Rabbit, Dragon, Snake},
-	BoxMedium: {Rabbit, Dragon, Snake, Tiger, Ox, Rat},
-	BoxLarge:  {Rabbit, Dragon, Snake, Tiger, Ox, Rat, Pig, Dog, Rooster},
-	BoxSuper:  {Rabbit, Dragon, Snake, Tiger, Ox, Rat, Pig, Dog, Rooster, Monkey, Goat, Horse},
-}
-
-// BoxPriority 心願箱優先順序（高價值優先）
-var BoxPriority = []BoxType{BoxSuper, BoxLarge, BoxMedium, BoxSmall}
-
 // BoxValues 心願箱價值
 type BoxValues struct {
 	Small  float64
@@ -58,6 +47,15 @@ func NewBoxCollection() BoxCollection {
 	}
 }
 
+// Diff 計算與另一份心願箱收集的差異（bc - other）
+func (bc BoxCollection) Diff(other BoxCollection) BoxCollection {
+	diff := NewBoxCollection()
+	for boxType := range diff {
+		diff[boxType] = bc[boxType] - other[boxType]
+	}
+	return diff
+}
+
 // TotalValue 計算總價值
 func (bc BoxCollection) TotalValue(values BoxValues) float64 {
 	return bc[BoxSmall]*values.Small +
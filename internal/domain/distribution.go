@@ -0,0 +1,96 @@
+package domain
+
+import "math"
+
+// massEpsilon 機率質量低於此值的尾端不列出
+const massEpsilon = 1e-12
+
+// CountMass 數量的機率質量
+type CountMass struct {
+	Count       int
+	Probability float64 // P(X = Count) (%)
+	Cumulative  float64 // P(X ≤ Count) (%)
+}
+
+// CountDistribution 數量的精確機率分佈
+type CountDistribution struct {
+	Trials      int
+	Mean        float64
+	Variance    float64
+	StdDev      float64
+	AtLeastOne  float64     // P(X ≥ 1) (%)
+	Percentiles map[int]int // 百分位數 -> 累積機率達到該百分位的最小數量
+	Mass        []CountMass // 機率質量（省略低於 1e-12 的尾端）
+}
+
+// Binomial 計算二項分佈 B(n, p) 的精確機率分佈，p 為單次成功機率（0~1）
+func Binomial(n int, p float64) CountDistribution {
+	p = math.Min(math.Max(p, 0), 1)
+	dist := CountDistribution{
+		Trials:      n,
+		Mean:        float64(n) * p,
+		Variance:    float64(n) * p * (1 - p),
+		Percentiles: make(map[int]int),
+	}
+	dist.StdDev = math.Sqrt(dist.Variance)
+	if n <= 0 {
+		dist.Mass = []CountMass{{Count: 0, Probability: 100, Cumulative: 100}}
+		return dist
+	}
+	dist.AtLeastOne = (1 - math.Pow(1-p, float64(n))) * 100
+
+	// 1. 由眾數往兩側展開，直到機率質量低於門檻
+	mode := min(int(math.Floor(float64(n+1)*p)), n)
+	lo, hi := mode, mode
+	for lo > 0 && binomialPMF(n, lo-1, p) >= massEpsilon {
+		lo--
+	}
+	for hi < n && binomialPMF(n, hi+1, p) >= massEpsilon {
+		hi++
+	}
+
+	// 2. 計算機率質量與累積機率
+	var cumulative float64
+	for k := lo; k <= hi; k++ {
+		mass := binomialPMF(n, k, p)
+		cumulative += mass
+		dist.Mass = append(dist.Mass, CountMass{
+			Count:       k,
+			Probability: mass * 100,
+			Cumulative:  math.Min(cumulative, 1) * 100,
+		})
+	}
+
+	// 3. 百分位數
+	for _, percentile := range SummaryPercentiles {
+		dist.Percentiles[percentile] = hi
+		for _, m := range dist.Mass {
+			if m.Cumulative >= float64(percentile) {
+				dist.Percentiles[percentile] = m.Count
+				break
+			}
+		}
+	}
+
+	return dist
+}
+
+// binomialPMF 計算 P(X = k)，X ~ B(n, p)（以對數計算避免溢位）
+func binomialPMF(n, k int, p float64) float64 {
+	switch {
+	case p == 0:
+		if k == 0 {
+			return 1
+		}
+		return 0
+	case p == 1:
+		if k == n {
+			return 1
+		}
+		return 0
+	}
+	lgN, _ := math.Lgamma(float64(n + 1))
+	lgK, _ := math.Lgamma(float64(k + 1))
+	lgNK, _ := math.Lgamma(float64(n - k + 1))
+	return math.Exp(lgN - lgK - lgNK + float64(k)*math.Log(p) + float64(n-k)*math.Log1p(-p))
+}
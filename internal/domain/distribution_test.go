@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBinomial(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		p    float64
+		mass []float64 // P(X = 0..n) (%)
+	}{
+		{"公平硬幣 4 次", 4, 0.5, []float64{6.25, 25, 37.5, 25, 6.25}},
+		{"不對稱機率", 2, 0.1, []float64{81, 18, 1}},
+		{"必定成功", 3, 1, []float64{0, 0, 0, 100}},
+		{"不抽", 0, 0.3, []float64{100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dist := Binomial(tt.n, tt.p)
+			if math.Abs(dist.Mean-float64(tt.n)*tt.p) > 1e-12 {
+				t.Errorf("Mean = %g, want %g", dist.Mean, float64(tt.n)*tt.p)
+			}
+			if want := float64(tt.n) * tt.p * (1 - tt.p); math.Abs(dist.Variance-want) > 1e-12 {
+				t.Errorf("Variance = %g, want %g", dist.Variance, want)
+			}
+
+			got := make(map[int]float64)
+			for _, m := range dist.Mass {
+				got[m.Count] = m.Probability
+			}
+			for k, want := range tt.mass {
+				if math.Abs(got[k]-want) > 1e-9 {
+					t.Errorf("P(X = %d) = %g%%, want %g%%", k, got[k], want)
+				}
+			}
+			if last := dist.Mass[len(dist.Mass)-1]; math.Abs(last.Cumulative-100) > 1e-9 {
+				t.Errorf("累積機率 = %g%%, want 100%%", last.Cumulative)
+			}
+		})
+	}
+}
+
+func TestBinomialAtLeastOneAndPercentiles(t *testing.T) {
+	dist := Binomial(10, 0.2)
+	if want := (1 - math.Pow(0.8, 10)) * 100; math.Abs(dist.AtLeastOne-want) > 1e-9 {
+		t.Errorf("AtLeastOne = %g, want %g", dist.AtLeastOne, want)
+	}
+	// 中位數為累積機率達到 50% 的最小數量：P(X ≤ 1) ≈ 37.6%、P(X ≤ 2) ≈ 67.8%
+	if got := dist.Percentiles[50]; got != 2 {
+		t.Errorf("Percentiles[50] = %d, want 2", got)
+	}
+}
+
+func TestBinomialLargeTrialsTruncatesTails(t *testing.T) {
+	dist := Binomial(1000000, 0.01)
+	if len(dist.Mass) >= 1000000 {
+		t.Fatalf("尾端未省略，共 %d 個機率質量", len(dist.Mass))
+	}
+	var total float64
+	for _, m := range dist.Mass {
+		total += m.Probability
+	}
+	if math.Abs(total-100) > 1e-6 {
+		t.Errorf("機率質量總和 = %g%%, want 100%%", total)
+	}
+}
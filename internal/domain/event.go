@@ -0,0 +1,93 @@
+package domain
+
+import "sort"
+
+// EventDefinition 活動定義（獎池、機率與合成規則）
+type EventDefinition struct {
+	Version   string // 資料版本
+	Name      string // 活動名稱
+	Zodiac    ZodiacEvent
+	Starlight StarlightEvent
+}
+
+// ZodiacEvent 新年氣息活動定義
+type ZodiacEvent struct {
+	CostPerDraw     float64              // 每抽成本（點數）
+	Rates           map[Zodiac]float64   // 生肖機率 (%)
+	BoxRequirements map[BoxType][]Zodiac // 心願箱所需生肖（需各1個湊齊）
+	BoxPriority     []BoxType            // 心願箱優先順序（高價值優先）
+}
+
+// StarlightEvent 星光錦囊活動定義
+type StarlightEvent struct {
+	CostPerDraw      int              // 每抽成本（點數）
+	CrystalItem      string           // 進入階梯的道具（玲瓏星光）
+	CrystalsPerMerge int              // 合成一個星光結晶體所需數量
+	MergedItem       string           // 合成所得、開啟第2階段的道具（星光結晶體）
+	Stage1Pool       []Reward         // 第一階段（星光錦囊）獎池
+	StagePools       map[int][]Reward // 階梯式獎池（第2階段起）
+	UpgradeItems     map[int]string   // 各階段的升級道具名稱
+	ValuableItems    []string         // 需要輸入價值的道具
+	ZeroValueItems   []string         // 價值為0的道具
+}
+
+// Probability 取得指定道具在獎池中的機率 (%)
+func Probability(pool []Reward, name string) float64 {
+	var total float64
+	for _, reward := range pool {
+		if reward.Name == name {
+			total += reward.Probability
+		}
+	}
+	return total
+}
+
+// Stages 取得階梯式獎池的階段編號（由小到大）
+func (e StarlightEvent) Stages() []int {
+	stages := make([]int, 0, len(e.StagePools))
+	for stage := range e.StagePools {
+		stages = append(stages, stage)
+	}
+	sort.Ints(stages)
+	return stages
+}
+
+// StageItem 取得開啟指定階段所需的道具名稱
+// 第一個階梯階段為合成所得的道具，其後為前一階段的升級道具
+func (e StarlightEvent) StageItem(stage int) string {
+	stages := e.Stages()
+	if len(stages) > 0 && stage == stages[0] {
+		return e.MergedItem
+	}
+	return e.UpgradeItems[stage-1]
+}
+
+// Pool 以獎池形式表示生肖機率（依 Zodiacs 順序）
+func (e ZodiacEvent) Pool() []Reward {
+	pool := make([]Reward, 0, len(e.Rates))
+	for _, zodiac := range e.Zodiacs() {
+		pool = append(pool, Reward{Name: string(zodiac), Probability: e.Rates[zodiac]})
+	}
+	return pool
+}
+
+// Zodiacs 取得所有有設定機率的生肖（依 AllZodiacs 順序，未知生肖排在最後）
+func (e ZodiacEvent) Zodiacs() []Zodiac {
+	var zodiacs []Zodiac
+	known := make(map[Zodiac]bool)
+	for _, zodiac := range AllZodiacs {
+		known[zodiac] = true
+		if _, ok := e.Rates[zodiac]; ok {
+			zodiacs = append(zodiacs, zodiac)
+		}
+	}
+
+	var unknown []Zodiac
+	for zodiac := range e.Rates {
+		if !known[zodiac] {
+			unknown = append(unknown, zodiac)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+	return append(zodiacs, unknown...)
+}
@@ -0,0 +1,356 @@
+package domain
+
+import "sort"
+
+// GachaPool 多階段抽獎模型中的獎池節點
+type GachaPool struct {
+	ID      string // 獎池代號
+	Name    string // 顯示名稱
+	Rewards []Reward
+}
+
+// Recipe 合成配方：消耗 Inputs 中各道具指定數量，合成一個 Output
+type Recipe struct {
+	Inputs map[string]int // 道具名稱 -> 所需數量
+	Output string         // 合成所得道具
+}
+
+// Assembler 自訂合成步驟：依道具價格決定如何消耗 items 中的原料並加入產物（直接修改 items）
+type Assembler func(items map[string]int, prices map[string]int)
+
+// GachaModel 多階段抽獎模型
+//
+// 獎池為節點，Opens 為邊：取得道具（抽到的升級道具或合成所得道具）時立即消耗並開啟對應獎池，
+// 直到抽到不開啟任何獎池的最終道具為止。每次抽取從 Entry 開始，抽完後依 Recipes 順序合成；
+// 指定 Assemble 時改以 Assemble 合成（其產物不會開啟獎池），Recipes 僅用於驗證與列出產物。
+type GachaModel struct {
+	Name        string
+	Entry       string            // 每次抽取開啟的獎池代號
+	CostPerDraw float64           // 每抽成本（點數）
+	Pools       []GachaPool       // 所有獎池（依開啟順序）
+	Opens       map[string]string // 道具名稱 -> 取得時開啟的獎池代號
+	Recipes     []Recipe          // 合成配方（依優先順序）
+	Assemble    Assembler         // 自訂合成步驟，nil 表示依 Recipes 順序盡量合成
+}
+
+// Model 將星光錦囊表示為多階段抽獎模型：
+// 第一階段為入口，每 CrystalsPerMerge 個玲瓏星光合成星光結晶體並開啟第一個階梯階段，各階段的升級道具開啟下一階段
+func (e StarlightEvent) Model() GachaModel {
+	model := GachaModel{
+		Name:        "星光錦囊",
+		Entry:       PoolStage1,
+		CostPerDraw: float64(e.CostPerDraw),
+		Pools:       []GachaPool{{ID: PoolStage1, Name: StageName(1), Rewards: e.Stage1Pool}},
+		Opens:       make(map[string]string),
+	}
+
+	stages := e.Stages()
+	for _, stage := range stages {
+		model.Pools = append(model.Pools, GachaPool{ID: StagePoolID(stage), Name: StageName(stage), Rewards: e.StagePools[stage]})
+		if upgrade, ok := e.UpgradeItems[stage]; ok {
+			if _, hasNext := e.StagePools[stage+1]; hasNext {
+				model.Opens[upgrade] = StagePoolID(stage + 1)
+			}
+		}
+	}
+	if len(stages) > 0 && e.MergedItem != "" {
+		model.Opens[e.MergedItem] = StagePoolID(stages[0])
+		model.Recipes = append(model.Recipes, Recipe{
+			Inputs: map[string]int{e.CrystalItem: e.CrystalsPerMerge},
+			Output: e.MergedItem,
+		})
+	}
+	return model
+}
+
+// Model 將新年氣息表示為多階段抽獎模型：單一生肖獎池，心願箱為依優先順序合成的配方（所需生肖各 1 個）
+// 依優先順序湊箱不一定是總價值最高的組合，模擬時應另以 Assemble 指定依心願箱價值的最佳湊箱方式
+func (e ZodiacEvent) Model() GachaModel {
+	model := GachaModel{
+		Name:        "新年氣息",
+		Entry:       PoolZodiac,
+		CostPerDraw: e.CostPerDraw,
+		Pools:       []GachaPool{{ID: PoolZodiac, Name: "新年氣息", Rewards: e.Pool()}},
+	}
+	for _, boxType := range e.BoxPriority {
+		inputs := make(map[string]int)
+		for _, zodiac := range e.BoxRequirements[boxType] {
+			inputs[string(zodiac)]++
+		}
+		model.Recipes = append(model.Recipes, Recipe{Inputs: inputs, Output: string(boxType)})
+	}
+	return model
+}
+
+// Pool 依代號取得獎池
+func (m GachaModel) Pool(id string) (GachaPool, bool) {
+	for _, pool := range m.Pools {
+		if pool.ID == id {
+			return pool, true
+		}
+	}
+	return GachaPool{}, false
+}
+
+// Validate 驗證模型：各獎池機率、入口與開啟的獎池存在、開啟關係不可循環、配方數量
+func (m GachaModel) Validate() ValidationReport {
+	var report ValidationReport
+
+	ids := make(map[string]bool)
+	for _, pool := range m.Pools {
+		if ids[pool.ID] {
+			report.addIssue(SeverityError, pool.Name, "", "獎池代號 %q 重複", pool.ID)
+		}
+		ids[pool.ID] = true
+		report.merge(ValidatePool(pool.Name, pool.Rewards))
+	}
+	if m.CostPerDraw <= 0 {
+		report.addIssue(SeverityError, m.Name, "", "每抽成本必須大於 0")
+	}
+	if !ids[m.Entry] {
+		report.addIssue(SeverityError, m.Name, "", "入口獎池 %q 不存在", m.Entry)
+	}
+	for _, item := range sortedKeys(m.Opens) {
+		if !ids[m.Opens[item]] {
+			report.addIssue(SeverityError, m.Name, item, "開啟的獎池 %q 不存在", m.Opens[item])
+		}
+	}
+
+	// 開啟關係不可循環（否則無法停在最終道具）
+	openCycle := false
+	for _, pool := range m.Pools {
+		if m.hasCycle(pool.ID, make(map[string]bool)) {
+			report.addIssue(SeverityError, pool.Name, "", "開啟關係形成循環")
+			openCycle = true
+			break
+		}
+	}
+
+	for i, recipe := range m.Recipes {
+		if recipe.Output == "" || len(recipe.Inputs) == 0 {
+			report.addIssue(SeverityError, m.Name, recipe.Output, "第 %d 個配方缺少原料或產物", i+1)
+		}
+		if _, self := recipe.Inputs[recipe.Output]; self {
+			report.addIssue(SeverityError, m.Name, recipe.Output, "第 %d 個配方的產物同時為其原料", i+1)
+		}
+		for _, input := range sortedKeys(recipe.Inputs) {
+			if recipe.Inputs[input] <= 0 {
+				report.addIssue(SeverityError, m.Name, input, "配方所需數量必須大於 0")
+			}
+		}
+	}
+
+	// 合成關係不可循環（否則合成永遠不會停止）；開啟關係循環時無法展開產物，略過此檢查
+	if !openCycle {
+		edges := m.recipeEdges()
+		done := make(map[string]bool)
+		for _, item := range sortedKeys(edges) {
+			if hasRecipeCycle(item, edges, make(map[string]bool), done) {
+				report.addIssue(SeverityError, m.Name, item, "合成配方形成循環")
+				break
+			}
+		}
+	}
+	return report
+}
+
+// recipeEdges 合成關係：原料 -> 合成後可能取得的道具（產物會開啟獎池時為該獎池可能抽到的最終道具）
+func (m GachaModel) recipeEdges() map[string][]string {
+	edges := make(map[string][]string)
+	for _, recipe := range m.Recipes {
+		products := []string{recipe.Output}
+		if pool, opens := m.Opens[recipe.Output]; opens {
+			products = nil
+			outcomes := m.Outcomes(pool)
+			for _, name := range sortedKeys(outcomes) {
+				if outcomes[name] > 0 {
+					products = append(products, name)
+				}
+			}
+		}
+		for _, input := range sortedKeys(recipe.Inputs) {
+			edges[input] = append(edges[input], products...)
+		}
+	}
+	return edges
+}
+
+// hasRecipeCycle 檢查從指定道具出發的合成關係是否形成循環，done 記錄已確認不在循環上的道具
+func hasRecipeCycle(item string, edges map[string][]string, visiting, done map[string]bool) bool {
+	if visiting[item] {
+		return true
+	}
+	if done[item] {
+		return false
+	}
+	visiting[item] = true
+	for _, next := range edges[item] {
+		if hasRecipeCycle(next, edges, visiting, done) {
+			return true
+		}
+	}
+	delete(visiting, item)
+	done[item] = true
+	return false
+}
+
+// Items 模型中所有可能留存的道具：各獎池中不開啟其他獎池的道具與不開啟獎池的合成產物（依出現順序、不重複）
+func (m GachaModel) Items() []string {
+	var items []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if _, opens := m.Opens[name]; !opens && !seen[name] {
+			seen[name] = true
+			items = append(items, name)
+		}
+	}
+	for _, pool := range m.Pools {
+		for _, reward := range pool.Rewards {
+			add(reward.Name)
+		}
+	}
+	for _, recipe := range m.Recipes {
+		add(recipe.Output)
+	}
+	return items
+}
+
+// hasCycle 檢查從指定獎池出發的開啟關係是否形成循環
+func (m GachaModel) hasCycle(id string, visiting map[string]bool) bool {
+	if visiting[id] {
+		return true
+	}
+	pool, ok := m.Pool(id)
+	if !ok {
+		return false
+	}
+	visiting[id] = true
+	defer delete(visiting, id)
+	for _, reward := range pool.Rewards {
+		if next, ok := m.Opens[reward.Name]; ok && m.hasCycle(next, visiting) {
+			return true
+		}
+	}
+	return false
+}
+
+// Outcomes 開啟指定獎池一次，最終停在各道具的機率（0~1）
+// 開啟其他獎池的道具會被消耗並展開，同名道具出現在多個獎池時合併計算
+func (m GachaModel) Outcomes(id string) map[string]float64 {
+	outcomes := make(map[string]float64)
+	m.walk(id, 1, func(pool GachaPool, reach float64) {
+		for _, reward := range pool.Rewards {
+			if _, opens := m.Opens[reward.Name]; !opens {
+				outcomes[reward.Name] += reach * reward.Probability / 100
+			}
+		}
+	})
+	return outcomes
+}
+
+// Reach 開啟指定獎池一次，過程中開啟各獎池的機率（0~1，起點為 1）
+func (m GachaModel) Reach(id string) map[string]float64 {
+	reach := make(map[string]float64)
+	m.walk(id, 1, func(pool GachaPool, p float64) {
+		reach[pool.ID] += p
+	})
+	return reach
+}
+
+// Moments 開啟指定獎池一次所得價值的一階與二階動差
+// 開啟其他獎池的道具會被消耗並展開，本身不計價值；獎池不存在時為 0
+func (m GachaModel) Moments(id string, prices map[string]int) (mean, second float64) {
+	pool, ok := m.Pool(id)
+	if !ok {
+		return 0, 0
+	}
+
+	for _, reward := range pool.Rewards {
+		p := reward.Probability / 100
+		if next, opens := m.Opens[reward.Name]; opens {
+			m1, m2 := m.Moments(next, prices)
+			mean += p * m1
+			second += p * m2
+			continue
+		}
+		v := float64(prices[reward.Name])
+		mean += p * v
+		second += p * v * v
+	}
+	return mean, second
+}
+
+// walk 由指定獎池沿開啟關係走訪，visit 取得每個獎池與開啟它的機率
+func (m GachaModel) walk(id string, reach float64, visit func(pool GachaPool, reach float64)) {
+	pool, ok := m.Pool(id)
+	if !ok {
+		return
+	}
+	visit(pool, reach)
+	for _, reward := range pool.Rewards {
+		if next, ok := m.Opens[reward.Name]; ok {
+			m.walk(next, reach*reward.Probability/100, visit)
+		}
+	}
+}
+
+// Expected 抽 draws 次入口獎池並合成後的期望最終道具數量
+// 單一原料的配方視為可分割（期望數量直接除以所需數量），合成所得道具會開啟獎池時展開到最終道具；
+// 需要多種原料的配方（如心願箱）期望數量無法線性展開，不套用
+func (m GachaModel) Expected(draws float64) map[string]float64 {
+	items := make(map[string]float64)
+	for name, p := range m.Outcomes(m.Entry) {
+		items[name] += draws * p
+	}
+
+	for _, recipe := range m.Recipes {
+		if len(recipe.Inputs) != 1 {
+			continue
+		}
+		for input, need := range recipe.Inputs {
+			crafted := items[input] / float64(need)
+			delete(items, input)
+			if pool, ok := m.Opens[recipe.Output]; ok {
+				for name, p := range m.Outcomes(pool) {
+					items[name] += crafted * p
+				}
+				continue
+			}
+			items[recipe.Output] += crafted
+		}
+	}
+	return items
+}
+
+// ItemDistribution 單一最終道具的精確分佈
+type ItemDistribution struct {
+	Name        string
+	Probability float64           // 開啟一次最終獲得此道具的機率 (%)
+	Count       CountDistribution // 開啟 n 次獲得此道具數量的分佈
+}
+
+// OpenDistribution 開啟指定獎池 n 次的各最終道具精確分佈（依機率由高到低）
+// 每次開啟最終必停在恰好一個道具上且彼此獨立，因此各道具數量皆服從二項分佈
+func (m GachaModel) OpenDistribution(id string, n int) []ItemDistribution {
+	var dists []ItemDistribution
+	for name, p := range m.Outcomes(id) {
+		dists = append(dists, ItemDistribution{Name: name, Probability: p * 100, Count: Binomial(n, p)})
+	}
+	sort.Slice(dists, func(i, j int) bool {
+		if dists[i].Probability != dists[j].Probability {
+			return dists[i].Probability > dists[j].Probability
+		}
+		return dists[i].Name < dists[j].Name
+	})
+	return dists
+}
+
+// sortedKeys 取得依名稱排序的鍵
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
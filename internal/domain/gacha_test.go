@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// testGachaModel 兩階段抽獎模型：入口抽到「鑰匙」開啟寶箱，每 2 個「碎片」合成「寶石袋」並開啟寶石獎池
+func testGachaModel() GachaModel {
+	return GachaModel{
+		Name:        "測試",
+		Entry:       "entry",
+		CostPerDraw: 10,
+		Pools: []GachaPool{
+			{ID: "entry", Name: "入口", Rewards: []Reward{
+				{Name: "銅幣", Probability: 50},
+				{Name: "碎片", Probability: 30},
+				{Name: "鑰匙", Probability: 20},
+			}},
+			{ID: "chest", Name: "寶箱", Rewards: []Reward{
+				{Name: "銀幣", Probability: 75},
+				{Name: "金幣", Probability: 25},
+			}},
+			{ID: "gems", Name: "寶石", Rewards: []Reward{
+				{Name: "紅寶石", Probability: 40},
+				{Name: "金幣", Probability: 60},
+			}},
+		},
+		Opens: map[string]string{"鑰匙": "chest", "寶石袋": "gems"},
+		Recipes: []Recipe{
+			{Inputs: map[string]int{"碎片": 2}, Output: "寶石袋"},
+		},
+	}
+}
+
+func TestGachaModelValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(m *GachaModel)
+		wantErr string // 空字串表示應通過驗證
+	}{
+		{"有效模型", func(m *GachaModel) {}, ""},
+		{"入口不存在", func(m *GachaModel) { m.Entry = "missing" }, "入口獎池"},
+		{"開啟的獎池不存在", func(m *GachaModel) { m.Opens["鑰匙"] = "missing" }, "開啟的獎池"},
+		{"開啟關係循環", func(m *GachaModel) { m.Opens["銀幣"] = "entry" }, "開啟關係形成循環"},
+		{"配方產物為自身原料", func(m *GachaModel) {
+			m.Recipes = append(m.Recipes, Recipe{Inputs: map[string]int{"銅幣": 2}, Output: "銅幣"})
+		}, "產物同時為其原料"},
+		{"合成關係循環", func(m *GachaModel) {
+			// 碎片 → 寶石袋 → 金幣 → 碎片
+			m.Recipes = append(m.Recipes, Recipe{Inputs: map[string]int{"金幣": 1}, Output: "碎片"})
+		}, "合成配方形成循環"},
+		{"配方數量為 0", func(m *GachaModel) { m.Recipes[0].Inputs["碎片"] = 0 }, "所需數量"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := testGachaModel()
+			tt.modify(&model)
+
+			var messages []string
+			for _, issue := range model.Validate().Errors() {
+				messages = append(messages, issue.Message)
+			}
+			joined := strings.Join(messages, "; ")
+			switch {
+			case tt.wantErr == "" && len(messages) > 0:
+				t.Errorf("Validate() = %q, want 無錯誤", joined)
+			case tt.wantErr != "" && !strings.Contains(joined, tt.wantErr):
+				t.Errorf("Validate() = %q, want 包含 %q", joined, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGachaModelOutcomesAndReach(t *testing.T) {
+	model := testGachaModel()
+
+	outcomes := model.Outcomes("entry")
+	want := map[string]float64{"銅幣": 0.5, "碎片": 0.3, "銀幣": 0.15, "金幣": 0.05}
+	assertFloatMap(t, "Outcomes", outcomes, want)
+
+	reach := model.Reach("entry")
+	assertFloatMap(t, "Reach", reach, map[string]float64{"entry": 1, "chest": 0.2})
+}
+
+func TestGachaModelExpected(t *testing.T) {
+	model := testGachaModel()
+
+	// 100 抽：碎片 30 個合成 15 個寶石袋，每個寶石袋 40% 紅寶石、60% 金幣
+	want := map[string]float64{
+		"銅幣":  50,
+		"銀幣":  15,
+		"金幣":  5 + 15*0.6,
+		"紅寶石": 15 * 0.4,
+	}
+	assertFloatMap(t, "Expected", model.Expected(100), want)
+}
+
+func TestGachaModelMoments(t *testing.T) {
+	model := testGachaModel()
+	prices := map[string]int{"銅幣": 1, "碎片": 4, "銀幣": 10, "金幣": 20}
+
+	mean, second := model.Moments("entry", prices)
+	// 鑰匙開啟寶箱：寶箱一次的平均 12.5、二階動差 175
+	wantMean := 0.5*1 + 0.3*4 + 0.2*12.5
+	wantSecond := 0.5*1 + 0.3*16 + 0.2*175
+	if math.Abs(mean-wantMean) > 1e-9 || math.Abs(second-wantSecond) > 1e-9 {
+		t.Errorf("Moments = (%g, %g), want (%g, %g)", mean, second, wantMean, wantSecond)
+	}
+}
+
+func TestGachaModelOpenDistribution(t *testing.T) {
+	model := testGachaModel()
+	for _, dist := range model.OpenDistribution("chest", 4) {
+		p := map[string]float64{"銀幣": 0.75, "金幣": 0.25}[dist.Name]
+		if math.Abs(dist.Probability-p*100) > 1e-9 || math.Abs(dist.Count.Mean-4*p) > 1e-9 {
+			t.Errorf("%s: Probability = %g%%, Mean = %g, want %g%%, %g", dist.Name, dist.Probability, dist.Count.Mean, p*100, 4*p)
+		}
+	}
+}
+
+func assertFloatMap(t *testing.T, name string, got, want map[string]float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+		return
+	}
+	for key, w := range want {
+		if math.Abs(got[key]-w) > 1e-9 {
+			t.Errorf("%s[%s] = %g, want %g", name, key, got[key], w)
+		}
+	}
+}
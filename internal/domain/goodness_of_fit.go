@@ -0,0 +1,289 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// 適合度檢定參數
+const (
+	fitTolerance     = 1e-7    // 比較結果機率時允許的相對誤差
+	lowExpectedCount = 5       // 期望次數低於此值時卡方近似不可靠
+	maxExactOutcomes = 2000000 // 多項精確檢定列舉的結果數上限
+)
+
+// 多項精確檢定的計算方式
+const (
+	ExactEnumeration = "exact"       // 列舉所有結果
+	ExactMonteCarlo  = "monte_carlo" // 蒙地卡羅模擬
+)
+
+// ItemFit 單一道具的觀察值與公告機率比較
+type ItemFit struct {
+	Item         string
+	Probability  float64 // 公告機率 (%)
+	Observed     int     // 實際數量
+	Expected     float64 // 期望數量
+	ObservedRate float64 // 實際比例 (%)
+	Deviation    float64 // 實際數量 − 期望數量
+	Residual     float64 // 標準化殘差 (O − E) / √(N·p·(1 − p))
+	PValue       float64 // 雙尾精確二項檢定 p 值（未做多重比較校正）
+}
+
+// FitTest 單一獎池的適合度檢定結果
+type FitTest struct {
+	Pool             string // 獎池代號
+	Name             string // 獎池顯示名稱
+	Sessions         int    // 紀錄筆數
+	Total            int    // 總抽數
+	Items            []ItemFit
+	ChiSquare        float64 // 卡方統計量
+	DegreesOfFreedom int
+	ChiSquarePValue  float64
+	LowExpected      int     // 期望數量低於 5 的道具數（卡方近似可能不準確）
+	ExactPValue      float64 // 多項精確檢定 p 值
+	ExactMethod      string  // 多項精確檢定的計算方式
+	ExactTrials      int     // 蒙地卡羅模擬次數（列舉時為 0）
+}
+
+// NewFitTest 以獎池公告機率檢定各道具的觀察數量：卡方檢定與各道具的精確二項檢定
+// 多項精確檢定可列舉時一併計算，否則 ExactMethod 為空，需另以模擬估計
+func NewFitTest(pool, name string, rewards []Reward, counts map[string]int, sessions int) (FitTest, error) {
+	// 1. 依獎池順序整理道具（同名道具機率合併），並檢查紀錄中的道具皆在獎池中
+	var items []string
+	var probs []float64
+	var totalProbability float64
+	seen := make(map[string]bool)
+	for _, reward := range rewards {
+		if seen[reward.Name] {
+			continue
+		}
+		seen[reward.Name] = true
+		items = append(items, reward.Name)
+		probs = append(probs, Probability(rewards, reward.Name))
+		totalProbability += Probability(rewards, reward.Name)
+	}
+	for item := range counts {
+		if !seen[item] {
+			return FitTest{}, fmt.Errorf("道具 %q 不在%s的獎池中", item, name)
+		}
+	}
+	if totalProbability <= 0 {
+		return FitTest{}, fmt.Errorf("%s的獎池沒有可抽到的道具", name)
+	}
+
+	test := FitTest{Pool: pool, Name: name, Sessions: sessions}
+	for _, item := range items {
+		test.Total += counts[item]
+	}
+	if test.Total == 0 {
+		return FitTest{}, fmt.Errorf("%s的紀錄總抽數為 0", name)
+	}
+
+	// 2. 各道具的偏差與精確二項檢定，並累計卡方統計量
+	n := float64(test.Total)
+	for i, item := range items {
+		p := probs[i] / totalProbability
+		observed := counts[item]
+		expected := n * p
+		fit := ItemFit{
+			Item:         item,
+			Probability:  probs[i],
+			Observed:     observed,
+			Expected:     expected,
+			ObservedRate: float64(observed) / n * 100,
+			Deviation:    float64(observed) - expected,
+			PValue:       BinomialTest(test.Total, observed, p),
+		}
+		if variance := expected * (1 - p); variance > 0 {
+			fit.Residual = fit.Deviation / math.Sqrt(variance)
+		}
+		test.Items = append(test.Items, fit)
+
+		switch {
+		case expected > 0:
+			test.ChiSquare += fit.Deviation * fit.Deviation / expected
+			test.DegreesOfFreedom++
+			if expected < lowExpectedCount {
+				test.LowExpected++
+			}
+		case observed > 0:
+			// 抽到機率為 0 的道具
+			test.ChiSquare = math.Inf(1)
+		}
+	}
+	test.DegreesOfFreedom = max(test.DegreesOfFreedom-1, 1)
+	test.ChiSquarePValue = ChiSquareSurvival(test.ChiSquare, test.DegreesOfFreedom)
+
+	// 3. 結果數不多時列舉計算多項精確檢定
+	if p, ok := ExactMultinomialTest(test.Categories()); ok {
+		test.ExactPValue = p
+		test.ExactMethod = ExactEnumeration
+	}
+	return test, nil
+}
+
+// Categories 各道具的觀察數量與正規化後的機率（0~1）
+func (t FitTest) Categories() ([]int, []float64) {
+	counts := make([]int, len(t.Items))
+	probs := make([]float64, len(t.Items))
+	var total float64
+	for _, item := range t.Items {
+		total += item.Probability
+	}
+	for i, item := range t.Items {
+		counts[i] = item.Observed
+		probs[i] = item.Probability / total
+	}
+	return counts, probs
+}
+
+// MultinomialLogPMF 計算多項分佈 log P(X = counts)，probs 為各類別機率（0~1）
+func MultinomialLogPMF(counts []int, probs []float64) float64 {
+	n := 0
+	for _, count := range counts {
+		n += count
+	}
+	logP, _ := math.Lgamma(float64(n + 1))
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		if probs[i] <= 0 {
+			return math.Inf(-1)
+		}
+		lg, _ := math.Lgamma(float64(count + 1))
+		logP += float64(count)*math.Log(probs[i]) - lg
+	}
+	return logP
+}
+
+// IsAsExtreme 結果機率是否不大於觀察結果的機率（容許浮點誤差）
+func IsAsExtreme(logP, observedLogP float64) bool {
+	return logP <= observedLogP+fitTolerance
+}
+
+// ExactMultinomialTest 列舉所有結果計算多項精確檢定 p 值（機率不大於觀察結果的所有結果機率總和）
+// 結果數超過上限時回傳 false
+func ExactMultinomialTest(counts []int, probs []float64) (float64, bool) {
+	n := 0
+	for _, count := range counts {
+		n += count
+	}
+	k := len(counts)
+	if k == 0 {
+		return 1, true
+	}
+
+	// 結果數 C(n + k − 1, k − 1)
+	a, _ := math.Lgamma(float64(n + k))
+	b, _ := math.Lgamma(float64(n + 1))
+	c, _ := math.Lgamma(float64(k))
+	if a-b-c > math.Log(maxExactOutcomes) {
+		return 0, false
+	}
+
+	observed := MultinomialLogPMF(counts, probs)
+	logProbs := make([]float64, k)
+	for i, p := range probs {
+		logProbs[i] = math.Log(p)
+	}
+	base, _ := math.Lgamma(float64(n + 1))
+
+	var total float64
+	var enumerate func(index, remaining int, logP float64)
+	enumerate = func(index, remaining int, logP float64) {
+		if index == k-1 {
+			logP += categoryLogTerm(remaining, logProbs[index])
+			if IsAsExtreme(logP, observed) {
+				total += math.Exp(logP)
+			}
+			return
+		}
+		for x := 0; x <= remaining; x++ {
+			enumerate(index+1, remaining-x, logP+categoryLogTerm(x, logProbs[index]))
+		}
+	}
+	enumerate(0, n, base)
+	return math.Min(total, 1), true
+}
+
+// categoryLogTerm 多項分佈中單一類別的對數項 x·log p − log x!
+func categoryLogTerm(x int, logP float64) float64 {
+	if x == 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(float64(x + 1))
+	return float64(x)*logP - lg
+}
+
+// BinomialTest 雙尾精確二項檢定：X ~ B(n, p) 中機率不大於 P(X = k) 的結果機率總和
+func BinomialTest(n, k int, p float64) float64 {
+	observed := binomialPMF(n, k, p)
+	if observed <= 0 {
+		return 0
+	}
+	var total float64
+	for _, m := range Binomial(n, p).Mass {
+		if mass := m.Probability / 100; mass <= observed*(1+fitTolerance) {
+			total += mass
+		}
+	}
+	return math.Min(total, 1)
+}
+
+// ChiSquareSurvival 卡方分佈的右尾機率 P(X ≥ x)，df 為自由度
+func ChiSquareSurvival(x float64, df int) float64 {
+	switch {
+	case math.IsInf(x, 1):
+		return 0
+	case x <= 0:
+		return 1
+	}
+	return regularizedGammaQ(float64(df)/2, x/2)
+}
+
+// regularizedGammaQ 正規化上不完全伽瑪函數 Q(a, x)
+// x < a + 1 時以級數計算 P(a, x) 再取補數，否則以連分數計算
+func regularizedGammaQ(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < 10000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return math.Max(1-sum*prefix, 0)
+	}
+
+	// Lentz 法計算連分數
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 10000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return prefix * h
+}
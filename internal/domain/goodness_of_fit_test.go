@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChiSquareSurvival(t *testing.T) {
+	tests := []struct {
+		name string
+		x    float64
+		df   int
+		want float64
+	}{
+		{"df1 的 95% 臨界值", 3.8415, 1, 0.05},
+		{"df2 的 95% 臨界值", 5.9915, 2, 0.05},
+		{"df10 的 99% 臨界值", 23.2093, 10, 0.01},
+		{"df2 為指數分佈", 2, 2, math.Exp(-1)},
+		{"x 為 0", 0, 3, 1},
+		{"x 為負", -1, 3, 1},
+		{"x 為無限大", math.Inf(1), 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChiSquareSurvival(tt.x, tt.df); math.Abs(got-tt.want) > 1e-4 {
+				t.Errorf("ChiSquareSurvival(%g, %d) = %g, want %g", tt.x, tt.df, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExactMultinomialTest(t *testing.T) {
+	third := 1.0 / 3
+	tests := []struct {
+		name   string
+		counts []int
+		probs  []float64
+		want   float64
+	}{
+		{"全部落在同一類", []int{3, 0, 0}, []float64{third, third, third}, 1.0 / 9},
+		{"最可能的結果", []int{1, 1, 1}, []float64{third, third, third}, 1},
+		{"二項兩端", []int{2, 0}, []float64{0.5, 0.5}, 0.5},
+		{"二項中間", []int{1, 1}, []float64{0.5, 0.5}, 1},
+		{"不對稱機率", []int{0, 2}, []float64{0.9, 0.1}, 0.01},
+		{"沒有類別", nil, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExactMultinomialTest(tt.counts, tt.probs)
+			if !ok {
+				t.Fatalf("ExactMultinomialTest(%v) 無法列舉", tt.counts)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ExactMultinomialTest(%v, %v) = %g, want %g", tt.counts, tt.probs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExactMultinomialTestTooManyOutcomes(t *testing.T) {
+	counts := make([]int, 20)
+	probs := make([]float64, 20)
+	for i := range counts {
+		counts[i] = 100
+		probs[i] = 0.05
+	}
+	if _, ok := ExactMultinomialTest(counts, probs); ok {
+		t.Error("結果數超過上限時應回傳 false")
+	}
+}
+
+func TestBinomialTest(t *testing.T) {
+	tests := []struct {
+		name string
+		n, k int
+		p    float64
+		want float64
+	}{
+		{"觀察值為眾數", 10, 5, 0.5, 1},
+		{"觀察值在尾端", 10, 0, 0.5, 2.0 / 1024},
+		{"不可能的結果", 10, 1, 0, 0},
+		{"不對稱機率", 2, 2, 0.1, 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BinomialTest(tt.n, tt.k, tt.p); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("BinomialTest(%d, %d, %g) = %g, want %g", tt.n, tt.k, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFitTest(t *testing.T) {
+	rewards := []Reward{{Name: "A", Probability: 50}, {Name: "B", Probability: 50}}
+	test, err := NewFitTest("pool", "測試", rewards, map[string]int{"A": 60, "B": 40}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.Total != 100 || test.DegreesOfFreedom != 1 {
+		t.Errorf("Total = %d, DegreesOfFreedom = %d, want 100, 1", test.Total, test.DegreesOfFreedom)
+	}
+	// (60−50)²/50 + (40−50)²/50 = 4
+	if math.Abs(test.ChiSquare-4) > 1e-9 {
+		t.Errorf("ChiSquare = %g, want 4", test.ChiSquare)
+	}
+	if want := ChiSquareSurvival(4, 1); math.Abs(test.ChiSquarePValue-want) > 1e-12 {
+		t.Errorf("ChiSquarePValue = %g, want %g", test.ChiSquarePValue, want)
+	}
+
+	if _, err := NewFitTest("pool", "測試", rewards, map[string]int{"C": 1}, 1); err == nil {
+		t.Error("紀錄中有獎池外的道具時應回傳錯誤")
+	}
+}
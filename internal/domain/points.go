@@ -0,0 +1,69 @@
+package domain
+
+import "math"
+
+// pointEpsilon 點數比較容許誤差，避免浮點誤差少算一抽
+const pointEpsilon = 1e-9
+
+// DrawPlan 以整數抽數使用點數的結果
+type DrawPlan struct {
+	Name        string  // 活動名稱
+	Points      float64 // 可用點數（含前一步結轉的點數）
+	CostPerDraw float64 // 每抽點數
+	Draws       int     // 可抽次數（無條件捨去）
+	Spent       float64 // 實際使用的點數
+	Residual    float64 // 不足一抽的剩餘點數，可結轉至下次購買或其他活動
+}
+
+// PlanDraws 以整數抽數使用點數，不足一抽的點數保留為剩餘點數
+func PlanDraws(points float64, costPerDraw float64) DrawPlan {
+	plan := DrawPlan{Points: points, CostPerDraw: costPerDraw, Residual: math.Max(points, 0)}
+	if points <= 0 || costPerDraw <= 0 {
+		return plan
+	}
+	plan.Draws = int(math.Floor(points/costPerDraw + pointEpsilon))
+	plan.Spent = float64(plan.Draws) * costPerDraw
+	plan.Residual = math.Max(points-plan.Spent, 0)
+	return plan
+}
+
+// PointAllocation 點數計畫中分配給一個活動的點數
+type PointAllocation struct {
+	Name        string  // 活動名稱
+	CostPerDraw float64 // 每抽點數
+	Points      float64 // 分配的點數，0 表示使用所有剩餘點數
+}
+
+// PointPlan 同一次購買的點數依序用於多個活動的計畫
+type PointPlan struct {
+	Points   float64    // 可用點數總和（含結轉點數）
+	Steps    []DrawPlan // 各活動的整數抽數
+	Residual float64    // 計畫結束後的剩餘點數（未分配 + 最後不足一抽的點數）
+}
+
+// PlanPoints 依序將點數分配給各活動並以整數抽數使用，
+// 每一步不足一抽的點數會併入下一步的分配，最後的剩餘點數可結轉至下次購買
+func PlanPoints(points float64, allocations []PointAllocation) PointPlan {
+	plan := PointPlan{Points: points}
+	remaining := math.Max(points, 0)
+	carry := 0.0
+
+	for _, allocation := range allocations {
+		// 1. 本步可用點數：分配的點數 + 前一步結轉，不超過剩餘點數
+		budget := remaining
+		if allocation.Points > 0 {
+			budget = math.Min(allocation.Points+carry, remaining)
+		}
+
+		// 2. 以整數抽數使用，不足一抽的點數結轉至下一步
+		step := PlanDraws(budget, allocation.CostPerDraw)
+		step.Name = allocation.Name
+		plan.Steps = append(plan.Steps, step)
+
+		remaining -= step.Spent
+		carry = step.Residual
+	}
+
+	plan.Residual = remaining
+	return plan
+}
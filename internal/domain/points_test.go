@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPlanDraws(t *testing.T) {
+	tests := []struct {
+		name        string
+		points      float64
+		costPerDraw float64
+		draws       int
+		residual    float64
+	}{
+		{"剛好整數抽", 270, 27, 10, 0},
+		{"保留不足一抽的點數", 100, 27, 3, 19},
+		{"不足一抽", 26, 27, 0, 26},
+		{"浮點誤差不少算一抽", 0.3, 0.1, 3, 0},
+		{"點數為負", -5, 27, 0, 0},
+		{"每抽點數為 0", 100, 0, 0, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanDraws(tt.points, tt.costPerDraw)
+			if plan.Draws != tt.draws || math.Abs(plan.Residual-tt.residual) > 1e-9 {
+				t.Errorf("PlanDraws(%g, %g) = %d 抽剩 %g, want %d 抽剩 %g", tt.points, tt.costPerDraw, plan.Draws, plan.Residual, tt.draws, tt.residual)
+			}
+			if plan.Draws > 0 && math.Abs(plan.Spent-float64(plan.Draws)*tt.costPerDraw) > 1e-9 {
+				t.Errorf("Spent = %g, want %g", plan.Spent, float64(plan.Draws)*tt.costPerDraw)
+			}
+		})
+	}
+}
+
+func TestPlanPoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		points      float64
+		allocations []PointAllocation
+		draws       []int
+		residual    float64
+	}{
+		{"單一活動使用所有點數", 100, []PointAllocation{{Name: "zodiac", CostPerDraw: 27}}, []int{3}, 19},
+		{
+			"剩餘點數全數用於最後一個活動", 1000,
+			[]PointAllocation{{Name: "zodiac", CostPerDraw: 27, Points: 500}, {Name: "starlight", CostPerDraw: 45}},
+			// 新年氣息 500 點抽 18 次剩 14 點，星光錦囊以剩餘 514 點抽 11 次
+			[]int{18, 11}, 19,
+		},
+		{
+			"不足一抽的點數併入下一步", 1000,
+			[]PointAllocation{{Name: "zodiac", CostPerDraw: 27, Points: 500}, {Name: "starlight", CostPerDraw: 45, Points: 300}},
+			// 星光錦囊以 300 + 14 點抽 6 次，未分配的點數結轉
+			[]int{18, 6}, 1000 - 486 - 270,
+		},
+		{"分配超過可用點數", 100, []PointAllocation{{Name: "zodiac", CostPerDraw: 27, Points: 500}}, []int{3}, 19},
+		{"點數為負", -5, []PointAllocation{{Name: "zodiac", CostPerDraw: 27}}, []int{0}, 0},
+		{"沒有活動", 100, nil, nil, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanPoints(tt.points, tt.allocations)
+			if len(plan.Steps) != len(tt.draws) {
+				t.Fatalf("Steps = %d 步, want %d 步", len(plan.Steps), len(tt.draws))
+			}
+			spent := 0.0
+			for i, step := range plan.Steps {
+				if step.Name != tt.allocations[i].Name || step.Draws != tt.draws[i] {
+					t.Errorf("第 %d 步 = %s %d 抽, want %s %d 抽", i+1, step.Name, step.Draws, tt.allocations[i].Name, tt.draws[i])
+				}
+				spent += step.Spent
+			}
+			if math.Abs(plan.Residual-tt.residual) > 1e-9 {
+				t.Errorf("Residual = %g, want %g", plan.Residual, tt.residual)
+			}
+			// 使用的點數與剩餘點數合計等於可用點數
+			if tt.points > 0 && math.Abs(spent+plan.Residual-tt.points) > 1e-9 {
+				t.Errorf("使用 %g + 剩餘 %g ≠ 可用 %g", spent, plan.Residual, tt.points)
+			}
+		})
+	}
+}
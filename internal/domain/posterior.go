@@ -0,0 +1,257 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"math"
+)
+
+// 事後機率估計的預設參數
+const (
+	DefaultPriorWeight   = 1000 // 公告機率先驗相當於的抽數
+	DefaultCredibleLevel = 0.95 // 可信區間的機率
+)
+
+// RateEstimate 單一道具的事後機率估計
+type RateEstimate struct {
+	Item     string
+	Prior    float64 // 公告機率 (%)
+	Observed int     // 實際數量
+	Alpha    float64 // 事後 Dirichlet 參數（先驗強度 × 公告比例 + 實際數量）
+	Mean     float64 // 事後平均機率 (%)
+	StdDev   float64 // 事後標準差 (%)
+	Lower    float64 // 可信區間下界 (%)
+	Upper    float64 // 可信區間上界 (%)
+}
+
+// PosteriorRates 單一獎池的 Dirichlet-多項事後分佈
+//
+// 先驗為 Dirichlet(w·p)，p 為正規化後的公告機率、w 為先驗強度（相當於已觀察 w 抽且完全符合公告機率）；
+// 觀察到各道具數量 n 後，事後分佈為 Dirichlet(w·p + n)，各道具的邊際分佈為 Beta(αᵢ, Σα − αᵢ)。
+type PosteriorRates struct {
+	Pool        string  // 獎池代號
+	Name        string  // 獎池顯示名稱
+	Sessions    int     // 紀錄筆數
+	Total       int     // 總抽數
+	PriorWeight float64 // 先驗強度（抽數）
+	Level       float64 // 可信區間的機率（0~1）
+	Items       []RateEstimate
+}
+
+// NewPosteriorRates 以公告機率為先驗、觀察數量為資料，計算各道具的事後機率與可信區間
+func NewPosteriorRates(pool, name string, rewards []Reward, counts map[string]int, sessions int, priorWeight, level float64) (PosteriorRates, error) {
+	if priorWeight < 0 {
+		return PosteriorRates{}, fmt.Errorf("先驗強度不可為負: %g", priorWeight)
+	}
+	if level <= 0 || level >= 1 {
+		return PosteriorRates{}, fmt.Errorf("可信區間機率需介於 0 與 1 之間: %g", level)
+	}
+
+	// 1. 依獎池順序整理道具（同名道具機率合併），並檢查紀錄中的道具皆在獎池中
+	var items []string
+	var totalProbability float64
+	seen := make(map[string]bool)
+	for _, reward := range rewards {
+		if !seen[reward.Name] {
+			seen[reward.Name] = true
+			items = append(items, reward.Name)
+		}
+		totalProbability += reward.Probability
+	}
+	for item := range counts {
+		if !seen[item] {
+			return PosteriorRates{}, fmt.Errorf("道具 %q 不在%s的獎池中", item, name)
+		}
+	}
+	if totalProbability <= 0 {
+		return PosteriorRates{}, fmt.Errorf("%s的獎池沒有可抽到的道具", name)
+	}
+
+	// 2. 事後 Dirichlet 參數
+	posterior := PosteriorRates{Pool: pool, Name: name, Sessions: sessions, PriorWeight: priorWeight, Level: level}
+	var alphaTotal float64
+	for _, item := range items {
+		probability := Probability(rewards, item)
+		estimate := RateEstimate{
+			Item:     item,
+			Prior:    probability,
+			Observed: counts[item],
+			Alpha:    priorWeight*probability/totalProbability + float64(counts[item]),
+		}
+		posterior.Total += estimate.Observed
+		alphaTotal += estimate.Alpha
+		posterior.Items = append(posterior.Items, estimate)
+	}
+	if alphaTotal <= 0 {
+		return PosteriorRates{}, fmt.Errorf("%s的先驗強度與紀錄總抽數皆為 0", name)
+	}
+
+	// 3. 各道具的 Beta 邊際分佈：平均、標準差與等尾可信區間
+	tail := (1 - level) / 2
+	for i := range posterior.Items {
+		estimate := &posterior.Items[i]
+		a, b := estimate.Alpha, alphaTotal-estimate.Alpha
+		estimate.Mean = a / alphaTotal * 100
+		estimate.StdDev = math.Sqrt(a*b/(alphaTotal*alphaTotal*(alphaTotal+1))) * 100
+		estimate.Lower = BetaQuantile(tail, a, b) * 100
+		estimate.Upper = BetaQuantile(1-tail, a, b) * 100
+	}
+	return posterior, nil
+}
+
+// Means 各道具的事後平均機率（0~1）
+func (p PosteriorRates) Means() map[string]float64 {
+	means := make(map[string]float64, len(p.Items))
+	for _, item := range p.Items {
+		means[item.Item] = item.Mean / 100
+	}
+	return means
+}
+
+// Alphas 各道具的事後 Dirichlet 參數（依 Items 順序）
+func (p PosteriorRates) Alphas() []float64 {
+	alphas := make([]float64, len(p.Items))
+	for i, item := range p.Items {
+		alphas[i] = item.Alpha
+	}
+	return alphas
+}
+
+// ApplyRates 以新的道具機率（0~1）取代獎池機率，維持原獎池的機率總和
+// 同名的多個獎項依原機率比例分配（原機率皆為 0 時平均分配），未指定的道具機率不變
+func ApplyRates(rewards []Reward, rates map[string]float64) []Reward {
+	var total float64
+	names := make(map[string]int)
+	for _, reward := range rewards {
+		total += reward.Probability
+		names[reward.Name]++
+	}
+
+	applied := make([]Reward, len(rewards))
+	for i, reward := range rewards {
+		applied[i] = reward
+		rate, ok := rates[reward.Name]
+		if !ok {
+			continue
+		}
+		if original := Probability(rewards, reward.Name); original > 0 {
+			applied[i].Probability = rate * total * reward.Probability / original
+		} else {
+			applied[i].Probability = rate * total / float64(names[reward.Name])
+		}
+	}
+	return applied
+}
+
+// WithPoolRates 建立以新的道具機率（0~1）取代指定獎池機率的活動定義（不修改原定義）
+func (e EventDefinition) WithPoolRates(id string, rates map[string]float64) (EventDefinition, error) {
+	_, pool, err := e.PoolByID(id)
+	if err != nil {
+		return EventDefinition{}, err
+	}
+	pool = ApplyRates(pool, rates)
+
+	updated := e
+	switch id {
+	case PoolZodiac:
+		updated.Zodiac.Rates = make(map[Zodiac]float64, len(pool))
+		for _, reward := range pool {
+			updated.Zodiac.Rates[Zodiac(reward.Name)] = reward.Probability
+		}
+	case PoolStage1:
+		updated.Starlight.Stage1Pool = pool
+	default:
+		stagePools := maps.Clone(e.Starlight.StagePools)
+		for stage := range stagePools {
+			if StagePoolID(stage) == id {
+				stagePools[stage] = pool
+			}
+		}
+		updated.Starlight.StagePools = stagePools
+	}
+	return updated, nil
+}
+
+// BetaQuantile Beta(a, b) 分佈的分位數（以二分法求 I_x(a, b) = p）
+func BetaQuantile(p, a, b float64) float64 {
+	switch {
+	case a <= 0:
+		return 0
+	case b <= 0:
+		return 1
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if RegularizedBeta(mid, a, b) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// RegularizedBeta 正規化不完全貝他函數 I_x(a, b)
+// x < (a + 1) / (a + b + 2) 時直接以連分數計算，否則以 1 − I_{1−x}(b, a) 計算
+func RegularizedBeta(x, a, b float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	lab, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	prefix := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	if x < (a+1)/(a+b+2) {
+		return prefix * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - prefix*betaContinuedFraction(1-x, b, a)/b
+}
+
+// betaContinuedFraction 以 Lentz 法計算不完全貝他函數的連分數
+func betaContinuedFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m < 10000; m++ {
+		fm := float64(m)
+		// 偶數項
+		an := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + an*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// 奇數項
+		an = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + an*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return h
+}
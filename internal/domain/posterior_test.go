@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegularizedBeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		x, a, b float64
+		want    float64
+	}{
+		{"均勻分佈", 0.3, 1, 1, 0.3},
+		{"Beta(2, 1)", 0.6, 2, 1, 0.36},
+		{"Beta(1, 2)", 0.6, 1, 2, 1 - 0.4*0.4},
+		{"對稱分佈的中位數", 0.5, 7.5, 7.5, 0.5},
+		{"Beta(2, 3)", 0.4, 2, 3, 0.5248},
+		{"x 為 0", 0, 2, 3, 0},
+		{"x 為 1", 1, 2, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RegularizedBeta(tt.x, tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("RegularizedBeta(%g, %g, %g) = %g, want %g", tt.x, tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetaQuantile(t *testing.T) {
+	tests := []struct {
+		p, a, b float64
+	}{
+		{0.025, 2, 3},
+		{0.5, 2, 3},
+		{0.975, 50, 950},
+		{0.9, 0.5, 0.5},
+	}
+	for _, tt := range tests {
+		x := BetaQuantile(tt.p, tt.a, tt.b)
+		if got := RegularizedBeta(x, tt.a, tt.b); math.Abs(got-tt.p) > 1e-9 {
+			t.Errorf("RegularizedBeta(BetaQuantile(%g, %g, %g)) = %g, want %g", tt.p, tt.a, tt.b, got, tt.p)
+		}
+	}
+}
+
+func TestNewPosteriorRates(t *testing.T) {
+	rewards := []Reward{{Name: "A", Probability: 20}, {Name: "B", Probability: 50}, {Name: "A", Probability: 30}}
+
+	tests := []struct {
+		name        string
+		counts      map[string]int
+		priorWeight float64
+		alphas      []float64 // 依獎池順序：A、B
+		means       []float64 // (%)
+	}{
+		{"沒有紀錄時為公告機率", nil, 100, []float64{50, 50}, []float64{50, 50}},
+		{"先驗與紀錄合併", map[string]int{"A": 10, "B": 30}, 40, []float64{30, 50}, []float64{37.5, 62.5}},
+		{"無先驗時為實際比例", map[string]int{"A": 1, "B": 3}, 0, []float64{1, 3}, []float64{25, 75}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posterior, err := NewPosteriorRates("pool", "測試", rewards, tt.counts, 1, tt.priorWeight, 0.95)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(posterior.Items) != 2 {
+				t.Fatalf("Items = %d 個，同名道具應合併為 2 個", len(posterior.Items))
+			}
+			for i, item := range posterior.Items {
+				if math.Abs(item.Alpha-tt.alphas[i]) > 1e-9 || math.Abs(item.Mean-tt.means[i]) > 1e-9 {
+					t.Errorf("%s: Alpha = %g, Mean = %g, want %g, %g", item.Item, item.Alpha, item.Mean, tt.alphas[i], tt.means[i])
+				}
+				if !(item.Lower < item.Mean && item.Mean < item.Upper) {
+					t.Errorf("%s: 可信區間 [%g, %g] 未包含平均 %g", item.Item, item.Lower, item.Upper, item.Mean)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPosteriorRatesErrors(t *testing.T) {
+	rewards := []Reward{{Name: "A", Probability: 50}, {Name: "B", Probability: 50}}
+	tests := []struct {
+		name        string
+		counts      map[string]int
+		priorWeight float64
+		level       float64
+	}{
+		{"先驗強度為負", nil, -1, 0.95},
+		{"可信區間機率超出範圍", nil, 100, 1},
+		{"獎池外的道具", map[string]int{"C": 1}, 100, 0.95},
+		{"沒有先驗也沒有紀錄", nil, 0, 0.95},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPosteriorRates("pool", "測試", rewards, tt.counts, 0, tt.priorWeight, tt.level); err == nil {
+				t.Error("應回傳錯誤")
+			}
+		})
+	}
+}
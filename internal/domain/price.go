@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// PriceBook 道具價格表（道具名稱 -> 價格，台幣）
+// 心願箱價值以心願箱類型（小吉、中吉、大吉、超越）為鍵
+type PriceBook map[string]int
+
+// PricedItems 活動中需要設定價格的道具：星光錦囊有價值道具、玲瓏星光與各心願箱
+func (e EventDefinition) PricedItems() []string {
+	items := append([]string{}, e.Starlight.ValuableItems...)
+	if e.Starlight.CrystalItem != "" {
+		items = append(items, e.Starlight.CrystalItem)
+	}
+	for _, boxType := range e.Zodiac.BoxPriority {
+		items = append(items, string(boxType))
+	}
+	return items
+}
+
+// Validate 檢查價格表（價格不可為負、道具名稱不可為空）
+func (b PriceBook) Validate() error {
+	for item, price := range b {
+		if item == "" {
+			return errors.New("道具名稱不可為空")
+		}
+		if price < 0 {
+			return fmt.Errorf("道具 %q 的價格不可為負", item)
+		}
+	}
+	return nil
+}
+
+// WithDefaults 複製價格表並為缺少的道具補上價格 0
+func (b PriceBook) WithDefaults(items []string) PriceBook {
+	book := make(PriceBook, len(b)+len(items))
+	for _, item := range items {
+		book[item] = 0
+	}
+	for item, price := range b {
+		book[item] = price
+	}
+	return book
+}
+
+// Items 價格表中的道具名稱：先依 order 的順序，其餘道具依名稱排序
+func (b PriceBook) Items(order []string) []string {
+	items := make([]string, 0, len(b))
+	listed := make(map[string]bool)
+	for _, item := range order {
+		if _, ok := b[item]; ok && !listed[item] {
+			items = append(items, item)
+			listed[item] = true
+		}
+	}
+
+	var rest []string
+	for item := range b {
+		if !listed[item] {
+			rest = append(rest, item)
+		}
+	}
+	sort.Strings(rest)
+	return append(items, rest...)
+}
+
+// BoxValues 取得價格表中的心願箱價值
+func (b PriceBook) BoxValues() BoxValues {
+	return BoxValues{
+		Small:  float64(b[string(BoxSmall)]),
+		Medium: float64(b[string(BoxMedium)]),
+		Large:  float64(b[string(BoxLarge)]),
+		Super:  float64(b[string(BoxSuper)]),
+	}
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// DateLayout 價格快照日期格式
+const DateLayout = "2006-01-02"
+
+// PriceSnapshot 某日記錄的道具價格（可只包含當日有變動的道具）
+type PriceSnapshot struct {
+	Date   time.Time
+	Prices PriceBook
+}
+
+// PriceHistory 依日期排序的道具價格快照
+type PriceHistory []PriceSnapshot
+
+// TruncateDate 將時間截斷為 UTC 日期
+func TruncateDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Record 記錄某日的道具價格，回傳新的價格歷史
+// 同一日已有快照時合併價格（新價格優先）
+func (h PriceHistory) Record(date time.Time, prices PriceBook) PriceHistory {
+	date = TruncateDate(date)
+	history := make(PriceHistory, 0, len(h)+1)
+	merged := false
+	for _, snapshot := range h {
+		if snapshot.Date.Equal(date) {
+			book := snapshot.Prices.WithDefaults(nil)
+			for item, price := range prices {
+				book[item] = price
+			}
+			snapshot = PriceSnapshot{Date: date, Prices: book}
+			merged = true
+		}
+		history = append(history, snapshot)
+	}
+	if !merged {
+		history = append(history, PriceSnapshot{Date: date, Prices: prices.WithDefaults(nil)})
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	return history
+}
+
+// At 取得某日的道具價格：各道具取該日（含）之前最後一次記錄的價格
+func (h PriceHistory) At(date time.Time) PriceBook {
+	date = TruncateDate(date)
+	prices := make(PriceBook)
+	for _, snapshot := range h {
+		if snapshot.Date.After(date) {
+			break
+		}
+		for item, price := range snapshot.Prices {
+			prices[item] = price
+		}
+	}
+	return prices
+}
+
+// Dates 取得 from 至 to（含）之間有快照的日期，零值表示不限
+func (h PriceHistory) Dates(from, to time.Time) []time.Time {
+	var dates []time.Time
+	for _, snapshot := range h {
+		if !from.IsZero() && snapshot.Date.Before(TruncateDate(from)) {
+			continue
+		}
+		if !to.IsZero() && snapshot.Date.After(TruncateDate(to)) {
+			continue
+		}
+		dates = append(dates, snapshot.Date)
+	}
+	return dates
+}
+
+// Validate 檢查各快照的價格
+func (h PriceHistory) Validate() error {
+	for _, snapshot := range h {
+		if err := snapshot.Prices.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 抽取紀錄的獎池代號
+const (
+	PoolZodiac = "zodiac" // 新年氣息生肖
+	PoolStage1 = "stage1" // 星光錦囊第一階段，第2階段起為 stage2、stage3…
+)
+
+// StagePoolID 取得星光錦囊指定階段的獎池代號
+func StagePoolID(stage int) string {
+	return fmt.Sprintf("stage%d", stage)
+}
+
+// PoolIDs 活動中所有可檢定的獎池代號（新年氣息、星光錦囊第一階段與各階梯階段）
+func (e EventDefinition) PoolIDs() []string {
+	ids := []string{PoolZodiac, PoolStage1}
+	for _, stage := range e.Starlight.Stages() {
+		ids = append(ids, StagePoolID(stage))
+	}
+	return ids
+}
+
+// PoolByID 依獎池代號取得顯示名稱與獎池
+func (e EventDefinition) PoolByID(id string) (string, []Reward, error) {
+	switch {
+	case id == PoolZodiac:
+		return "新年氣息", e.Zodiac.Pool(), nil
+	case id == PoolStage1:
+		return StageName(1), e.Starlight.Stage1Pool, nil
+	case strings.HasPrefix(id, "stage"):
+		stage, err := strconv.Atoi(strings.TrimPrefix(id, "stage"))
+		if pool, ok := e.Starlight.StagePools[stage]; err == nil && ok {
+			return StageName(stage), pool, nil
+		}
+	}
+	return "", nil, fmt.Errorf("未知的獎池 %q（可用：%s）", id, strings.Join(e.PoolIDs(), ", "))
+}
+
+// PullSession 一次開啟紀錄：獎池與各道具抽到的數量
+type PullSession struct {
+	Name   string         // 紀錄名稱（如日期或玩家）
+	Pool   string         // 獎池代號
+	Counts map[string]int // 道具名稱 -> 數量
+}
+
+// PullLog 抽取紀錄
+type PullLog []PullSession
+
+// Validate 檢查抽取紀錄（獎池代號不可為空、數量不可為負）
+func (l PullLog) Validate() error {
+	if len(l) == 0 {
+		return errors.New("抽取紀錄為空")
+	}
+	for _, session := range l {
+		if session.Pool == "" {
+			return fmt.Errorf("紀錄 %q 未指定獎池", session.Name)
+		}
+		for item, count := range session.Counts {
+			if count < 0 {
+				return fmt.Errorf("紀錄 %q 的道具 %q 數量不可為負", session.Name, item)
+			}
+		}
+	}
+	for _, pool := range l.Pools() {
+		if _, err := l.Draws(pool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Draws 合計指定獎池的總抽數，數量為負或合計超出整數範圍時回傳錯誤
+func (l PullLog) Draws(pool string) (int, error) {
+	total := 0
+	for _, session := range l {
+		if session.Pool != pool {
+			continue
+		}
+		for item, count := range session.Counts {
+			if count < 0 {
+				return 0, fmt.Errorf("紀錄 %q 的道具 %q 數量不可為負", session.Name, item)
+			}
+			if count > math.MaxInt-total {
+				return 0, fmt.Errorf("獎池 %q 的紀錄總抽數超出上限", pool)
+			}
+			total += count
+		}
+	}
+	return total, nil
+}
+
+// Pools 紀錄中出現的獎池代號（依首次出現順序）
+func (l PullLog) Pools() []string {
+	var pools []string
+	seen := make(map[string]bool)
+	for _, session := range l {
+		if !seen[session.Pool] {
+			seen[session.Pool] = true
+			pools = append(pools, session.Pool)
+		}
+	}
+	return pools
+}
+
+// Totals 合計指定獎池的各道具數量，並回傳紀錄筆數
+func (l PullLog) Totals(pool string) (map[string]int, int) {
+	totals := make(map[string]int)
+	sessions := 0
+	for _, session := range l {
+		if session.Pool != pool {
+			continue
+		}
+		sessions++
+		for item, count := range session.Counts {
+			totals[item] += count
+		}
+	}
+	return totals, sessions
+}
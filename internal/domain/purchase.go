@@ -1,6 +1,11 @@
 package domain
 
-import "math"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
 
 // PurchaseMethod 購買方式
 type PurchaseMethod string
@@ -12,31 +17,432 @@ const (
 	MethodGift       PurchaseMethod = "gift"       // 送禮
 )
 
-// CostPerDraw 每抽成本（點數）
-const CostPerDraw = 27.0
-
-// CalculatePoints 根據購買方式計算可得點數
-func CalculatePoints(investment float64, method PurchaseMethod, discount float64) float64 {
-	switch method {
-	case MethodCard:
-		// 點卡 xx 折：投入金額 ÷ 折數（四捨五入）
-		if discount > 0 {
-			return math.Round(investment / discount)
-		}
-		return investment
-	case MethodCardReader:
-		// 讀卡機 5% 回饋
-		return investment * 1.05
-	case MethodOriginal:
-		// 原價
-		return investment
-	case MethodGift:
-		// 送禮 xx 折：投入金額 ÷ 折數
-		if discount > 0 {
-			return math.Round(investment / discount)
-		}
-		return investment
+// Rounding 點數進位方式
+type Rounding string
+
+const (
+	RoundNone    Rounding = "none"  // 不進位
+	RoundNearest Rounding = "round" // 四捨五入
+	RoundDown    Rounding = "floor" // 無條件捨去
+	RoundUp      Rounding = "ceil"  // 無條件進位
+)
+
+// Apply 依進位方式處理點數
+func (r Rounding) Apply(points float64) float64 {
+	switch r {
+	case RoundNearest:
+		return math.Round(points)
+	case RoundDown:
+		return math.Floor(points)
+	case RoundUp:
+		return math.Ceil(points)
+	default:
+		return points
+	}
+}
+
+// Denomination 點卡面額
+type Denomination struct {
+	Price  int     // 售價（台幣，折扣前）
+	Points float64 // 面額點數
+	Bonus  float64 // 額外贈送點數
+	Limit  int     // 購買數量上限，0 表示不限
+}
+
+// TotalPoints 一張點卡可得的點數（含贈送）
+func (d Denomination) TotalPoints() float64 {
+	return d.Points + d.Bonus
+}
+
+// PurchaseRule 購買方式規則
+type PurchaseRule struct {
+	Method        PurchaseMethod
+	Name          string
+	Denominations []Denomination // 點卡面額，空表示可購買任意金額
+	UseDiscount   bool           // 是否套用使用者輸入的折數
+	BonusRate     float64        // 依點數的額外回饋比例（如讀卡機 0.05）
+	Rounding      Rounding       // 點數進位方式
+	MaxInvestment float64        // 單次購買金額上限，0 表示不限
+}
+
+// CardCount 購買的點卡與張數
+type CardCount struct {
+	Denomination
+	Count int
+}
+
+// Purchase 購買結果
+type Purchase struct {
+	Method   PurchaseMethod
+	Cards    []CardCount // 使用的點卡（可購買任意金額時為空）
+	Cost     float64     // 實際花費（台幣）
+	Points   float64     // 可得點數
+	Unspent  float64     // 投入金額中未使用的金額
+	Leftover float64     // 超出所需點數的點數（反推所需金額時）
+}
+
+// discount 取得實際套用的折數
+func (r PurchaseRule) discount(discount float64) float64 {
+	if r.UseDiscount && discount > 0 {
+		return discount
+	}
+	return 1
+}
+
+// points 由點卡點數套用回饋與進位
+func (r PurchaseRule) points(raw float64) float64 {
+	return r.Rounding.Apply(raw * (1 + r.BonusRate))
+}
+
+// Buy 以投入金額購買，回傳可得點數最多的購買結果（點數相同時取花費較低者）
+func (r PurchaseRule) Buy(investment float64, discount float64) Purchase {
+	d := r.discount(discount)
+	budget := math.Max(investment, 0)
+	if r.MaxInvestment > 0 {
+		budget = math.Min(budget, r.MaxInvestment)
+	}
+
+	purchase := Purchase{Method: r.Method}
+	if len(r.Denominations) == 0 {
+		// 可購買任意金額：投入金額 ÷ 折數
+		purchase.Cost = budget
+		purchase.Points = r.points(budget / d)
+	} else {
+		// 點卡面額：在預算內求點數最多的組合
+		cards := r.bestCards(int(math.Floor(budget/d+1e-9)), nil)
+		purchase.Cards = cards
+		raw, price := cardTotals(cards)
+		purchase.Cost = float64(price) * d
+		purchase.Points = r.points(raw)
+	}
+	purchase.Unspent = math.Max(investment-purchase.Cost, 0)
+	return purchase
+}
+
+// Cheapest 求取得指定點數的最低花費購買方式，並回傳超出所需的點數
+func (r PurchaseRule) Cheapest(points float64, discount float64) (Purchase, error) {
+	if points <= 0 {
+		return Purchase{Method: r.Method}, nil
+	}
+
+	var purchase Purchase
+	if len(r.Denominations) == 0 {
+		purchase = r.Buy(r.requiredInvestment(points, discount), discount)
+	} else {
+		cards, err := r.cheapestCards(points, discount)
+		if err != nil {
+			return Purchase{}, err
+		}
+		raw, price := cardTotals(cards)
+		purchase = Purchase{
+			Method: r.Method,
+			Cards:  cards,
+			Cost:   float64(price) * r.discount(discount),
+			Points: r.points(raw),
+		}
+	}
+
+	if purchase.Points < points {
+		return Purchase{}, fmt.Errorf("%s 單次購買無法取得 %.0f 點", r.Name, points)
+	}
+	purchase.Leftover = purchase.Points - points
+	return purchase, nil
+}
+
+// requiredInvestment 可購買任意金額時，取得指定點數所需的最低投入金額（整數元）
+func (r PurchaseRule) requiredInvestment(points float64, discount float64) float64 {
+	// 先倍增找出足夠的上限，再以二分搜尋找出最低金額
+	upper := math.Ceil(points)
+	for r.Buy(upper, discount).Points < points {
+		if r.MaxInvestment > 0 && upper >= r.MaxInvestment {
+			return r.MaxInvestment
+		}
+		upper *= 2
+	}
+
+	lower := 0.0
+	for upper-lower > 1 {
+		mid := math.Floor((lower + upper) / 2)
+		if r.Buy(mid, discount).Points >= points {
+			upper = mid
+		} else {
+			lower = mid
+		}
+	}
+	return upper
+}
+
+// maxCardStates 點卡組合搜尋的狀態數上限
+const maxCardStates = 1000000
+
+// bestCards 在售價總和不超過 budget 的組合中，求點數（含贈送）最多者，點數相同取售價較低者；
+// enough 不為 nil 時改為求點數滿足 enough 的最低售價組合（無解時回傳 nil）
+func (r PurchaseRule) bestCards(budget int, enough func(raw float64) bool) []CardCount {
+	// 所有面額皆有張數上限時，超過全部點卡售價的預算用不到
+	if _, ok := r.bestRatio(); !ok {
+		budget = min(budget, r.limitedCost())
+	}
+	if budget <= 0 {
+		return nil
+	}
+
+	// 以所有面額售價的最大公因數為單位，降低狀態數
+	unit := 0
+	for _, d := range r.Denominations {
+		unit = gcd(unit, d.Price)
+	}
+	states := budget / unit
+
+	// 預算過大時，先以點數/售價比最佳的不限張數面額填滿超出的部分
+	if states > maxCardStates {
+		if i, ok := r.bestRatio(); ok {
+			d := r.Denominations[i]
+			n := (states-maxCardStates)*unit/d.Price + 1
+			prefill := float64(n) * d.TotalPoints()
+			var rest func(raw float64) bool
+			if enough != nil {
+				if enough(prefill) {
+					return []CardCount{{Denomination: d, Count: n}}
+				}
+				rest = func(raw float64) bool { return enough(raw + prefill) }
+			}
+			return addCards(r.bestCards(budget-n*d.Price, rest), d, n)
+		}
+	}
+
+	// 有張數上限的面額拆成 1, 2, 4…張的組別（二進位拆分），每組至多選一次
+	groups := cardGroups(r.Denominations)
+
+	// best[c]：售價恰為 c 個單位時的最多點數；take[i][c]：第 i 組使用的張數
+	best := make([]float64, states+1)
+	for c := 1; c <= states; c++ {
+		best[c] = math.Inf(-1)
+	}
+	take := make([][]int, len(groups))
+
+	for i, g := range groups {
+		step := g.Price / unit
+		take[i] = make([]int, states+1)
+		next := make([]float64, states+1)
+		copy(next, best)
+
+		if g.Count == 0 {
+			// 不限張數：由 next[c-step] 再加一張
+			for c := step; c <= states; c++ {
+				if v := next[c-step] + g.TotalPoints(); v > next[c] {
+					next[c] = v
+					take[i][c] = take[i][c-step] + 1
+				}
+			}
+		} else {
+			// 固定張數的組別：選或不選
+			size := g.Count * step
+			for c := size; c <= states; c++ {
+				if v := best[c-size] + float64(g.Count)*g.TotalPoints(); v > next[c] {
+					next[c] = v
+					take[i][c] = g.Count
+				}
+			}
+		}
+		best = next
+	}
+
+	// 選出最佳的總售價
+	chosen := -1
+	if enough == nil {
+		chosen = 0
+		for c := 1; c <= states; c++ {
+			if best[c] > best[chosen] {
+				chosen = c
+			}
+		}
+	} else {
+		for c := 0; c <= states; c++ {
+			if !math.IsInf(best[c], -1) && enough(best[c]) {
+				chosen = c
+				break
+			}
+		}
+		if chosen < 0 {
+			return nil
+		}
+	}
+
+	// 由最後一組往回還原張數
+	var cards []CardCount
+	c := chosen
+	for i := len(groups) - 1; i >= 0; i-- {
+		if count := take[i][c]; count > 0 {
+			cards = addCards(cards, groups[i].Denomination, count)
+			c -= count * groups[i].Price / unit
+		}
+	}
+	return cards
+}
+
+// cardGroups 將面額轉為背包問題的組別：不限張數的面額 Count 為 0，
+// 有上限的面額拆成 1, 2, 4…張與剩餘張數，任意張數皆可由各組的選或不選組成
+func cardGroups(denominations []Denomination) []CardCount {
+	var groups []CardCount
+	for _, d := range denominations {
+		if d.Limit == 0 {
+			groups = append(groups, CardCount{Denomination: d})
+			continue
+		}
+		for size, left := 1, d.Limit; left > 0; size *= 2 {
+			size = min(size, left)
+			groups = append(groups, CardCount{Denomination: d, Count: size})
+			left -= size
+		}
+	}
+	return groups
+}
+
+// limitedCost 全部有張數上限的點卡買滿時的售價總和
+func (r PurchaseRule) limitedCost() int {
+	var cost int
+	for _, d := range r.Denominations {
+		cost += d.Limit * d.Price
+	}
+	return cost
+}
+
+// bestRatio 取得點數/售價比最佳的不限張數面額
+func (r PurchaseRule) bestRatio() (int, bool) {
+	index := -1
+	for i, d := range r.Denominations {
+		if d.Limit != 0 {
+			continue
+		}
+		if index < 0 || d.TotalPoints()/float64(d.Price) > r.Denominations[index].TotalPoints()/float64(r.Denominations[index].Price) {
+			index = i
+		}
+	}
+	return index, index >= 0
+}
+
+// cheapestCards 求點數達到 points 的最低售價點卡組合
+func (r PurchaseRule) cheapestCards(points float64, discount float64) ([]CardCount, error) {
+	enough := func(raw float64) bool {
+		return r.points(raw) >= points
+	}
+
+	// 售價上限：只用點數/售價比最佳的不限張數面額時的花費，若皆有上限則為全部點卡的售價
+	var bound int
+	if i, ok := r.bestRatio(); ok {
+		d := r.Denominations[i]
+		bound = int(math.Ceil(points/(1+r.BonusRate)/d.TotalPoints())+1) * d.Price
+	} else {
+		bound = r.limitedCost()
+	}
+	if r.MaxInvestment > 0 {
+		bound = min(bound, int(math.Floor(r.MaxInvestment/r.discount(discount)+1e-9)))
+	}
+
+	cards := r.bestCards(bound, enough)
+	if cards == nil {
+		return nil, fmt.Errorf("%s 單次購買無法取得 %.0f 點", r.Name, points)
+	}
+	return cards, nil
+}
+
+// addCards 將點卡加入組合（同面額合併），並依售價由高到低排序
+func addCards(cards []CardCount, d Denomination, count int) []CardCount {
+	for i := range cards {
+		if cards[i].Price == d.Price && cards[i].Points == d.Points && cards[i].Bonus == d.Bonus {
+			cards[i].Count += count
+			return cards
+		}
+	}
+	cards = append(cards, CardCount{Denomination: d, Count: count})
+	sort.Slice(cards, func(i, j int) bool { return cards[i].Price > cards[j].Price })
+	return cards
+}
+
+// Validate 檢查購買方式規則
+func (r PurchaseRule) Validate() error {
+	if r.Method == "" {
+		return errors.New("購買方式代號不可為空")
+	}
+	switch r.Rounding {
+	case "", RoundNone, RoundNearest, RoundDown, RoundUp:
 	default:
-		return investment
+		return fmt.Errorf("購買方式 %s 的進位方式 %q 無效", r.Method, r.Rounding)
+	}
+	if r.BonusRate < 0 || r.MaxInvestment < 0 {
+		return fmt.Errorf("購買方式 %s 的回饋比例與金額上限不可為負", r.Method)
+	}
+	for _, d := range r.Denominations {
+		if d.Price <= 0 || d.Points <= 0 || d.Bonus < 0 || d.Limit < 0 {
+			return fmt.Errorf("購買方式 %s 的點卡面額 %d 元設定無效", r.Method, d.Price)
+		}
+	}
+	return nil
+}
+
+// cardTotals 計算點卡組合的點數（含贈送）與售價總和
+func cardTotals(cards []CardCount) (points float64, price int) {
+	for _, card := range cards {
+		points += float64(card.Count) * card.TotalPoints()
+		price += card.Count * card.Price
+	}
+	return points, price
+}
+
+// gcd 最大公因數
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// PurchaseRegistry 購買方式規則表
+type PurchaseRegistry map[PurchaseMethod]PurchaseRule
+
+// DefaultPurchaseRegistry 預設購買方式（可購買任意金額）
+func DefaultPurchaseRegistry() PurchaseRegistry {
+	return PurchaseRegistry{
+		MethodCard:       {Method: MethodCard, Name: "點卡儲值", UseDiscount: true, Rounding: RoundNearest},
+		MethodCardReader: {Method: MethodCardReader, Name: "讀卡機", BonusRate: 0.05, Rounding: RoundNone},
+		MethodOriginal:   {Method: MethodOriginal, Name: "原價", Rounding: RoundNone},
+		MethodGift:       {Method: MethodGift, Name: "送禮", UseDiscount: true, Rounding: RoundNearest},
+	}
+}
+
+// Register 註冊或覆寫購買方式
+func (r PurchaseRegistry) Register(rule PurchaseRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	r[rule.Method] = rule
+	return nil
+}
+
+// Rule 取得購買方式規則，未知的購買方式視為原價
+func (r PurchaseRegistry) Rule(method PurchaseMethod) PurchaseRule {
+	if rule, ok := r[method]; ok {
+		return rule
 	}
+	return PurchaseRule{Method: method, Name: string(method), Rounding: RoundNone}
+}
+
+// Methods 取得所有購買方式（依代號排序）
+func (r PurchaseRegistry) Methods() []PurchaseMethod {
+	methods := make([]PurchaseMethod, 0, len(r))
+	for method := range r {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i] < methods[j] })
+	return methods
+}
+
+// Buy 以指定購買方式投入金額
+func (r PurchaseRegistry) Buy(investment float64, method PurchaseMethod, discount float64) Purchase {
+	return r.Rule(method).Buy(investment, discount)
+}
+
+// Cheapest 以指定購買方式取得指定點數的最低花費
+func (r PurchaseRegistry) Cheapest(points float64, method PurchaseMethod, discount float64) (Purchase, error) {
+	return r.Rule(method).Cheapest(points, discount)
 }
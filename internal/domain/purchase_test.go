@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// bruteForceCards 列舉所有張數組合，求售價不超過 budget 時點數最多、點數相同時售價最低的組合
+func bruteForceCards(denominations []Denomination, budget int) (float64, int) {
+	bestPoints, bestPrice := 0.0, 0
+	var search func(i, price int, points float64)
+	search = func(i, price int, points float64) {
+		if price > budget {
+			return
+		}
+		if i == len(denominations) {
+			if points > bestPoints || (points == bestPoints && price < bestPrice) {
+				bestPoints, bestPrice = points, price
+			}
+			return
+		}
+		limit := denominations[i].Limit
+		if limit == 0 {
+			limit = budget / denominations[i].Price
+		}
+		for k := 0; k <= limit; k++ {
+			search(i+1, price+k*denominations[i].Price, points+float64(k)*denominations[i].TotalPoints())
+		}
+	}
+	search(0, 0, 0)
+	return bestPoints, bestPrice
+}
+
+func TestBestCardsMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		var denominations []Denomination
+		for j := 0; j < 1+rng.Intn(3); j++ {
+			denominations = append(denominations, Denomination{
+				Price:  50 * (1 + rng.Intn(6)),
+				Points: float64(10 * (1 + rng.Intn(40))),
+				Bonus:  float64(rng.Intn(3) * 5),
+				Limit:  rng.Intn(4),
+			})
+		}
+		rule := PurchaseRule{Method: "test", Denominations: denominations}
+		budget := rng.Intn(1500)
+
+		points, price := cardTotals(rule.bestCards(budget, nil))
+		wantPoints, wantPrice := bruteForceCards(denominations, budget)
+		if points != wantPoints || price != wantPrice {
+			t.Fatalf("面額 %+v、預算 %d：bestCards = %g 點 %d 元, want %g 點 %d 元",
+				denominations, budget, points, price, wantPoints, wantPrice)
+		}
+	}
+}
+
+func TestBestCardsLimitedDenominationsLargeBudget(t *testing.T) {
+	// 所有面額皆有上限時，超過全部點卡售價的預算不會展開狀態
+	rule := PurchaseRule{Method: "test", Denominations: []Denomination{
+		{Price: 1, Points: 1, Limit: 3},
+		{Price: 7, Points: 9, Limit: 1000},
+	}}
+	points, price := cardTotals(rule.bestCards(1_000_000_000_000, nil))
+	if points != 9003 || price != 7003 {
+		t.Errorf("bestCards = %g 點 %d 元, want 9003 點 7003 元", points, price)
+	}
+}
+
+func TestCardGroups(t *testing.T) {
+	for _, limit := range []int{1, 2, 5, 7, 8, 100} {
+		groups := cardGroups([]Denomination{{Price: 10, Points: 10, Limit: limit}})
+
+		// 各組選或不選可組成 0 ~ limit 的所有張數
+		reachable := map[int]bool{0: true}
+		for _, group := range groups {
+			next := make(map[int]bool)
+			for count := range reachable {
+				next[count] = true
+				next[count+group.Count] = true
+			}
+			reachable = next
+		}
+		for count := 0; count <= limit; count++ {
+			if !reachable[count] {
+				t.Errorf("上限 %d：無法組成 %d 張", limit, count)
+			}
+		}
+		if len(reachable) != limit+1 {
+			t.Errorf("上限 %d：可組成的張數超過上限 %v", limit, reachable)
+		}
+	}
+}
+
+func TestCheapest(t *testing.T) {
+	rule := PurchaseRule{Method: "test", Name: "測試", Denominations: []Denomination{
+		{Price: 1000, Points: 1000},
+		{Price: 3000, Points: 3000, Bonus: 300},
+	}}
+
+	tests := []struct {
+		name   string
+		points float64
+		cost   float64
+	}{
+		{"單張小面額", 900, 1000},
+		{"大面額含贈送較划算", 3300, 3000},
+		{"組合面額", 4200, 4000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purchase, err := rule.Cheapest(tt.points, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if purchase.Cost != tt.cost || purchase.Points < tt.points {
+				t.Errorf("Cheapest(%g) = %g 元 %g 點, want %g 元", tt.points, purchase.Cost, purchase.Points, tt.cost)
+			}
+		})
+	}
+}
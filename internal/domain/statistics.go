@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"math"
+	"sort"
+)
+
+// SummaryPercentiles 統計摘要提供的百分位數
+var SummaryPercentiles = []int{5, 25, 50, 75, 95}
+
+// Summary 樣本統計摘要
+type Summary struct {
+	Mean        float64
+	Variance    float64
+	StdDev      float64
+	Min         float64
+	Max         float64
+	Median      float64
+	Percentiles map[int]float64 // 百分位數 -> 數值
+}
+
+// Summarize 計算樣本的統計摘要（會排序傳入的樣本）
+func Summarize(samples []float64) Summary {
+	summary := Summary{Percentiles: make(map[int]float64)}
+	if len(samples) == 0 {
+		return summary
+	}
+
+	sort.Float64s(samples)
+
+	var sum float64
+	for _, v := range samples {
+		sum += v
+	}
+	mean := sum / float64(len(samples))
+
+	var squares float64
+	for _, v := range samples {
+		squares += (v - mean) * (v - mean)
+	}
+	variance := squares / float64(len(samples))
+
+	summary.Mean = mean
+	summary.Variance = variance
+	summary.StdDev = math.Sqrt(variance)
+	summary.Min = samples[0]
+	summary.Max = samples[len(samples)-1]
+	summary.Median = Percentile(samples, 50)
+	for _, p := range SummaryPercentiles {
+		summary.Percentiles[p] = Percentile(samples, p)
+	}
+	return summary
+}
+
+// Percentile 計算已排序樣本的百分位數（線性內插）
+func Percentile(sorted []float64, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := float64(p) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
+
+// PercentileRank 計算 x 在樣本中的百分位 (%)：低於 x 的比例加上等於 x 的比例的一半
+// 同時回傳高於與低於 x 的樣本比例 (%)
+func PercentileRank(samples []float64, x float64) (rank, above, below float64) {
+	if len(samples) == 0 {
+		return 0, 0, 0
+	}
+	var lower, equal int
+	for _, v := range samples {
+		switch {
+		case v < x:
+			lower++
+		case v == x:
+			equal++
+		}
+	}
+	n := float64(len(samples))
+	upper := len(samples) - lower - equal
+	return (float64(lower) + float64(equal)/2) / n * 100, float64(upper) / n * 100, float64(lower) / n * 100
+}
@@ -0,0 +1,242 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// probabilityTolerance 機率總和允許的浮點誤差 (%)
+const probabilityTolerance = 1e-6
+
+// Severity 驗證問題嚴重程度
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 錯誤：資料無法使用
+	SeverityWarning Severity = "warning" // 警告：資料可用但可能有誤
+)
+
+// ValidationIssue 驗證問題
+type ValidationIssue struct {
+	Severity Severity
+	Pool     string // 獎池名稱
+	Item     string // 相關道具（可為空）
+	Message  string
+}
+
+// PoolSummary 獎池機率摘要
+type PoolSummary struct {
+	Pool      string  // 獎池名稱
+	ItemCount int     // 道具數量
+	Sum       float64 // 機率總和 (%)
+}
+
+// ValidationReport 驗證報告
+type ValidationReport struct {
+	Pools  []PoolSummary
+	Issues []ValidationIssue
+}
+
+// HasErrors 是否包含錯誤
+func (r ValidationReport) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors 取得所有錯誤
+func (r ValidationReport) Errors() []ValidationIssue {
+	var errs []ValidationIssue
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+// merge 合併另一份驗證報告
+func (r *ValidationReport) merge(other ValidationReport) {
+	r.Pools = append(r.Pools, other.Pools...)
+	r.Issues = append(r.Issues, other.Issues...)
+}
+
+// addIssue 新增驗證問題
+func (r *ValidationReport) addIssue(severity Severity, pool, item, format string, args ...any) {
+	r.Issues = append(r.Issues, ValidationIssue{
+		Severity: severity,
+		Pool:     pool,
+		Item:     item,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// String 格式化驗證問題
+func (i ValidationIssue) String() string {
+	if i.Item != "" {
+		return fmt.Sprintf("[%s] %s / %s: %s", i.Severity, i.Pool, i.Item, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", i.Severity, i.Pool, i.Message)
+}
+
+// ValidatePool 驗證單一獎池：機率總和、重複名稱與負機率
+func ValidatePool(name string, pool []Reward) ValidationReport {
+	var report ValidationReport
+
+	if len(pool) == 0 {
+		report.addIssue(SeverityError, name, "", "獎池為空")
+	}
+
+	seen := make(map[string]bool)
+	var sum float64
+	for _, reward := range pool {
+		if reward.Name == "" {
+			report.addIssue(SeverityError, name, "", "道具名稱為空")
+		}
+		if seen[reward.Name] {
+			report.addIssue(SeverityError, name, reward.Name, "道具名稱重複")
+		}
+		seen[reward.Name] = true
+
+		if reward.Probability < 0 {
+			report.addIssue(SeverityError, name, reward.Name, "機率為負數 (%.4f%%)", reward.Probability)
+		} else if reward.Probability == 0 {
+			report.addIssue(SeverityWarning, name, reward.Name, "機率為 0，永遠不會抽到")
+		}
+		sum += reward.Probability
+	}
+
+	if len(pool) > 0 && math.Abs(sum-100) > probabilityTolerance {
+		report.addIssue(SeverityError, name, "", "機率總和為 %.4f%%，應為 100%%", sum)
+	}
+
+	report.Pools = append(report.Pools, PoolSummary{Pool: name, ItemCount: len(pool), Sum: sum})
+	return report
+}
+
+// Validate 驗證整份活動定義
+func (e EventDefinition) Validate() ValidationReport {
+	var report ValidationReport
+	report.merge(e.Zodiac.Validate())
+	report.merge(e.Starlight.Validate())
+	return report
+}
+
+// Validate 驗證星光錦囊定義：各階段獎池、升級道具與階段可達性
+func (e StarlightEvent) Validate() ValidationReport {
+	var report ValidationReport
+	const eventName = "星光錦囊"
+
+	if e.CostPerDraw <= 0 {
+		report.addIssue(SeverityError, eventName, "", "每抽成本必須大於 0")
+	}
+	if e.CrystalsPerMerge <= 0 {
+		report.addIssue(SeverityError, eventName, "", "合成數量必須大於 0")
+	}
+
+	report.merge(ValidatePool(StageName(1), e.Stage1Pool))
+
+	// 依階段順序驗證
+	stages := e.Stages()
+	for _, stage := range stages {
+		report.merge(ValidatePool(StageName(stage), e.StagePools[stage]))
+	}
+
+	// 階段必須從第2階段起連續
+	for i, stage := range stages {
+		if stage != i+2 {
+			report.addIssue(SeverityError, StageName(stage), "", "階段編號不連續（預期第%d階段）", i+2)
+			break
+		}
+	}
+
+	// 第2階段由第一階段的玲瓏星光進入
+	reachable := false
+	if e.CrystalItem == "" {
+		report.addIssue(SeverityError, StageName(1), "", "未設定進入階梯的道具")
+	} else if Probability(e.Stage1Pool, e.CrystalItem) <= 0 {
+		report.addIssue(SeverityError, StageName(1), e.CrystalItem, "進入階梯的道具不在獎池中")
+	} else {
+		reachable = true
+	}
+
+	for _, stage := range stages {
+		if !reachable {
+			report.addIssue(SeverityError, StageName(stage), "", "無法到達此階段")
+			continue
+		}
+
+		upgrade, hasUpgrade := e.UpgradeItems[stage]
+		_, hasNext := e.StagePools[stage+1]
+
+		switch {
+		case hasNext && !hasUpgrade:
+			report.addIssue(SeverityError, StageName(stage), "", "缺少升級道具設定，無法進入第%d階段", stage+1)
+			reachable = false
+		case hasUpgrade && !hasNext:
+			report.addIssue(SeverityError, StageName(stage), upgrade, "升級道具沒有對應的第%d階段獎池", stage+1)
+			reachable = false
+		case hasUpgrade && Probability(e.StagePools[stage], upgrade) <= 0:
+			report.addIssue(SeverityError, StageName(stage), upgrade, "升級道具不在獎池中")
+			reachable = false
+		}
+	}
+
+	// 升級道具對應的階段不存在
+	for stage, upgrade := range e.UpgradeItems {
+		if _, ok := e.StagePools[stage]; !ok {
+			report.addIssue(SeverityError, StageName(stage), upgrade, "升級道具所屬階段不存在")
+		}
+	}
+
+	return report
+}
+
+// Validate 驗證新年氣息定義：生肖機率與心願箱組成
+func (e ZodiacEvent) Validate() ValidationReport {
+	var report ValidationReport
+	const poolName = "新年氣息"
+
+	if e.CostPerDraw <= 0 {
+		report.addIssue(SeverityError, poolName, "", "每抽成本必須大於 0")
+	}
+
+	// 生肖機率與獎池使用相同規則驗證
+	report.merge(ValidatePool(poolName, e.Pool()))
+
+	for _, boxType := range e.BoxPriority {
+		requirements, ok := e.BoxRequirements[boxType]
+		if !ok || len(requirements) == 0 {
+			report.addIssue(SeverityError, poolName, string(boxType), "心願箱缺少組成設定")
+			continue
+		}
+		for _, zodiac := range requirements {
+			if e.Rates[zodiac] <= 0 {
+				report.addIssue(SeverityError, poolName, string(boxType), "所需生肖「%s」無法抽到", zodiac)
+			}
+		}
+	}
+
+	for boxType := range e.BoxRequirements {
+		found := false
+		for _, b := range e.BoxPriority {
+			if b == boxType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report.addIssue(SeverityWarning, poolName, string(boxType), "心願箱未列入優先順序，將不會被計算")
+		}
+	}
+
+	return report
+}
+
+// StageName 取得階段顯示名稱
+func StageName(stage int) string {
+	return fmt.Sprintf("星光錦囊 第%d階段", stage)
+}
@@ -24,22 +24,6 @@ var AllZodiacs = []Zodiac{
 	Rat, Ox, Tiger, Rabbit, Dragon, Snake,
 }
 
-// ZodiacRates 生肖機率 (%)
-var ZodiacRates = map[Zodiac]float64{
-	Horse:   0.15,
-	Goat:    0.20,
-	Monkey:  0.25,
-	Rooster: 0.80,
-	Dog:     0.90,
-	Pig:     1.00,
-	Rat:     2.50,
-	Ox:      3.00,
-	Tiger:   3.50,
-	Rabbit:  29.20,
-	Dragon:  29.25,
-	Snake:   29.25,
-}
-
 // BreathCollection 氣息收集（各生肖的數量）
 type BreathCollection map[Zodiac]float64
 
@@ -57,6 +41,15 @@ func (bc BreathCollection) Clone() BreathCollection {
 	return clone
 }
 
+// Add 合併另一份氣息收集，回傳新的氣息收集
+func (bc BreathCollection) Add(other BreathCollection) BreathCollection {
+	sum := bc.Clone()
+	for k, v := range other {
+		sum[k] += v
+	}
+	return sum
+}
+
 // Min 取得指定生肖中的最小數量
 func (bc BreathCollection) Min(zodiacs []Zodiac) float64 {
 	min := bc[zodiacs[0]]
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultEventVersion 內嵌的預設活動資料版本
+const DefaultEventVersion = "2026-01"
+
+//go:embed events/*.json
+var eventFiles embed.FS
+
+// eventFile 活動定義檔格式
+type eventFile struct {
+	Version   string        `json:"version"`
+	Name      string        `json:"name"`
+	Zodiac    zodiacFile    `json:"zodiac"`
+	Starlight starlightFile `json:"starlight"`
+}
+
+// zodiacFile 新年氣息定義檔格式
+type zodiacFile struct {
+	CostPerDraw     float64             `json:"cost_per_draw"`
+	Rates           map[string]float64  `json:"rates"`
+	BoxRequirements map[string][]string `json:"box_requirements"`
+	BoxPriority     []string            `json:"box_priority"`
+}
+
+// starlightFile 星光錦囊定義檔格式
+type starlightFile struct {
+	CostPerDraw      int                  `json:"cost_per_draw"`
+	CrystalItem      string               `json:"crystal_item"`
+	CrystalsPerMerge int                  `json:"crystals_per_merge"`
+	MergedItem       string               `json:"merged_item"`
+	Stage1Pool       []rewardFile         `json:"stage1_pool"`
+	StagePools       map[int][]rewardFile `json:"stage_pools"`
+	UpgradeItems     map[int]string       `json:"upgrade_items"`
+	ValuableItems    []string             `json:"valuable_items"`
+	ZeroValueItems   []string             `json:"zero_value_items"`
+}
+
+// rewardFile 獎品定義檔格式
+type rewardFile struct {
+	Name        string  `json:"name"`
+	Probability float64 `json:"probability"`
+	MarketPrice int     `json:"market_price,omitempty"`
+}
+
+// LoadEvent 載入並驗證活動定義，path 為空時使用內嵌的預設版本
+func LoadEvent(path string) (domain.EventDefinition, error) {
+	event, err := ReadEvent(path)
+	if err != nil {
+		return domain.EventDefinition{}, err
+	}
+	if err := checkEvent(event); err != nil {
+		return domain.EventDefinition{}, err
+	}
+	return event, nil
+}
+
+// ReadEvent 讀取活動定義但不驗證（供驗證工具使用），path 為空時使用內嵌的預設版本
+func ReadEvent(path string) (domain.EventDefinition, error) {
+	if path == "" {
+		return readEmbeddedEvent(DefaultEventVersion)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.EventDefinition{}, fmt.Errorf("讀取活動定義檔失敗: %w", err)
+	}
+	return decodeEvent(data)
+}
+
+// LoadEmbeddedEvent 載入並驗證內嵌的指定版本活動定義
+func LoadEmbeddedEvent(version string) (domain.EventDefinition, error) {
+	event, err := readEmbeddedEvent(version)
+	if err != nil {
+		return domain.EventDefinition{}, err
+	}
+	if err := checkEvent(event); err != nil {
+		return domain.EventDefinition{}, err
+	}
+	return event, nil
+}
+
+// readEmbeddedEvent 讀取內嵌的指定版本活動定義
+func readEmbeddedEvent(version string) (domain.EventDefinition, error) {
+	data, err := eventFiles.ReadFile(path.Join("events", version+".json"))
+	if err != nil {
+		return domain.EventDefinition{}, fmt.Errorf("找不到內嵌活動版本 %q: %w", version, err)
+	}
+	return decodeEvent(data)
+}
+
+// EmbeddedEventVersions 列出所有內嵌的活動版本
+func EmbeddedEventVersions() []string {
+	entries, _ := eventFiles.ReadDir("events")
+	var versions []string
+	for _, entry := range entries {
+		versions = append(versions, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// ParseEvent 解析並驗證活動定義 JSON
+func ParseEvent(data []byte) (domain.EventDefinition, error) {
+	event, err := decodeEvent(data)
+	if err != nil {
+		return domain.EventDefinition{}, err
+	}
+	if err := checkEvent(event); err != nil {
+		return domain.EventDefinition{}, err
+	}
+	return event, nil
+}
+
+// decodeEvent 解析活動定義 JSON
+func decodeEvent(data []byte) (domain.EventDefinition, error) {
+	var file eventFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return domain.EventDefinition{}, fmt.Errorf("解析活動定義失敗: %w", err)
+	}
+	return file.toDomain(), nil
+}
+
+// checkEvent 驗證活動定義，有錯誤時回傳所有錯誤訊息
+func checkEvent(event domain.EventDefinition) error {
+	errs := event.Validate().Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, issue := range errs {
+		messages = append(messages, issue.String())
+	}
+	return fmt.Errorf("活動定義 %q 驗證失敗:\n  %s", event.Version, strings.Join(messages, "\n  "))
+}
+
+// toDomain 將定義檔格式轉換為領域模型
+func (f eventFile) toDomain() domain.EventDefinition {
+	return domain.EventDefinition{
+		Version:   f.Version,
+		Name:      f.Name,
+		Zodiac:    f.Zodiac.toDomain(),
+		Starlight: f.Starlight.toDomain(),
+	}
+}
+
+// toDomain 將新年氣息定義轉換為領域模型
+func (f zodiacFile) toDomain() domain.ZodiacEvent {
+	rates := make(map[domain.Zodiac]float64)
+	for zodiac, rate := range f.Rates {
+		rates[domain.Zodiac(zodiac)] = rate
+	}
+
+	requirements := make(map[domain.BoxType][]domain.Zodiac)
+	for boxType, zodiacs := range f.BoxRequirements {
+		for _, zodiac := range zodiacs {
+			requirements[domain.BoxType(boxType)] = append(requirements[domain.BoxType(boxType)], domain.Zodiac(zodiac))
+		}
+	}
+
+	var priority []domain.BoxType
+	for _, boxType := range f.BoxPriority {
+		priority = append(priority, domain.BoxType(boxType))
+	}
+
+	return domain.ZodiacEvent{
+		CostPerDraw:     f.CostPerDraw,
+		Rates:           rates,
+		BoxRequirements: requirements,
+		BoxPriority:     priority,
+	}
+}
+
+// toDomain 將星光錦囊定義轉換為領域模型
+func (f starlightFile) toDomain() domain.StarlightEvent {
+	stagePools := make(map[int][]domain.Reward)
+	for stage, pool := range f.StagePools {
+		stagePools[stage] = toRewards(pool)
+	}
+
+	return domain.StarlightEvent{
+		CostPerDraw:      f.CostPerDraw,
+		CrystalItem:      f.CrystalItem,
+		CrystalsPerMerge: f.CrystalsPerMerge,
+		MergedItem:       f.MergedItem,
+		Stage1Pool:       toRewards(f.Stage1Pool),
+		StagePools:       stagePools,
+		UpgradeItems:     f.UpgradeItems,
+		ValuableItems:    f.ValuableItems,
+		ZeroValueItems:   f.ZeroValueItems,
+	}
+}
+
+// toRewards 將獎品定義轉換為領域模型
+func toRewards(pool []rewardFile) []domain.Reward {
+	rewards := make([]domain.Reward, 0, len(pool))
+	for _, r := range pool {
+		rewards = append(rewards, domain.Reward{
+			Name:        r.Name,
+			Probability: r.Probability,
+			MarketPrice: r.MarketPrice,
+		})
+	}
+	return rewards
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// gachaFile 多階段抽獎模型定義檔格式
+type gachaFile struct {
+	Name        string            `json:"name"`
+	Entry       string            `json:"entry"`
+	CostPerDraw float64           `json:"cost_per_draw"`
+	Pools       []gachaPoolFile   `json:"pools"`
+	Opens       map[string]string `json:"opens"`
+	Recipes     []recipeFile      `json:"recipes"`
+}
+
+// gachaPoolFile 獎池節點定義檔格式
+type gachaPoolFile struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Rewards []rewardFile `json:"rewards"`
+}
+
+// recipeFile 合成配方定義檔格式
+type recipeFile struct {
+	Inputs map[string]int `json:"inputs"`
+	Output string         `json:"output"`
+}
+
+// LoadGachaModel 載入並驗證多階段抽獎模型定義檔
+func LoadGachaModel(path string) (domain.GachaModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.GachaModel{}, fmt.Errorf("讀取抽獎模型定義檔失敗: %w", err)
+	}
+	var file gachaFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return domain.GachaModel{}, fmt.Errorf("解析抽獎模型定義檔失敗: %w", err)
+	}
+
+	model := file.toDomain()
+	if errs := model.Validate().Errors(); len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, issue := range errs {
+			messages = append(messages, issue.String())
+		}
+		return domain.GachaModel{}, fmt.Errorf("抽獎模型 %q 驗證失敗:\n  %s", model.Name, strings.Join(messages, "\n  "))
+	}
+	return model, nil
+}
+
+// toDomain 將抽獎模型定義轉換為領域模型（未指定名稱的獎池以代號為名稱）
+func (f gachaFile) toDomain() domain.GachaModel {
+	model := domain.GachaModel{
+		Name:        f.Name,
+		Entry:       f.Entry,
+		CostPerDraw: f.CostPerDraw,
+		Opens:       make(map[string]string),
+	}
+	for _, pool := range f.Pools {
+		name := pool.Name
+		if name == "" {
+			name = pool.ID
+		}
+		model.Pools = append(model.Pools, domain.GachaPool{ID: pool.ID, Name: name, Rewards: toRewards(pool.Rewards)})
+	}
+	for item, pool := range f.Opens {
+		model.Opens[item] = pool
+	}
+	for _, recipe := range f.Recipes {
+		model.Recipes = append(model.Recipes, domain.Recipe{Inputs: recipe.Inputs, Output: recipe.Output})
+	}
+	return model
+}
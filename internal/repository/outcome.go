@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+)
+
+// LoadOutcome 讀取實際結果檔（道具、心願箱或生肖名稱 -> 數量）
+// 格式與道具價格檔相同：副檔名為 .csv 時以「名稱,數量」逐列讀取（可有標題列），其餘視為 JSON 物件
+func LoadOutcome(path string) (map[string]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("讀取實際結果檔失敗: %w", err)
+	}
+	outcome, err := decodeItemValues(data, isCSV(path), "數量")
+	if err != nil {
+		return nil, fmt.Errorf("解析實際結果檔失敗: %w", err)
+	}
+	for name, count := range outcome {
+		if count < 0 {
+			return nil, fmt.Errorf("%q 的數量不可為負", name)
+		}
+	}
+	return outcome, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultPricesPath 伺服器與 CLI 預設的道具價格檔
+const DefaultPricesPath = "prices.json"
+
+// LoadPrices 讀取道具價格檔，path 為空時回傳空價格表
+// 副檔名為 .csv 時以「道具名稱,價格」逐列讀取（可有標題列），其餘視為 JSON 物件（道具名稱 -> 價格）
+func LoadPrices(path string) (domain.PriceBook, error) {
+	if path == "" {
+		return domain.PriceBook{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("讀取道具價格檔失敗: %w", err)
+	}
+	prices, err := decodePrices(data, isCSV(path))
+	if err != nil {
+		return nil, fmt.Errorf("解析道具價格檔失敗: %w", err)
+	}
+	if err := prices.Validate(); err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// SavePrices 寫出道具價格檔（格式依副檔名決定）
+func SavePrices(path string, prices domain.PriceBook) error {
+	if err := prices.Validate(); err != nil {
+		return err
+	}
+	data, err := encodePrices(prices, isCSV(path))
+	if err != nil {
+		return fmt.Errorf("編碼道具價格檔失敗: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("寫入道具價格檔失敗: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic 先寫入同目錄的暫存檔再更名，避免讀到寫到一半的檔案
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// PriceFile 以檔案保存的道具價格表（供伺服器讀寫）
+type PriceFile struct {
+	path string
+}
+
+// NewPriceFile 建立以 path 保存的道具價格表
+func NewPriceFile(path string) *PriceFile {
+	return &PriceFile{path: path}
+}
+
+// Load 讀取價格表，檔案不存在時回傳空價格表
+func (f *PriceFile) Load() (domain.PriceBook, error) {
+	prices, err := LoadPrices(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return domain.PriceBook{}, nil
+	}
+	return prices, err
+}
+
+// Save 寫出價格表
+func (f *PriceFile) Save(prices domain.PriceBook) error {
+	return SavePrices(f.path, prices)
+}
+
+// isCSV 依副檔名判斷是否為 CSV 價格檔
+func isCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+// decodePrices 解析價格檔內容
+func decodePrices(data []byte, csvFormat bool) (domain.PriceBook, error) {
+	return decodeItemValues(data, csvFormat, "價格")
+}
+
+// decodeItemValues 解析「道具名稱 -> 整數」檔案內容，label 為錯誤訊息中的數值名稱
+// CSV 第一列無法解析數值時視為標題列
+func decodeItemValues(data []byte, csvFormat bool, label string) (map[string]int, error) {
+	values := make(map[string]int)
+	if !csvFormat {
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		value, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("第 %d 列的%s %q 不是整數", i+1, label, record[1])
+		}
+		values[strings.TrimSpace(record[0])] = value
+	}
+	return values, nil
+}
+
+// encodePrices 將價格表編碼為檔案內容（依道具名稱排序）
+func encodePrices(prices domain.PriceBook, csvFormat bool) ([]byte, error) {
+	if !csvFormat {
+		data, err := json.MarshalIndent(prices, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"item", "price"})
+	for _, item := range prices.Items(nil) {
+		writer.Write([]string{item, strconv.Itoa(prices[item])})
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// priceHistoryFile 價格歷史檔格式
+type priceHistoryFile struct {
+	Snapshots []snapshotFile `json:"snapshots"`
+}
+
+// snapshotFile 價格快照格式
+type snapshotFile struct {
+	Date   string         `json:"date"` // YYYY-MM-DD
+	Prices map[string]int `json:"prices"`
+}
+
+// LoadPriceHistory 讀取價格歷史檔，path 為空或檔案不存在時回傳空的價格歷史
+func LoadPriceHistory(path string) (domain.PriceHistory, error) {
+	if path == "" {
+		return domain.PriceHistory{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return domain.PriceHistory{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("讀取價格歷史檔失敗: %w", err)
+	}
+	var file priceHistoryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析價格歷史檔失敗: %w", err)
+	}
+
+	history := domain.PriceHistory{}
+	for _, snapshot := range file.Snapshots {
+		date, err := time.Parse(domain.DateLayout, snapshot.Date)
+		if err != nil {
+			return nil, fmt.Errorf("價格快照日期 %q 格式錯誤（應為 YYYY-MM-DD）", snapshot.Date)
+		}
+		history = history.Record(date, snapshot.Prices)
+	}
+	if err := history.Validate(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// SavePriceHistory 寫出價格歷史檔
+func SavePriceHistory(path string, history domain.PriceHistory) error {
+	file := priceHistoryFile{Snapshots: make([]snapshotFile, len(history))}
+	for i, snapshot := range history {
+		file.Snapshots[i] = snapshotFile{
+			Date:   snapshot.Date.Format(domain.DateLayout),
+			Prices: snapshot.Prices,
+		}
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("編碼價格歷史檔失敗: %w", err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("寫入價格歷史檔失敗: %w", err)
+	}
+	return nil
+}
+
+// PriceHistoryFile 以檔案保存的價格歷史（供伺服器讀寫）
+type PriceHistoryFile struct {
+	path string
+}
+
+// NewPriceHistoryFile 建立以 path 保存的價格歷史
+func NewPriceHistoryFile(path string) *PriceHistoryFile {
+	return &PriceHistoryFile{path: path}
+}
+
+// Load 讀取價格歷史
+func (f *PriceHistoryFile) Load() (domain.PriceHistory, error) {
+	return LoadPriceHistory(f.path)
+}
+
+// Save 寫出價格歷史
+func (f *PriceHistoryFile) Save(history domain.PriceHistory) error {
+	return SavePriceHistory(f.path, history)
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// pullLogFile 抽取紀錄檔格式（JSON）
+type pullLogFile struct {
+	Sessions []sessionFile `json:"sessions"`
+}
+
+// sessionFile 單次開啟紀錄格式
+type sessionFile struct {
+	Session string         `json:"session"`
+	Pool    string         `json:"pool"`
+	Counts  map[string]int `json:"counts"`
+}
+
+// LoadPullLog 讀取抽取紀錄檔，未指定獎池的紀錄使用 defaultPool
+//
+// 副檔名為 .csv 時需有標題列，欄位為 item、count 與選填的 session、pool，
+// 同一 session 與 pool 的各列合併為一筆紀錄；其餘視為 JSON：
+// {"sessions": [{"session": "...", "pool": "stage1", "counts": {"道具名稱": 數量}}]}
+func LoadPullLog(path string, defaultPool string) (domain.PullLog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("讀取抽取紀錄檔失敗: %w", err)
+	}
+
+	var sessions []sessionFile
+	if isCSV(path) {
+		sessions, err = decodePullCSV(data)
+	} else {
+		var file pullLogFile
+		err = json.Unmarshal(data, &file)
+		sessions = file.Sessions
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析抽取紀錄檔失敗: %w", err)
+	}
+
+	log := make(domain.PullLog, 0, len(sessions))
+	for _, session := range sessions {
+		pool := session.Pool
+		if pool == "" {
+			pool = defaultPool
+		}
+		log = append(log, domain.PullSession{Name: session.Session, Pool: pool, Counts: session.Counts})
+	}
+	if err := log.Validate(); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// decodePullCSV 解析 CSV 抽取紀錄，依 session 與 pool 合併各列
+func decodePullCSV(data []byte) ([]sessionFile, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// 1. 由標題列取得欄位位置
+	columns := map[string]int{"session": -1, "pool": -1, "item": -1, "count": -1}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	if columns["item"] < 0 || columns["count"] < 0 {
+		return nil, errors.New("標題列需包含 item 與 count 欄位")
+	}
+	field := func(record []string, name string) string {
+		if i := columns[name]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	// 2. 逐列合併至 session 與 pool 相同的紀錄
+	var sessions []sessionFile
+	index := make(map[[2]string]int)
+	for line, record := range records[1:] {
+		item := field(record, "item")
+		count, err := strconv.Atoi(field(record, "count"))
+		if item == "" || err != nil {
+			return nil, fmt.Errorf("第 %d 列的道具或數量格式錯誤", line+2)
+		}
+
+		key := [2]string{field(record, "session"), field(record, "pool")}
+		i, ok := index[key]
+		if !ok {
+			i = len(sessions)
+			index[key] = i
+			sessions = append(sessions, sessionFile{Session: key[0], Pool: key[1], Counts: make(map[string]int)})
+		}
+		sessions[i].Counts[item] += count
+	}
+	return sessions, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// purchasesFile 購買方式定義檔格式
+type purchasesFile struct {
+	Methods []methodFile `json:"methods"`
+}
+
+// methodFile 購買方式規則定義檔格式
+type methodFile struct {
+	Method        string             `json:"method"`
+	Name          string             `json:"name"`
+	Denominations []denominationFile `json:"denominations"`
+	UseDiscount   bool               `json:"use_discount"`
+	BonusRate     float64            `json:"bonus_rate"`
+	Rounding      string             `json:"rounding"`
+	MaxInvestment float64            `json:"max_investment"`
+}
+
+// denominationFile 點卡面額定義檔格式
+type denominationFile struct {
+	Price  int     `json:"price"`
+	Points float64 `json:"points"`
+	Bonus  float64 `json:"bonus"`
+	Limit  int     `json:"limit"`
+}
+
+// LoadPurchases 載入購買方式定義檔，path 為空時使用預設購買方式
+// 定義檔中的購買方式會覆寫同代號的預設購買方式，未定義的預設購買方式維持不變
+func LoadPurchases(path string) (domain.PurchaseRegistry, error) {
+	registry := domain.DefaultPurchaseRegistry()
+	if path == "" {
+		return registry, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("讀取購買方式定義檔失敗: %w", err)
+	}
+	var file purchasesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析購買方式定義檔失敗: %w", err)
+	}
+
+	for _, method := range file.Methods {
+		if err := registry.Register(method.toDomain()); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// toDomain 將購買方式定義轉換為領域模型
+func (f methodFile) toDomain() domain.PurchaseRule {
+	denominations := make([]domain.Denomination, 0, len(f.Denominations))
+	for _, d := range f.Denominations {
+		denominations = append(denominations, domain.Denomination{
+			Price:  d.Price,
+			Points: d.Points,
+			Bonus:  d.Bonus,
+			Limit:  d.Limit,
+		})
+	}
+
+	name := f.Name
+	if name == "" {
+		name = f.Method
+	}
+	rounding := domain.Rounding(f.Rounding)
+	if rounding == "" {
+		rounding = domain.RoundNone
+	}
+
+	return domain.PurchaseRule{
+		Method:        domain.PurchaseMethod(f.Method),
+		Name:          name,
+		Denominations: denominations,
+		UseDiscount:   f.UseDiscount,
+		BonusRate:     f.BonusRate,
+		Rounding:      rounding,
+		MaxInvestment: f.MaxInvestment,
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"math"
+	"sort"
+)
+
+// BoxStrategy 湊箱策略
+type BoxStrategy string
+
+const (
+	StrategyGreedy  BoxStrategy = "greedy"  // 依固定優先順序湊箱
+	StrategyOptimal BoxStrategy = "optimal" // 依心願箱價值求總價值最高的組合
+)
+
+// BoxAssembly 湊箱結果
+type BoxAssembly struct {
+	Strategy    BoxStrategy          // 實際採用的策略
+	Boxes       domain.BoxCollection // 各心願箱數量
+	Value       float64              // 總價值
+	GreedyValue float64              // 固定優先順序湊箱的總價值（供比較）
+}
+
+// optimizeBoxes 在給定氣息下找出總價值最高的心願箱組合
+//
+// 心願箱組成為巢狀結構（小吉 ⊂ 中吉 ⊂ 大吉 ⊂ 超越），令 y_j 為第 j 層以上的箱數、
+// d_j 為相鄰兩層的價值差，總價值即為 Σ d_j·y_j，限制為 y_j 不超過該層以下各層生肖的最小數量
+// 且 y_j 隨層數遞減。最佳解的 y_j 必落在 0 或某一層的上限，因此只需列舉少量候選組合。
+// 組成不為巢狀時無法套用此模型，改用固定優先順序。
+// 最佳組合沒有比貪心算法更好時，回報貪心算法的結果。
+func optimizeBoxes(event domain.ZodiacEvent, breaths domain.BreathCollection, values domain.BoxValues) BoxAssembly {
+	greedy := assembleBoxes(event, breaths)
+	greedyValue := greedy.TotalValue(values)
+	result := BoxAssembly{
+		Strategy:    StrategyGreedy,
+		Boxes:       greedy,
+		Value:       greedyValue,
+		GreedyValue: greedyValue,
+	}
+
+	chain, ok := boxChain(event)
+	if !ok {
+		return result
+	}
+
+	// 各層上限：該層以下所有生肖的最小數量
+	caps := make([]float64, len(chain))
+	for j, boxType := range chain {
+		caps[j] = breaths.Min(event.BoxRequirements[boxType])
+	}
+
+	// 相鄰兩層的價值差
+	deltas := make([]float64, len(chain))
+	for j, boxType := range chain {
+		deltas[j] = values.GetValue(boxType)
+		if j > 0 {
+			deltas[j] -= values.GetValue(chain[j-1])
+		}
+	}
+
+	// 列舉遞減的 y 組合
+	best := make([]float64, len(chain))
+	bestValue := math.Inf(-1)
+	current := make([]float64, len(chain))
+
+	var search func(j int, upper float64, value float64)
+	search = func(j int, upper float64, value float64) {
+		if j == len(chain) {
+			if value > bestValue {
+				bestValue = value
+				copy(best, current)
+			}
+			return
+		}
+
+		candidates := []float64{0}
+		// caps 隨層數遞減，因此 caps[m]（m ≥ j）皆不超過本層上限
+		for m := j; m < len(chain); m++ {
+			candidates = append(candidates, caps[m])
+		}
+		for _, y := range candidates {
+			if y > upper {
+				continue
+			}
+			current[j] = y
+			search(j+1, y, value+deltas[j]*y)
+		}
+	}
+	search(0, math.Inf(1), 0)
+
+	if bestValue <= greedyValue+1e-9 {
+		return result
+	}
+
+	// y 轉回各心願箱數量：x_j = y_j - y_{j+1}
+	boxes := domain.NewBoxCollection()
+	for j, boxType := range chain {
+		next := 0.0
+		if j+1 < len(chain) {
+			next = best[j+1]
+		}
+		boxes[boxType] = best[j] - next
+	}
+
+	result.Strategy = StrategyOptimal
+	result.Boxes = boxes
+	result.Value = boxes.TotalValue(values)
+	return result
+}
+
+// boxChain 將心願箱依組成由少到多排序，並確認每一種都包含前一種的所有生肖
+func boxChain(event domain.ZodiacEvent) ([]domain.BoxType, bool) {
+	chain := make([]domain.BoxType, len(event.BoxPriority))
+	copy(chain, event.BoxPriority)
+	sort.SliceStable(chain, func(i, j int) bool {
+		return len(event.BoxRequirements[chain[i]]) < len(event.BoxRequirements[chain[j]])
+	})
+
+	for j := 1; j < len(chain); j++ {
+		contains := make(map[domain.Zodiac]bool)
+		for _, zodiac := range event.BoxRequirements[chain[j]] {
+			contains[zodiac] = true
+		}
+		for _, zodiac := range event.BoxRequirements[chain[j-1]] {
+			if !contains[zodiac] {
+				return nil, false
+			}
+		}
+	}
+
+	return chain, len(chain) > 0
+}
+
+// ZodiacModel 新年氣息的多階段抽獎模型，湊箱以 optimizeBoxes 依心願箱價格求總價值最高的組合，
+// 與 ZodiacSimulator 的湊箱方式一致
+func ZodiacModel(event domain.ZodiacEvent) domain.GachaModel {
+	model := event.Model()
+	model.Assemble = func(items map[string]int, prices map[string]int) {
+		breaths := domain.NewBreathCollection()
+		for _, zodiac := range event.Zodiacs() {
+			breaths[zodiac] = float64(items[string(zodiac)])
+		}
+
+		assembly := optimizeBoxes(event, breaths, domain.PriceBook(prices).BoxValues())
+		for _, boxType := range event.BoxPriority {
+			n := int(assembly.Boxes[boxType])
+			items[string(boxType)] += n
+			for _, zodiac := range event.BoxRequirements[boxType] {
+				items[string(zodiac)] -= n
+			}
+		}
+	}
+	return model
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"MSCashItemExpected/internal/repository"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func loadTestEvent(t *testing.T) domain.EventDefinition {
+	t.Helper()
+	event, err := repository.LoadEvent("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+// bruteForceBoxes 列舉所有心願箱數量組合，回傳氣息足夠時的最高總價值
+func bruteForceBoxes(event domain.ZodiacEvent, breaths domain.BreathCollection, values domain.BoxValues) float64 {
+	boxTypes := event.BoxPriority
+	counts := make([]float64, len(boxTypes))
+	best := 0.0
+
+	var search func(i int)
+	search = func(i int) {
+		if i == len(boxTypes) {
+			used := domain.NewBreathCollection()
+			var value float64
+			for j, boxType := range boxTypes {
+				for _, zodiac := range event.BoxRequirements[boxType] {
+					used[zodiac] += counts[j]
+				}
+				value += counts[j] * values.GetValue(boxType)
+			}
+			for zodiac, n := range used {
+				if n > breaths[zodiac] {
+					return
+				}
+			}
+			best = math.Max(best, value)
+			return
+		}
+		for n := 0.0; n <= breaths.Min(event.BoxRequirements[boxTypes[i]]); n++ {
+			counts[i] = n
+			search(i + 1)
+		}
+	}
+	search(0)
+	return best
+}
+
+func TestOptimizeBoxesMatchesBruteForce(t *testing.T) {
+	event := loadTestEvent(t).Zodiac
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		breaths := domain.NewBreathCollection()
+		for _, zodiac := range event.Zodiacs() {
+			breaths[zodiac] = float64(rng.Intn(4))
+		}
+		// 價值不一定隨組成遞增，涵蓋貪心算法較差的情況
+		values := domain.BoxValues{
+			Small:  float64(rng.Intn(100)),
+			Medium: float64(rng.Intn(300)),
+			Large:  float64(rng.Intn(600)),
+			Super:  float64(rng.Intn(1000)),
+		}
+
+		assembly := optimizeBoxes(event, breaths, values)
+		want := bruteForceBoxes(event, breaths, values)
+		if math.Abs(assembly.Value-want) > 1e-9 {
+			t.Fatalf("氣息 %v、價值 %+v：optimizeBoxes = %g, want %g", breaths, values, assembly.Value, want)
+		}
+		if math.Abs(assembly.Boxes.TotalValue(values)-assembly.Value) > 1e-9 {
+			t.Fatalf("心願箱 %v 的總價值與回報的 %g 不一致", assembly.Boxes, assembly.Value)
+		}
+		if assembly.Value < assembly.GreedyValue-1e-9 {
+			t.Fatalf("最佳組合 %g 低於固定優先順序 %g", assembly.Value, assembly.GreedyValue)
+		}
+	}
+}
+
+func TestOptimizeBoxesBeatsGreedy(t *testing.T) {
+	event := loadTestEvent(t).Zodiac
+
+	// 每種生肖各 1 個、小吉生肖各 3 個，超越價值偏低：
+	// 固定優先順序湊 1 個超越 + 2 個小吉（250），最佳為 1 個大吉 + 2 個小吉（400）
+	breaths := domain.NewBreathCollection()
+	for _, zodiac := range event.Zodiacs() {
+		breaths[zodiac] = 1
+	}
+	for _, zodiac := range event.BoxRequirements[domain.BoxSmall] {
+		breaths[zodiac] = 3
+	}
+	values := domain.BoxValues{Small: 100, Medium: 150, Large: 200, Super: 50}
+
+	assembly := optimizeBoxes(event, breaths, values)
+	if assembly.Strategy != StrategyOptimal || assembly.Value != 400 || assembly.GreedyValue != 250 {
+		t.Errorf("optimizeBoxes = %s %g（貪心 %g）, want optimal 400（貪心 250）", assembly.Strategy, assembly.Value, assembly.GreedyValue)
+	}
+	if assembly.Boxes[domain.BoxLarge] != 1 || assembly.Boxes[domain.BoxSmall] != 2 {
+		t.Errorf("Boxes = %v, want 大吉 1、小吉 2", assembly.Boxes)
+	}
+}
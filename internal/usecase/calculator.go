@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"MSCashItemExpected/internal/domain"
+	"context"
+	"fmt"
+	"time"
 )
 
 // CalculatorInput 計算器輸入
@@ -10,85 +13,191 @@ type CalculatorInput struct {
 	Method     domain.PurchaseMethod
 	Discount   float64
 	BoxValues  domain.BoxValues
+	Inventory  domain.BreathCollection // 已持有的氣息
+	Trials     int                     // 蒙地卡羅模擬次數，0 表示使用預設值
+	// IntegerDraws 以整數抽數計算（不足一抽的點數保留為剩餘點數），否則視抽數為可分割
+	IntegerDraws bool
+	CarryPoints  float64 // 前次購買或其他活動結轉的點數
 }
 
 // CalculatorOutput 計算器輸出
 type CalculatorOutput struct {
 	Points          float64
+	Purchase        domain.Purchase // 購買方式與點卡組合
 	DrawCount       float64
+	Residual        float64 // 不足一抽的剩餘點數（整數抽數時）
 	CostPerBreath   float64
 	ExpectedBreaths domain.BreathCollection
-	ExpectedBoxes   domain.BoxCollection
+	ExpectedBoxes   domain.BoxCollection // 已持有 + 新抽氣息可湊成的心願箱
+	InventoryBoxes  domain.BoxCollection // 僅以已持有氣息可湊成的心願箱
+	NewBoxes        domain.BoxCollection // 本次購買新解鎖的心願箱
 	ExpectedValue   float64
+	InventoryValue  float64 // 已持有氣息可湊成心願箱的價值
+	AddedValue      float64 // 本次購買增加的價值
 	ROI             float64
+	Strategy        BoxStrategy     // 實際採用的湊箱策略
+	GreedyValue     float64         // 固定優先順序湊箱的期望總價值
+	Distribution    BoxDistribution // 心願箱數量分佈（蒙地卡羅模擬）
+	Risk            RiskReport      // 總價值與報酬率的分散程度（蒙地卡羅模擬）
+	Seed            int64           // 模擬使用的亂數種子
+}
+
+// ZodiacTargetInput 新年氣息目標反推輸入
+type ZodiacTargetInput struct {
+	Method     domain.PurchaseMethod
+	Discount   float64
+	BoxType    domain.BoxType          // 目標心願箱
+	Count      int                     // 目標數量
+	Confidence float64                 // 信心水準 (%)
+	Inventory  domain.BreathCollection // 已持有的氣息
+	Trials     int                     // 模擬次數
+	MaxDraws   int                     // 每次模擬的抽數上限，0 表示使用預設值
 }
 
 // Calculator 期望值計算器
-type Calculator struct{}
+type Calculator struct {
+	event     domain.ZodiacEvent
+	simulator *ZodiacSimulator
+}
+
+// NewCalculator 建立計算器，選項套用於內部的模擬器
+func NewCalculator(event domain.ZodiacEvent, opts ...Option) *Calculator {
+	return &Calculator{
+		event:     event,
+		simulator: NewZodiacSimulator(event, opts...),
+	}
+}
 
-// NewCalculator 建立計算器
-func NewCalculator() *Calculator {
-	return &Calculator{}
+// WithSeed 建立使用指定種子的新計算器
+func (c *Calculator) WithSeed(seed int64) *Calculator {
+	return &Calculator{
+		event:     c.event,
+		simulator: c.simulator.WithSeed(seed),
+	}
+}
+
+// Seed 取得計算器使用的亂數種子
+func (c *Calculator) Seed() int64 {
+	return c.simulator.Seed()
+}
+
+// DrawCount 計算投入金額加上結轉點數實際可抽的整數次數
+func (c *Calculator) DrawCount(investment float64, method domain.PurchaseMethod, discount float64, carryPoints float64) int {
+	return c.simulator.DrawCount(investment, method, discount, carryPoints)
 }
 
-// Calculate 計算期望值
-func (c *Calculator) Calculate(input CalculatorInput) CalculatorOutput {
-	// 1. 計算可得點數
-	points := domain.CalculatePoints(input.Investment, input.Method, input.Discount)
+// Calculate 計算期望值，並以蒙地卡羅模擬估計心願箱數量與總價值的分佈
+func (c *Calculator) Calculate(ctx context.Context, input CalculatorInput) (CalculatorOutput, error) {
+	output := c.CalculateExpected(input)
 
-	// 2. 計算可抽次數
-	drawCount := points / domain.CostPerDraw
+	// 模擬心願箱數量、總價值與報酬率的實際分佈
+	trials := input.Trials
+	if trials <= 0 {
+		trials = DefaultRiskTrials
+	}
+	simulation, err := c.simulator.Simulate(ctx, ZodiacSimulationInput{
+		Investment:  input.Investment,
+		Method:      input.Method,
+		Discount:    input.Discount,
+		BoxValues:   input.BoxValues,
+		Inventory:   input.Inventory,
+		Trials:      trials,
+		CarryPoints: input.CarryPoints,
+	})
+	if err != nil {
+		return CalculatorOutput{}, err
+	}
+	output.Distribution = simulation.Boxes
+	output.Risk = newRiskReport(output.Purchase.Cost, simulation.Value, simulation.ROI, trials)
+	output.Seed = simulation.Seed
+
+	return output, nil
+}
+
+// CalculateExpectedAt 以價格歷史中某日的心願箱價值計算期望值（不含模擬分佈）
+func (c *Calculator) CalculateExpectedAt(history domain.PriceHistory, date time.Time, input CalculatorInput) CalculatorOutput {
+	input.BoxValues = history.At(date).BoxValues()
+	return c.CalculateExpected(input)
+}
+
+// CalculateExpected 計算期望值（解析解，不含模擬分佈）
+func (c *Calculator) CalculateExpected(input CalculatorInput) CalculatorOutput {
+	// 1. 計算可得點數（點卡面額時為預算內點數最多的組合）
+	purchase := c.simulator.purchases.Buy(input.Investment, input.Method, input.Discount)
+	points := purchase.Points + input.CarryPoints
+	cost := purchase.Cost
+
+	// 2. 計算可抽次數（整數抽數時無條件捨去，剩餘點數可結轉）
+	drawCount := points / c.event.CostPerDraw
+	residual := 0.0
+	if input.IntegerDraws {
+		plan := domain.PlanDraws(points, c.event.CostPerDraw)
+		drawCount = float64(plan.Draws)
+		residual = plan.Residual
+	}
 
 	// 3. 計算每個氣息的實際成本
 	costPerBreath := 0.0
 	if drawCount > 0 {
-		costPerBreath = input.Investment / drawCount
+		costPerBreath = cost / drawCount
 	}
 
 	// 4. 計算期望獲得各氣息數量
 	expectedBreaths := c.calculateExpectedBreaths(drawCount)
 
-	// 5. 計算期望可湊心願箱數量（貪心算法）
-	expectedBoxes := c.calculateExpectedBoxes(expectedBreaths)
+	// 5. 計算期望可湊心願箱數量（已持有 + 新抽，依心願箱價值求最佳組合）
+	assembly := optimizeBoxes(c.event, expectedBreaths.Add(input.Inventory), input.BoxValues)
+	expectedBoxes := assembly.Boxes
 
-	// 6. 計算期望總價值
-	expectedValue := expectedBoxes.TotalValue(input.BoxValues)
+	// 6. 計算期望總價值與本次購買增加的價值
+	inventory := optimizeBoxes(c.event, input.Inventory, input.BoxValues)
+	expectedValue := assembly.Value
+	addedValue := expectedValue - inventory.Value
 
-	// 7. 計算報酬率
+	// 7. 計算報酬率（僅計入本次購買增加的價值）
 	roi := 0.0
-	if input.Investment > 0 {
-		roi = ((expectedValue - input.Investment) / input.Investment) * 100
+	if cost > 0 {
+		roi = ((addedValue - cost) / cost) * 100
 	}
 
 	return CalculatorOutput{
 		Points:          points,
+		Purchase:        purchase,
 		DrawCount:       drawCount,
+		Residual:        residual,
 		CostPerBreath:   costPerBreath,
 		ExpectedBreaths: expectedBreaths,
 		ExpectedBoxes:   expectedBoxes,
+		InventoryBoxes:  inventory.Boxes,
+		NewBoxes:        expectedBoxes.Diff(inventory.Boxes),
 		ExpectedValue:   expectedValue,
+		InventoryValue:  inventory.Value,
+		AddedValue:      addedValue,
 		ROI:             roi,
+		Strategy:        assembly.Strategy,
+		GreedyValue:     assembly.GreedyValue,
 	}
 }
 
 // calculateExpectedBreaths 計算期望獲得的氣息數量
 func (c *Calculator) calculateExpectedBreaths(drawCount float64) domain.BreathCollection {
 	breaths := domain.NewBreathCollection()
-	for zodiac, rate := range domain.ZodiacRates {
+	for zodiac, rate := range c.event.Rates {
 		breaths[zodiac] = drawCount * (rate / 100.0)
 	}
 	return breaths
 }
 
-// calculateExpectedBoxes 計算期望心願箱數量（貪心算法，優先湊高價值）
-func (c *Calculator) calculateExpectedBoxes(breaths domain.BreathCollection) domain.BoxCollection {
+// assembleBoxes 依優先順序湊心願箱（貪心算法，優先湊高價值）
+// 傳入實際抽到的整數氣息數量時，即為實際可湊成的心願箱數量
+func assembleBoxes(event domain.ZodiacEvent, breaths domain.BreathCollection) domain.BoxCollection {
 	// 複製一份，避免修改原始資料
 	remaining := breaths.Clone()
 	result := domain.NewBoxCollection()
 
 	// 按優先順序湊箱
-	for _, boxType := range domain.BoxPriority {
-		requirements := domain.BoxRequirements[boxType]
+	for _, boxType := range event.BoxPriority {
+		requirements := event.BoxRequirements[boxType]
 
 		// 找出可湊的箱數（取最小值）
 		minCount := remaining.Min(requirements)
@@ -102,3 +211,34 @@ func (c *Calculator) calculateExpectedBoxes(breaths domain.BreathCollection) dom
 
 	return result
 }
+
+// SolveTarget 反推以指定信心水準湊成 Count 個目標心願箱所需的投入金額
+// 目標為「可湊成」該心願箱，即所需生肖皆至少有 Count 個（不論實際選擇湊哪一種箱）
+func (c *Calculator) SolveTarget(ctx context.Context, input ZodiacTargetInput) (TargetOutput, error) {
+	if _, ok := c.event.BoxRequirements[input.BoxType]; !ok {
+		return TargetOutput{}, fmt.Errorf("未知的心願箱類型 %q", input.BoxType)
+	}
+	if err := validateTarget(input.Count, input.Confidence, input.Trials); err != nil {
+		return TargetOutput{}, err
+	}
+
+	maxDraws := input.MaxDraws
+	if maxDraws <= 0 {
+		maxDraws = DefaultMaxTargetDraws
+	}
+
+	// 每次模擬記錄湊齊目標所需的抽數
+	hits, err := collectHits(ctx, c.simulator.engine, input.Trials, func(rng RNG) int {
+		return c.simulator.DrawsToAssemble(rng, input.BoxType, input.Count, input.Inventory, maxDraws)
+	})
+	if err != nil {
+		return TargetOutput{}, err
+	}
+
+	output, err := solveTarget(hits, maxDraws, input.Confidence, c.event.CostPerDraw, c.simulator.purchases.Rule(input.Method), input.Discount)
+	if err != nil {
+		return TargetOutput{}, err
+	}
+	output.Seed = c.Seed()
+	return output, nil
+}
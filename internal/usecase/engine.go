@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// 模擬區塊大小：區塊切分只依總次數決定，與 worker 數量無關
+const (
+	drawChunkSize  = 10000 // 逐抽模擬每個區塊的抽數
+	trialChunkSize = 100   // 逐次模擬每個區塊的模擬次數
+)
+
+// Engine 平行蒙地卡羅模擬引擎
+//
+// 每次模擬切分為固定大小的區塊，各區塊使用由主種子、模擬序號與區塊序號衍生的獨立亂數串流，
+// 由 worker pool 並行執行後依區塊順序合併，因此相同種子下結果與 worker 數量無關。
+type Engine struct {
+	seed    int64
+	source  RNGSource
+	workers int
+	streams atomic.Uint64 // 已執行的模擬序號，使同一引擎的多次模擬使用不同串流
+}
+
+// NewEngine 建立平行模擬引擎
+func NewEngine(opts ...Option) *Engine {
+	return newEngine(newOptions(opts))
+}
+
+// newEngine 以已套用的建立選項建立平行模擬引擎
+func newEngine(o options) *Engine {
+	return &Engine{
+		seed:    o.seed,
+		source:  o.source,
+		workers: o.workers,
+	}
+}
+
+// Seed 取得主種子
+func (e *Engine) Seed() int64 {
+	return e.seed
+}
+
+// Workers 取得 worker 數量
+func (e *Engine) Workers() int {
+	return e.workers
+}
+
+// WithSeed 以相同亂數來源與 worker 數量，建立使用指定種子的新引擎
+func (e *Engine) WithSeed(seed int64) *Engine {
+	return NewEngine(WithSeed(seed), WithSource(e.source), WithWorkers(e.workers))
+}
+
+// runChunks 將 total 次模擬切分為 chunkSize 大小的區塊並行執行，回傳依區塊順序排列的結果
+// context 取消時停止派發新區塊，等待執行中的區塊結束後回傳錯誤
+func runChunks[T any](ctx context.Context, e *Engine, total int, chunkSize int, work func(rng RNG, size int) T) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if total <= 0 {
+		return nil, nil
+	}
+
+	stream := e.streams.Add(1)
+	chunks := (total + chunkSize - 1) / chunkSize
+	results := make([]T, chunks)
+
+	// 1. 啟動 worker pool
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(e.workers, chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				size := min(chunkSize, total-index*chunkSize)
+				rng := e.source(deriveSeed(e.seed, stream, uint64(index)))
+				results[index] = work(rng, size)
+			}
+		}()
+	}
+
+	// 2. 依序派發區塊，context 取消時停止
+	var err error
+dispatch:
+	for index := 0; index < chunks; index++ {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// deriveSeed 由主種子、模擬序號與區塊序號衍生區塊的亂數種子（SplitMix64）
+func deriveSeed(seed int64, stream, index uint64) int64 {
+	z := uint64(seed) + stream*0x9e3779b97f4a7c15 + index*0xd1b54a32d192ed03
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
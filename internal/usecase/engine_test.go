@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRunChunksDeterministicAcrossWorkers(t *testing.T) {
+	run := func(workers int) [][]float64 {
+		engine := NewEngine(WithSeed(42), WithWorkers(workers))
+		chunks, err := runChunks(context.Background(), engine, 10_000, 1_000, func(rng RNG, size int) []float64 {
+			draws := make([]float64, size)
+			for i := range draws {
+				draws[i] = rng.Float64()
+			}
+			return draws
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chunks
+	}
+
+	want := run(1)
+	if len(want) != 10 || len(want[9]) != 1_000 {
+		t.Fatalf("區塊數 = %d，應切分為 10 個 1000 次的區塊", len(want))
+	}
+	for _, workers := range []int{2, 3, 8} {
+		if got := run(workers); !reflect.DeepEqual(got, want) {
+			t.Errorf("%d 個 worker 的結果與單一 worker 不同", workers)
+		}
+	}
+}
+
+func TestSimulateDeterministicAcrossWorkers(t *testing.T) {
+	event := loadTestEvent(t)
+	values := domain.BoxValues{Small: 100, Medium: 500, Large: 3000, Super: 20000}
+
+	tests := []struct {
+		name string
+		run  func(workers int) (any, error)
+	}{
+		{"新年氣息", func(workers int) (any, error) {
+			simulator := NewZodiacSimulator(event.Zodiac, WithSeed(7), WithWorkers(workers))
+			return simulator.Simulate(context.Background(), ZodiacSimulationInput{
+				Investment: 10000,
+				Method:     domain.MethodCard,
+				Discount:   1,
+				BoxValues:  values,
+				Trials:     5000,
+			})
+		}},
+		{"多階段抽獎模型", func(workers int) (any, error) {
+			engine := NewGachaEngine(event.Starlight.Model(), WithSeed(7), WithWorkers(workers))
+			return engine.Simulate(context.Background(), GachaSimulationInput{Draws: 50, Trials: 5000})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := tt.run(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, workers := range []int{2, 8} {
+				got, err := tt.run(workers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%d 個 worker 的模擬結果與單一 worker 不同", workers)
+				}
+			}
+		})
+	}
+}
+
+func TestEngineSeedsDifferAcrossSimulations(t *testing.T) {
+	engine := NewEngine(WithSeed(1), WithWorkers(1))
+	first := func() float64 {
+		chunks, err := runChunks(context.Background(), engine, 1, 1, func(rng RNG, size int) float64 {
+			return rng.Float64()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chunks[0]
+	}
+	if first() == first() {
+		t.Error("同一個引擎的兩次模擬應使用不同的亂數序列")
+	}
+}
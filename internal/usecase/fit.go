@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 多項精確檢定的蒙地卡羅模擬參數
+const (
+	defaultFitTrials = 10000       // 無法列舉時的預設模擬次數
+	minFitTrials     = 200         // 依總抽數調降後的最少模擬次數
+	maxFitDraws      = 200_000_000 // 模擬次數 × 總抽數的上限
+)
+
+// MaxFitLogDraws 單一獎池紀錄總抽數的上限，確保多項精確檢定至少能模擬 minFitTrials 次
+const MaxFitLogDraws = maxFitDraws / minFitTrials
+
+// CheckFitLog 檢查抽取紀錄，並在進行任何檢定前確認各獎池的總抽數不超過 MaxFitLogDraws
+func CheckFitLog(log domain.PullLog) error {
+	if err := log.Validate(); err != nil {
+		return err
+	}
+	for _, pool := range log.Pools() {
+		draws, err := log.Draws(pool)
+		if err != nil {
+			return err
+		}
+		if draws > MaxFitLogDraws {
+			return fmt.Errorf("獎池 %q 的紀錄共 %d 抽，超過檢定的上限 %d 抽", pool, draws, MaxFitLogDraws)
+		}
+	}
+	return nil
+}
+
+// FitInput 抽取紀錄適合度檢定輸入
+type FitInput struct {
+	Log    domain.PullLog
+	Trials int // 多項精確檢定無法列舉時的模擬次數，0 表示使用預設值
+}
+
+// FitOutput 抽取紀錄適合度檢定輸出
+type FitOutput struct {
+	Tests []domain.FitTest // 各獎池的檢定結果（依紀錄中首次出現的順序）
+	Seed  int64            // 模擬使用的亂數種子
+}
+
+// RateTester 以抽取紀錄檢定公告機率或估計獎池的事後機率
+type RateTester struct {
+	event  domain.EventDefinition
+	engine *Engine
+}
+
+// NewRateTester 建立機率檢定器
+func NewRateTester(event domain.EventDefinition, opts ...Option) *RateTester {
+	return &RateTester{
+		event:  event,
+		engine: newEngine(newOptions(opts)),
+	}
+}
+
+// WithSeed 建立使用指定種子的新檢定器
+func (t *RateTester) WithSeed(seed int64) *RateTester {
+	return &RateTester{
+		event:  t.event,
+		engine: t.engine.WithSeed(seed),
+	}
+}
+
+// Seed 取得檢定器使用的亂數種子
+func (t *RateTester) Seed() int64 {
+	return t.engine.Seed()
+}
+
+// Test 依獎池合計抽取紀錄，進行卡方、各道具精確二項與多項精確檢定
+// 多項精確檢定的結果數過多無法列舉時，以蒙地卡羅模擬估計 p 值
+func (t *RateTester) Test(ctx context.Context, input FitInput) (FitOutput, error) {
+	// 1. 檢查抽取紀錄與總抽數（各道具的精確二項檢定運算量隨抽數成長，須在檢定前拒絕）
+	if err := CheckFitLog(input.Log); err != nil {
+		return FitOutput{}, err
+	}
+	trials := input.Trials
+	if trials <= 0 {
+		trials = defaultFitTrials
+	}
+
+	output := FitOutput{Seed: t.engine.Seed()}
+	for _, pool := range input.Log.Pools() {
+		// 2. 合計紀錄並以公告機率檢定
+		name, rewards, err := t.event.PoolByID(pool)
+		if err != nil {
+			return FitOutput{}, err
+		}
+		counts, sessions := input.Log.Totals(pool)
+		test, err := domain.NewFitTest(pool, name, rewards, counts, sessions)
+		if err != nil {
+			return FitOutput{}, err
+		}
+
+		// 3. 無法列舉時以模擬估計多項精確檢定
+		if test.ExactMethod == "" {
+			used := min(trials, maxFitDraws/test.Total)
+			p, err := t.simulateExact(ctx, test, used)
+			if err != nil {
+				return FitOutput{}, err
+			}
+			test.ExactPValue = p
+			test.ExactMethod = domain.ExactMonteCarlo
+			test.ExactTrials = used
+		}
+		output.Tests = append(output.Tests, test)
+	}
+	return output, nil
+}
+
+// simulateExact 以公告機率模擬相同總抽數的結果，估計機率不大於觀察結果的比例
+// 以 (命中數 + 1) / (模擬次數 + 1) 估計，避免 p 值為 0
+func (t *RateTester) simulateExact(ctx context.Context, test domain.FitTest, trials int) (float64, error) {
+	counts, probs := test.Categories()
+	observed := domain.MultinomialLogPMF(counts, probs)
+
+	cumulative := make([]float64, len(probs))
+	var sum float64
+	for i, p := range probs {
+		sum += p
+		cumulative[i] = sum
+	}
+
+	chunks, err := runChunks(ctx, t.engine, trials, trialChunkSize, func(rng RNG, size int) int {
+		hits := 0
+		sample := make([]int, len(probs))
+		for trial := 0; trial < size; trial++ {
+			clear(sample)
+			for draw := 0; draw < test.Total; draw++ {
+				roll := rng.Float64() * sum
+				index := sort.Search(len(cumulative), func(i int) bool { return roll < cumulative[i] })
+				sample[min(index, len(sample)-1)]++
+			}
+			if domain.IsAsExtreme(domain.MultinomialLogPMF(sample, probs), observed) {
+				hits++
+			}
+		}
+		return hits
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	hits := 0
+	for _, chunk := range chunks {
+		hits += chunk
+	}
+	return math.Min(float64(hits+1)/float64(trials+1), 1), nil
+}
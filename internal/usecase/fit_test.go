@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCheckFitLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     domain.PullLog
+		wantErr bool
+	}{
+		{"上限內", domain.PullLog{{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": MaxFitLogDraws}}}, false},
+		{"超過上限", domain.PullLog{{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": MaxFitLogDraws, "龍": 1}}}, true},
+		{"跨紀錄合計超過上限", domain.PullLog{
+			{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": MaxFitLogDraws}},
+			{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": 1}},
+		}, true},
+		{"合計溢位", domain.PullLog{
+			{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": math.MaxInt}},
+			{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": math.MaxInt}},
+		}, true},
+		{"數量為負", domain.PullLog{{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": -1}}}, true},
+		{"各獎池分別計算", domain.PullLog{
+			{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": MaxFitLogDraws}},
+			{Pool: domain.PoolStage1, Counts: map[string]int{"靈魂艾爾達": MaxFitLogDraws}},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckFitLog(tt.log); (err != nil) != tt.wantErr {
+				t.Errorf("CheckFitLog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRateTesterRejectsHugeLogBeforeTesting(t *testing.T) {
+	tester := NewRateTester(loadTestEvent(t), WithSeed(1))
+
+	// 一兆抽的紀錄應在計算各道具的二項分佈前被拒絕
+	log := domain.PullLog{{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": 1_000_000_000_000}}}
+	if _, err := tester.Test(context.Background(), FitInput{Log: log}); err == nil {
+		t.Error("應回傳錯誤")
+	}
+}
@@ -0,0 +1,304 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"fmt"
+)
+
+// GachaEngine 多階段抽獎模型的計算與模擬引擎
+// 期望值與精確分佈由模型直接計算，模擬則逐抽沿開啟關係展開並依配方合成
+type GachaEngine struct {
+	model  domain.GachaModel
+	engine *Engine
+}
+
+// NewGachaEngine 建立多階段抽獎引擎
+func NewGachaEngine(model domain.GachaModel, opts ...Option) *GachaEngine {
+	return &GachaEngine{
+		model:  model,
+		engine: newEngine(newOptions(opts)),
+	}
+}
+
+// WithSeed 以相同模型與引擎設定，建立使用指定種子的新引擎
+func (g *GachaEngine) WithSeed(seed int64) *GachaEngine {
+	return &GachaEngine{
+		model:  g.model,
+		engine: g.engine.WithSeed(seed),
+	}
+}
+
+// Seed 取得引擎使用的亂數種子
+func (g *GachaEngine) Seed() int64 {
+	return g.engine.Seed()
+}
+
+// Model 取得引擎使用的抽獎模型
+func (g *GachaEngine) Model() domain.GachaModel {
+	return g.model
+}
+
+// Distribution 開啟指定獎池 opens 次的各最終道具精確分佈與各獎池的抵達機率 (%)
+func (g *GachaEngine) Distribution(pool string, opens int) ([]domain.ItemDistribution, map[string]float64, error) {
+	if _, ok := g.model.Pool(pool); !ok {
+		return nil, nil, fmt.Errorf("模型中沒有獎池 %q", pool)
+	}
+	if opens < 0 {
+		return nil, nil, fmt.Errorf("開啟次數不可為負")
+	}
+
+	reach := g.model.Reach(pool)
+	for id := range reach {
+		reach[id] *= 100
+	}
+	return g.model.OpenDistribution(pool, opens), reach, nil
+}
+
+// GachaSimulationInput 多階段抽獎模擬輸入
+type GachaSimulationInput struct {
+	Draws  int            // 每次模擬抽入口獎池的次數
+	Trials int            // 模擬次數
+	Prices map[string]int // 道具價格（計算總價值）
+}
+
+// GachaItemSummary 單一道具的期望數量與模擬分佈
+type GachaItemSummary struct {
+	Name     string
+	Expected float64        // 期望數量（配方視為可分割，多原料配方不套用）
+	Count    domain.Summary // 模擬所得數量分佈（整數合成）
+}
+
+// GachaSimulationOutput 多階段抽獎模擬輸出
+type GachaSimulationOutput struct {
+	Draws  int
+	Trials int
+	Items  []GachaItemSummary // 依模型道具順序
+	Value  domain.Summary     // 模擬所得總價值分佈
+	Seed   int64
+}
+
+// Simulate 模擬抽 Draws 次入口獎池並依配方合成 Trials 次，統計各道具數量與總價值分佈
+func (g *GachaEngine) Simulate(ctx context.Context, input GachaSimulationInput) (GachaSimulationOutput, error) {
+	if input.Draws < 0 || input.Trials <= 0 {
+		return GachaSimulationOutput{}, fmt.Errorf("抽數不可為負且模擬次數必須大於 0")
+	}
+	names := g.model.Items()
+
+	// 1. 平行模擬，依道具順序記錄每次模擬的數量與總價值
+	type gachaChunk struct {
+		counts [][]float64
+		values []float64
+	}
+	chunks, err := runChunks(ctx, g.engine, input.Trials, trialChunkSize, func(rng RNG, size int) gachaChunk {
+		chunk := gachaChunk{counts: make([][]float64, len(names)), values: make([]float64, 0, size)}
+		for t := 0; t < size; t++ {
+			items := g.play(rng, input.Draws, input.Prices)
+
+			var value float64
+			for i, name := range names {
+				chunk.counts[i] = append(chunk.counts[i], float64(items[name]))
+				value += float64(items[name]) * float64(input.Prices[name])
+			}
+			chunk.values = append(chunk.values, value)
+		}
+		return chunk
+	})
+	if err != nil {
+		return GachaSimulationOutput{}, err
+	}
+
+	// 2. 依區塊順序合併並與期望數量並列
+	counts := make([][]float64, len(names))
+	values := make([]float64, 0, input.Trials)
+	for _, chunk := range chunks {
+		for i := range names {
+			counts[i] = append(counts[i], chunk.counts[i]...)
+		}
+		values = append(values, chunk.values...)
+	}
+
+	expected := g.model.Expected(float64(input.Draws))
+	output := GachaSimulationOutput{
+		Draws:  input.Draws,
+		Trials: input.Trials,
+		Value:  domain.Summarize(values),
+		Seed:   g.Seed(),
+	}
+	for i, name := range names {
+		output.Items = append(output.Items, GachaItemSummary{
+			Name:     name,
+			Expected: expected[name],
+			Count:    domain.Summarize(counts[i]),
+		})
+	}
+	return output, nil
+}
+
+// simulateValues 平行模擬 trials 次抽入口獎池 draws 次並依配方合成，以 value 計算每次模擬所得道具的價值，
+// 回傳依模擬順序排列的樣本
+func (g *GachaEngine) simulateValues(ctx context.Context, draws, trials int, prices map[string]int, value func(items map[string]int) float64) ([]float64, error) {
+	chunks, err := runChunks(ctx, g.engine, trials, trialChunkSize, func(rng RNG, size int) []float64 {
+		values := make([]float64, size)
+		for t := range values {
+			values[t] = value(g.play(rng, draws, prices))
+		}
+		return values
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, 0, trials)
+	for _, chunk := range chunks {
+		values = append(values, chunk...)
+	}
+	return values, nil
+}
+
+// GachaOpenOutput 開啟單一獎池的模擬結果
+type GachaOpenOutput struct {
+	Opens int
+	Stops map[string]int // 停留的獎池代號 -> 次數
+	Items map[string]int // 最終道具 -> 數量
+}
+
+// SimulateOpens 模擬開啟指定獎池 opens 次，每次沿開啟關係抽到最終道具，統計最終道具與停留的獎池
+func (g *GachaEngine) SimulateOpens(ctx context.Context, pool string, opens int) (GachaOpenOutput, error) {
+	if _, ok := g.model.Pool(pool); !ok {
+		return GachaOpenOutput{}, fmt.Errorf("模型中沒有獎池 %q", pool)
+	}
+	if opens < 0 {
+		return GachaOpenOutput{}, fmt.Errorf("開啟次數不可為負")
+	}
+
+	chunks, err := runChunks(ctx, g.engine, opens, drawChunkSize, func(rng RNG, size int) GachaOpenOutput {
+		chunk := GachaOpenOutput{Opens: size, Stops: make(map[string]int), Items: make(map[string]int)}
+		for i := 0; i < size; i++ {
+			item, stop := openPool(rng, g.model, pool, nil)
+			chunk.Items[item]++
+			chunk.Stops[stop]++
+		}
+		return chunk
+	})
+	if err != nil {
+		return GachaOpenOutput{}, err
+	}
+
+	// 依區塊順序合併
+	output := GachaOpenOutput{Opens: opens, Stops: make(map[string]int), Items: make(map[string]int)}
+	for _, chunk := range chunks {
+		for id, n := range chunk.Stops {
+			output.Stops[id] += n
+		}
+		for name, n := range chunk.Items {
+			output.Items[name] += n
+		}
+	}
+	return output, nil
+}
+
+// drawsToObtain 逐抽開啟入口獎池並立即依配方合成，直到抽到 count 個 item（含開啟下一獎池的中間道具），回傳所需抽數
+// 超過 maxDraws 仍未取得時回傳 maxDraws + 1
+func (g *GachaEngine) drawsToObtain(rng RNG, item string, count int, maxDraws int) int {
+	obtained := 0
+	observe := func(name string) {
+		if name == item {
+			obtained++
+		}
+	}
+
+	items := make(map[string]int)
+	for draws := 1; draws <= maxDraws; draws++ {
+		name, _ := openPool(rng, g.model, g.model.Entry, observe)
+		items[name]++
+		craft(rng, g.model, items, nil, observe)
+
+		if obtained >= count {
+			return draws
+		}
+	}
+	return maxDraws + 1
+}
+
+// play 抽入口獎池 draws 次並依配方合成，回傳各最終道具的數量
+func (g *GachaEngine) play(rng RNG, draws int, prices map[string]int) map[string]int {
+	items := make(map[string]int)
+	for i := 0; i < draws; i++ {
+		item, _ := openPool(rng, g.model, g.model.Entry, nil)
+		items[item]++
+	}
+	craft(rng, g.model, items, prices, nil)
+	return items
+}
+
+// drawReward 根據權重從獎池中抽取一個獎品
+func drawReward(rng RNG, rewards []domain.Reward) domain.Reward {
+	roll := rng.Float64() * 100
+	var cumulative float64
+
+	for _, reward := range rewards {
+		cumulative += reward.Probability
+		if roll < cumulative {
+			return reward
+		}
+	}
+
+	// 應該不會到這裡，但以防萬一返回最後一個
+	return rewards[len(rewards)-1]
+}
+
+// openPool 開啟獎池一次並沿開啟關係抽到最終道具，回傳最終道具與停留的獎池代號
+// 每次抽到的道具（含開啟下一獎池的道具）都會傳給 observe（可為 nil）
+func openPool(rng RNG, model domain.GachaModel, id string, observe func(name string)) (string, string) {
+	for {
+		pool, _ := model.Pool(id)
+		reward := drawReward(rng, pool.Rewards)
+		if observe != nil {
+			observe(reward.Name)
+		}
+		next, ok := model.Opens[reward.Name]
+		if !ok {
+			return reward.Name, id
+		}
+		id = next
+	}
+}
+
+// craft 依配方優先順序盡量合成，合成所得道具會開啟獎池時立即開啟並將最終道具加入 items
+// 開啟所得道具可能再滿足配方，因此重複直到無法再合成（模型驗證已排除合成關係循環）；
+// 模型指定 Assemble 時改以 Assemble 依道具價格合成；開啟獎池時抽到的道具會傳給 observe（可為 nil）
+func craft(rng RNG, model domain.GachaModel, items map[string]int, prices map[string]int, observe func(name string)) {
+	if model.Assemble != nil {
+		model.Assemble(items, prices)
+		return
+	}
+	for crafted := true; crafted; {
+		crafted = false
+		for _, recipe := range model.Recipes {
+			times := -1
+			for input, need := range recipe.Inputs {
+				if n := items[input] / need; times < 0 || n < times {
+					times = n
+				}
+			}
+			if times <= 0 {
+				continue
+			}
+			crafted = true
+
+			for input, need := range recipe.Inputs {
+				items[input] -= times * need
+			}
+			pool, opens := model.Opens[recipe.Output]
+			if !opens {
+				items[recipe.Output] += times
+				continue
+			}
+			for i := 0; i < times; i++ {
+				item, _ := openPool(rng, model, pool, observe)
+				items[item]++
+			}
+		}
+	}
+}
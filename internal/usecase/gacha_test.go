@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"math"
+	"testing"
+)
+
+// expandStarlight 不經由抽獎模型，直接依階段逐層展開星光錦囊的可分割期望數量
+func expandStarlight(event domain.StarlightEvent, draws float64) map[string]float64 {
+	q := domain.Probability(event.Stage1Pool, event.CrystalItem) / 100
+	items := ladderItems(event, draws*q/float64(event.CrystalsPerMerge))
+	for _, reward := range event.Stage1Pool {
+		if reward.Name != event.CrystalItem {
+			items[reward.Name] += draws * reward.Probability / 100
+		}
+	}
+	return items
+}
+
+func TestStarlightModelExpected(t *testing.T) {
+	event := loadTestEvent(t).Starlight
+	calculator := NewStarlightCalculator(event)
+
+	for _, draws := range []float64{0, 1, 35, 100, 1234.5} {
+		want := expandStarlight(event, draws)
+
+		got := event.Model().Expected(draws)
+		expanded := make(map[string]float64)
+		for _, item := range calculator.CalculateExpandedExpected(draws) {
+			expanded[item.Name] += item.Expected
+		}
+
+		for name, w := range want {
+			if math.Abs(got[name]-w) > 1e-9 {
+				t.Errorf("%g 抽 Model().Expected[%s] = %g, want %g", draws, name, got[name], w)
+			}
+			if math.Abs(expanded[name]-w) > 1e-9 {
+				t.Errorf("%g 抽 CalculateExpandedExpected[%s] = %g, want %g", draws, name, expanded[name], w)
+			}
+		}
+		for name, g := range got {
+			if _, ok := want[name]; !ok && g != 0 {
+				t.Errorf("%g 抽 Model().Expected 多出道具 %s = %g", draws, name, g)
+			}
+		}
+	}
+}
+
+func TestZodiacModelExpected(t *testing.T) {
+	event := loadTestEvent(t).Zodiac
+	const draws = 250.0
+
+	// 心願箱需要多種生肖，期望數量不展開，各生肖即抽數 × 機率
+	got := event.Model().Expected(draws)
+	for zodiac, rate := range event.Rates {
+		if want := draws * rate / 100; math.Abs(got[string(zodiac)]-want) > 1e-9 {
+			t.Errorf("Expected[%s] = %g, want %g", zodiac, got[string(zodiac)], want)
+		}
+	}
+	if len(got) != len(event.Rates) {
+		t.Errorf("Expected = %v，道具數應為 %d", got, len(event.Rates))
+	}
+}
+
+func TestGachaEngineSimulateMatchesExpected(t *testing.T) {
+	// 沒有配方時期望數量為線性展開，模擬平均應落在期望數量的 5 個標準誤內
+	model := domain.GachaModel{
+		Name:        "測試",
+		Entry:       "entry",
+		CostPerDraw: 1,
+		Pools: []domain.GachaPool{
+			{ID: "entry", Name: "入口", Rewards: []domain.Reward{{Name: "銅幣", Probability: 70}, {Name: "鑰匙", Probability: 30}}},
+			{ID: "chest", Name: "寶箱", Rewards: []domain.Reward{{Name: "銅幣", Probability: 50}, {Name: "金幣", Probability: 50}}},
+		},
+		Opens: map[string]string{"鑰匙": "chest"},
+	}
+	engine := NewGachaEngine(model, WithSeed(1))
+
+	output, err := engine.Simulate(context.Background(), GachaSimulationInput{Draws: 10, Trials: 20000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Seed != 1 {
+		t.Errorf("Seed = %d, want 1", output.Seed)
+	}
+
+	want := map[string]float64{"銅幣": 8.5, "金幣": 1.5}
+	for _, item := range output.Items {
+		if math.Abs(item.Expected-want[item.Name]) > 1e-9 {
+			t.Errorf("%s: Expected = %g, want %g", item.Name, item.Expected, want[item.Name])
+		}
+		stderr := item.Count.StdDev / math.Sqrt(float64(output.Trials))
+		if math.Abs(item.Count.Mean-item.Expected) > 5*stderr {
+			t.Errorf("%s: 模擬平均 %g, 期望 %g（標準誤 %g）", item.Name, item.Count.Mean, item.Expected, stderr)
+		}
+	}
+}
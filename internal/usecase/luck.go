@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"fmt"
+)
+
+// DefaultLuckTrials 未指定模擬次數時，估計運氣百分位所用的模擬次數
+const DefaultLuckTrials = 10000
+
+// LuckReport 實際結果在相同花費模擬分佈中的位置
+type LuckReport struct {
+	Realized    float64        // 實際結果的總價值
+	RealizedROI float64        // 實際結果的報酬率 (%)
+	Percentile  float64        // 實際總價值在模擬分佈中的百分位 (%)，越高表示運氣越好
+	Better      float64        // 模擬結果優於實際結果的比例 (%)
+	Worse       float64        // 模擬結果差於實際結果的比例 (%)
+	Trials      int            // 模擬次數
+	Value       domain.Summary // 模擬所得總價值分佈
+	ROI         domain.Summary // 模擬所得報酬率分佈 (%)
+}
+
+// newLuckReport 以模擬樣本計算實際總價值的百分位（會排序傳入的樣本）
+func newLuckReport(values []float64, realized float64, cost float64) LuckReport {
+	report := LuckReport{Realized: realized, Trials: len(values)}
+	if cost > 0 {
+		report.RealizedROI = ((realized - cost) / cost) * 100
+	}
+	report.Percentile, report.Better, report.Worse = domain.PercentileRank(values, realized)
+	report.ROI = domain.Summarize(toROI(values, cost))
+	report.Value = domain.Summarize(values)
+	return report
+}
+
+// StarlightLuckInput 星光錦囊運氣百分位輸入
+type StarlightLuckInput struct {
+	Investment float64
+	Method     domain.PurchaseMethod
+	Discount   float64
+	Draws      int // 實際開啟次數，0 表示依投入金額計算（無條件捨去）
+	// Outcome 實際獲得的道具數量（開啟所有星光結晶體之後），
+	// 玲瓏星光為尚未合成的數量，升級道具為尚未開啟的數量
+	Outcome     map[string]int
+	Prices      map[string]int
+	Trials      int  // 模擬次數，0 表示使用預設值
+	Integer     bool // 未合成的玲瓏星光以其價格計價（否則以期望價值計）
+	CarryPoints float64
+}
+
+// StarlightLuckOutput 星光錦囊運氣百分位輸出
+type StarlightLuckOutput struct {
+	Points    float64
+	Purchase  domain.Purchase
+	DrawCount int
+	Luck      LuckReport
+	Seed      int64
+}
+
+// Luck 模擬相同抽數的展開總價值分佈，計算實際結果的百分位
+func (sc *StarlightCalculator) Luck(ctx context.Context, input StarlightLuckInput) (StarlightLuckOutput, error) {
+	// 1. 計算實際結果的總價值（道具需出現在任一獎池中）
+	realized, err := sc.outcomeValue(input.Outcome, input.Prices, input.Integer)
+	if err != nil {
+		return StarlightLuckOutput{}, err
+	}
+
+	// 2. 計算花費與抽數（指定抽數時仍以投入金額計算報酬率）
+	purchase := sc.purchases.Buy(input.Investment, input.Method, input.Discount)
+	points := purchase.Points + input.CarryPoints
+	drawCount := input.Draws
+	if drawCount <= 0 {
+		drawCount = domain.PlanDraws(points, float64(sc.event.CostPerDraw)).Draws
+	}
+
+	// 3. 模擬相同抽數的總價值分佈
+	trials := input.Trials
+	if trials <= 0 {
+		trials = DefaultLuckTrials
+	}
+	values, err := sc.simulateValues(ctx, drawCount, input.Prices, trials, input.Integer)
+	if err != nil {
+		return StarlightLuckOutput{}, err
+	}
+
+	return StarlightLuckOutput{
+		Points:    points,
+		Purchase:  purchase,
+		DrawCount: drawCount,
+		Luck:      newLuckReport(values, realized, purchase.Cost),
+		Seed:      sc.Seed(),
+	}, nil
+}
+
+// outcomeValue 計算實際獲得道具的總價值，計價方式與 simulateValue 一致：
+// 未開啟的升級道具以繼續開啟的期望價值計，未合成的玲瓏星光依 integer 以其價格或期望價值計
+func (sc *StarlightCalculator) outcomeValue(outcome map[string]int, prices map[string]int, integer bool) (float64, error) {
+	upgrades := make(map[string]int)
+	for stage, item := range sc.event.UpgradeItems {
+		upgrades[item] = stage
+	}
+
+	var value float64
+	for item, count := range outcome {
+		if count < 0 {
+			return 0, fmt.Errorf("道具 %q 的數量不可為負", item)
+		}
+		if item != sc.event.CrystalItem && !sc.isObtainable(item) {
+			return 0, fmt.Errorf("道具 %q 不在星光錦囊的獎池中", item)
+		}
+
+		switch stage, isUpgrade := upgrades[item]; {
+		case item == sc.event.CrystalItem && integer:
+			value += float64(count) * float64(prices[item])
+		case item == sc.event.CrystalItem:
+			l1, _ := sc.ladderMoments(2, prices)
+			value += float64(count) * l1 / float64(sc.event.CrystalsPerMerge)
+		case isUpgrade:
+			mean, _ := sc.ladderMoments(stage+1, prices)
+			value += float64(count) * mean
+		default:
+			value += float64(count) * float64(prices[item])
+		}
+	}
+	return value, nil
+}
+
+// ZodiacLuckInput 新年氣息運氣百分位輸入
+type ZodiacLuckInput struct {
+	Investment float64
+	Method     domain.PurchaseMethod
+	Discount   float64
+	Draws      int // 實際抽取次數，0 表示依投入金額計算（無條件捨去）
+	// Outcome 實際結果：心願箱名稱（小吉、中吉…）為已湊成的心願箱數量，
+	// 生肖名稱為尚未湊箱的氣息數量（以最佳組合計價）
+	Outcome     map[string]int
+	BoxValues   domain.BoxValues
+	Trials      int // 模擬次數，0 表示使用預設值
+	CarryPoints float64
+}
+
+// ZodiacLuckOutput 新年氣息運氣百分位輸出
+type ZodiacLuckOutput struct {
+	Points    float64
+	Purchase  domain.Purchase
+	DrawCount int
+	Luck      LuckReport
+	Seed      int64
+}
+
+// Luck 模擬相同抽數的心願箱總價值分佈，計算實際結果的百分位
+func (s *ZodiacSimulator) Luck(ctx context.Context, input ZodiacLuckInput) (ZodiacLuckOutput, error) {
+	// 1. 計算實際結果的總價值
+	realized, err := s.outcomeValue(input.Outcome, input.BoxValues)
+	if err != nil {
+		return ZodiacLuckOutput{}, err
+	}
+
+	// 2. 計算花費與抽數（指定抽數時仍以投入金額計算報酬率）
+	purchase := s.purchases.Buy(input.Investment, input.Method, input.Discount)
+	points := purchase.Points + input.CarryPoints
+	drawCount := input.Draws
+	if drawCount <= 0 {
+		drawCount = domain.PlanDraws(points, s.event.CostPerDraw).Draws
+	}
+
+	// 3. 模擬相同抽數的總價值分佈
+	trials := input.Trials
+	if trials <= 0 {
+		trials = DefaultLuckTrials
+	}
+	_, values, err := s.run(ctx, drawCount, trials, input.BoxValues, nil)
+	if err != nil {
+		return ZodiacLuckOutput{}, err
+	}
+
+	return ZodiacLuckOutput{
+		Points:    points,
+		Purchase:  purchase,
+		DrawCount: drawCount,
+		Luck:      newLuckReport(values, realized, purchase.Cost),
+		Seed:      s.Seed(),
+	}, nil
+}
+
+// outcomeValue 計算已湊成的心願箱與剩餘氣息的總價值
+func (s *ZodiacSimulator) outcomeValue(outcome map[string]int, values domain.BoxValues) (float64, error) {
+	zodiacs := make(map[domain.Zodiac]bool)
+	for _, zodiac := range s.event.Zodiacs() {
+		zodiacs[zodiac] = true
+	}
+	boxTypes := make(map[domain.BoxType]bool)
+	for _, boxType := range s.event.BoxPriority {
+		boxTypes[boxType] = true
+	}
+
+	boxes := domain.NewBoxCollection()
+	breaths := domain.NewBreathCollection()
+	for name, count := range outcome {
+		switch {
+		case count < 0:
+			return 0, fmt.Errorf("%q 的數量不可為負", name)
+		case boxTypes[domain.BoxType(name)]:
+			boxes[domain.BoxType(name)] += float64(count)
+		case zodiacs[domain.Zodiac(name)]:
+			breaths[domain.Zodiac(name)] += float64(count)
+		default:
+			return 0, fmt.Errorf("%q 不是心願箱或生肖名稱", name)
+		}
+	}
+	return boxes.TotalValue(values) + optimizeBoxes(s.event, breaths, values).Value, nil
+}
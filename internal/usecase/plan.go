@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"fmt"
+)
+
+// 點數計畫可使用的活動代號
+const (
+	PlanZodiac    = "zodiac"    // 新年氣息
+	PlanStarlight = "starlight" // 星光錦囊
+)
+
+// PlanStep 點數計畫中的一個活動
+type PlanStep struct {
+	Event  string  // 活動代號（zodiac、starlight）
+	Points float64 // 分配的點數，0 表示使用所有剩餘點數
+}
+
+// PlanInput 點數計畫輸入
+type PlanInput struct {
+	Investment  float64
+	Method      domain.PurchaseMethod
+	Discount    float64
+	CarryPoints float64    // 前次購買結轉的點數
+	Steps       []PlanStep // 依序使用點數的活動
+}
+
+// PlanOutput 點數計畫輸出
+type PlanOutput struct {
+	Purchase domain.Purchase  // 購買方式與點卡組合
+	Plan     domain.PointPlan // 各活動的整數抽數與剩餘點數
+}
+
+// Planner 點數計畫器：同一次購買的點數依序用於多個活動，不足一抽的點數併入下一個活動
+type Planner struct {
+	costs     map[string]float64
+	purchases domain.PurchaseRegistry
+}
+
+// NewPlanner 建立點數計畫器
+func NewPlanner(event domain.EventDefinition, opts ...Option) *Planner {
+	o := newOptions(opts)
+	return &Planner{
+		costs: map[string]float64{
+			PlanZodiac:    event.Zodiac.CostPerDraw,
+			PlanStarlight: float64(event.Starlight.CostPerDraw),
+		},
+		purchases: o.purchases,
+	}
+}
+
+// Plan 購買點數並依序分配給各活動，回傳各活動的整數抽數與最後可結轉的剩餘點數
+func (p *Planner) Plan(input PlanInput) (PlanOutput, error) {
+	// 1. 轉換各活動的每抽點數
+	allocations := make([]domain.PointAllocation, 0, len(input.Steps))
+	for _, step := range input.Steps {
+		cost, ok := p.costs[step.Event]
+		if !ok {
+			return PlanOutput{}, fmt.Errorf("未知的活動 %q", step.Event)
+		}
+		if step.Points < 0 {
+			return PlanOutput{}, fmt.Errorf("活動 %s 分配的點數不可為負", step.Event)
+		}
+		allocations = append(allocations, domain.PointAllocation{
+			Name:        step.Event,
+			CostPerDraw: cost,
+			Points:      step.Points,
+		})
+	}
+
+	// 2. 購買點數並加上結轉點數
+	purchase := p.purchases.Buy(input.Investment, input.Method, input.Discount)
+
+	// 3. 依序以整數抽數使用點數
+	return PlanOutput{
+		Purchase: purchase,
+		Plan:     domain.PlanPoints(purchase.Points+input.CarryPoints, allocations),
+	}, nil
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 預算分配的搜尋範圍
+const (
+	defaultAllocationUnits = 20  // 未指定分配單位時，預算切分的份數
+	maxAllocationUnits     = 500 // 預算切分份數上限
+	defaultPortfolioTrials = 500 // 新年氣息價值模型估計標準差的預設模擬次數
+	maxPortfolioModels     = 4   // 同時分配的活動數量上限
+	// 新年氣息估計標準差的運算量上限
+	maxPortfolioDraws      = 200_000_000 // 模擬次數 × 最多的抽數
+	maxPortfolioAssemblies = 2_000_000   // 模擬次數 × 分配單位數（每個分配金額各湊箱一次）
+)
+
+// Valuation 投入金額於單一活動的價值評估
+type Valuation struct {
+	Investment float64 // 分配的投入金額
+	Cost       float64 // 實際花費（點卡面額時可能低於投入金額）
+	Points     float64 // 可得點數
+	Mean       float64 // 期望價值
+	Variance   float64 // 價值變異數
+}
+
+// ValueModel 活動價值模型：評估投入金額可得的期望價值與變異數
+type ValueModel interface {
+	// Name 活動代號
+	Name() string
+	// Evaluate 評估各投入金額的價值（依 investments 順序）
+	Evaluate(ctx context.Context, investments []float64, method domain.PurchaseMethod, discount float64) ([]Valuation, error)
+}
+
+// zodiacModel 新年氣息價值模型（期望價值為本次購買增加的價值，標準差以模擬估計）
+type zodiacModel struct {
+	calculator *Calculator
+	values     domain.BoxValues
+	inventory  domain.BreathCollection
+	trials     int
+}
+
+// NewZodiacModel 建立新年氣息價值模型，trials 為估計標準差的模擬次數（0 表示使用預設值）
+func NewZodiacModel(calculator *Calculator, values domain.BoxValues, inventory domain.BreathCollection, trials int) ValueModel {
+	if trials <= 0 {
+		trials = defaultPortfolioTrials
+	}
+	return &zodiacModel{calculator: calculator, values: values, inventory: inventory, trials: trials}
+}
+
+// Name 活動代號
+func (m *zodiacModel) Name() string {
+	return PlanZodiac
+}
+
+// Evaluate 評估各投入金額的價值
+// 期望價值為解析解；變異數只模擬一次最多的抽數，各投入金額取同一次模擬的前段抽取結果（共同亂數），
+// 使不同投入金額之間的比較不受模擬誤差影響
+func (m *zodiacModel) Evaluate(ctx context.Context, investments []float64, method domain.PurchaseMethod, discount float64) ([]Valuation, error) {
+	valuations := make([]Valuation, len(investments))
+	drawCounts := make([]int, len(investments))
+	maxDraws := 0
+	for i, investment := range investments {
+		output := m.calculator.CalculateExpected(CalculatorInput{
+			Investment: investment,
+			Method:     method,
+			Discount:   discount,
+			BoxValues:  m.values,
+			Inventory:  m.inventory,
+		})
+		valuations[i] = Valuation{
+			Investment: investment,
+			Cost:       output.Purchase.Cost,
+			Points:     output.Points,
+			Mean:       output.AddedValue,
+		}
+		drawCounts[i] = m.calculator.DrawCount(investment, method, discount, 0)
+		maxDraws = max(maxDraws, drawCounts[i])
+	}
+
+	if float64(m.trials)*float64(maxDraws) > maxPortfolioDraws || float64(m.trials)*float64(len(investments)) > maxPortfolioAssemblies {
+		return nil, fmt.Errorf("新年氣息模擬量過大：模擬次數 × 抽數不可超過 %d，模擬次數 × 分配單位數不可超過 %d", maxPortfolioDraws, maxPortfolioAssemblies)
+	}
+	variances, err := m.calculator.simulator.valueVariances(ctx, drawCounts, m.trials, m.values, m.inventory)
+	if err != nil {
+		return nil, err
+	}
+	for i := range valuations {
+		valuations[i].Variance = variances[i]
+	}
+	return valuations, nil
+}
+
+// starlightModel 星光錦囊價值模型（期望價值與變異數皆為解析解）
+type starlightModel struct {
+	calculator *StarlightCalculator
+	prices     map[string]int
+	integer    bool
+}
+
+// NewStarlightModel 建立星光錦囊價值模型
+func NewStarlightModel(calculator *StarlightCalculator, prices map[string]int, integer bool) ValueModel {
+	return &starlightModel{calculator: calculator, prices: prices, integer: integer}
+}
+
+// Name 活動代號
+func (m *starlightModel) Name() string {
+	return PlanStarlight
+}
+
+// Evaluate 評估各投入金額的價值
+func (m *starlightModel) Evaluate(ctx context.Context, investments []float64, method domain.PurchaseMethod, discount float64) ([]Valuation, error) {
+	sc := m.calculator
+	valuations := make([]Valuation, len(investments))
+	for i, investment := range investments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		purchase := sc.purchases.Buy(investment, method, discount)
+		drawCount := purchase.Points / float64(sc.event.CostPerDraw)
+
+		variance := sc.analyticVariance(drawCount, m.prices)
+		if m.integer {
+			variance = sc.integerMergeVariance(drawCount, m.prices)
+		}
+		valuations[i] = Valuation{
+			Investment: investment,
+			Cost:       purchase.Cost,
+			Points:     purchase.Points,
+			Mean:       sc.CalculateMergedEV(drawCount, m.prices, m.integer),
+			Variance:   variance,
+		}
+	}
+	return valuations, nil
+}
+
+// AllocationInput 預算分配輸入
+type AllocationInput struct {
+	Budget       float64
+	Method       domain.PurchaseMethod
+	Discount     float64
+	Step         float64 // 分配金額的最小單位，0 表示預算的 1/20
+	RiskAversion float64 // 風險趨避係數 λ，目標為 期望價值 − λ·標準差（0 表示只看期望價值）
+}
+
+// Allocation 單一活動的分配結果
+type Allocation struct {
+	Event string
+	Valuation
+	StdDev float64 // 價值標準差
+}
+
+// AllocationOutput 預算分配輸出
+type AllocationOutput struct {
+	Allocations []Allocation // 各活動的分配（依價值模型順序）
+	Unspent     float64      // 未花費的預算（以原金額計價）
+	Mean        float64      // 期望總價值（含未花費的預算）
+	StdDev      float64      // 總價值標準差（各活動視為獨立）
+	Objective   float64      // 目標值：期望總價值 − λ·標準差
+	ROI         float64      // 期望報酬率 (%)，以實際花費計
+	Step        float64      // 分配金額的最小單位
+	Candidates  int          // 比較過的分配組合數
+}
+
+// Allocate 在預算內以 Step 為單位分配各活動的投入金額，求目標值最高的分配
+//
+// 目標值 = Σ 各活動期望價值 + 未花費預算 − λ·√(Σ 各活動變異數)，
+// 未花費的預算以原金額計入，因此期望報酬率為負的活動不會被分配；
+// 各活動的價值模型只在每個分配金額評估一次，再依已用的分配單位數動態規劃：
+// 標準差不可拆分到各活動，因此每個單位數保留期望價值與變異數的柏拉圖最適組合，
+// 被其他組合支配（期望價值不高於且變異數不低於）的組合不論 λ 為何都不會是最佳解。
+func Allocate(ctx context.Context, input AllocationInput, models []ValueModel) (AllocationOutput, error) {
+	// 1. 檢查參數並決定分配單位
+	if input.Budget <= 0 {
+		return AllocationOutput{}, errors.New("預算必須大於 0")
+	}
+	if input.RiskAversion < 0 {
+		return AllocationOutput{}, errors.New("風險趨避係數不可為負")
+	}
+	if len(models) == 0 || len(models) > maxPortfolioModels {
+		return AllocationOutput{}, errors.New("活動數量必須介於 1 與 4 之間")
+	}
+	step := input.Step
+	if step <= 0 {
+		step = input.Budget / defaultAllocationUnits
+	}
+	units := int(math.Floor(input.Budget/step + 1e-9))
+	if units <= 0 || units > maxAllocationUnits {
+		return AllocationOutput{}, errors.New("分配單位必須介於預算的 1/500 與預算之間")
+	}
+
+	// 2. 評估各活動在每個分配金額的價值
+	investments := make([]float64, units)
+	for k := 1; k <= units; k++ {
+		investments[k-1] = float64(k) * step
+	}
+	table := make([][]Valuation, len(models))
+	for i, model := range models {
+		valuations, err := model.Evaluate(ctx, investments, input.Method, input.Discount)
+		if err != nil {
+			return AllocationOutput{}, err
+		}
+		table[i] = append([]Valuation{{}}, valuations...)
+	}
+
+	// 3. 依已用的單位數動態規劃，每個單位數只保留柏拉圖最適的組合
+	states := make([][]portfolioState, units+1)
+	states[0] = []portfolioState{{}}
+	candidates := 0
+	for i := range models {
+		next := make([][]portfolioState, units+1)
+		for used, group := range states {
+			for _, state := range group {
+				for k := 0; used+k <= units; k++ {
+					candidates++
+					next[used+k] = append(next[used+k], portfolioState{
+						gain:     state.gain + table[i][k].Mean - table[i][k].Cost,
+						variance: state.variance + table[i][k].Variance,
+						units:    append(append([]int(nil), state.units...), k),
+					})
+				}
+			}
+		}
+		for used := range next {
+			next[used] = paretoStates(next[used])
+		}
+		states = next
+	}
+
+	// 4. 比較所有單位數的最適組合，保留目標值最高者
+	var best []int
+	bestObjective := math.Inf(-1)
+	for _, group := range states {
+		for _, state := range group {
+			objective := input.Budget + state.gain - input.RiskAversion*math.Sqrt(math.Max(state.variance, 0))
+			if objective > bestObjective {
+				bestObjective = objective
+				best = state.units
+			}
+		}
+	}
+
+	// 5. 彙整最佳分配
+	output := AllocationOutput{
+		Unspent:    input.Budget,
+		Objective:  bestObjective,
+		Step:       step,
+		Candidates: candidates,
+	}
+	var variance, cost, value float64
+	for i, k := range best {
+		valuation := table[i][k]
+		output.Allocations = append(output.Allocations, Allocation{
+			Event:     models[i].Name(),
+			Valuation: valuation,
+			StdDev:    math.Sqrt(math.Max(valuation.Variance, 0)),
+		})
+		output.Unspent -= valuation.Cost
+		variance += valuation.Variance
+		cost += valuation.Cost
+		value += valuation.Mean
+	}
+	output.Mean = value + output.Unspent
+	output.StdDev = math.Sqrt(math.Max(variance, 0))
+	if cost > 0 {
+		output.ROI = (value - cost) / cost * 100
+	}
+	return output, nil
+}
+
+// portfolioState 前幾個活動的分配組合
+type portfolioState struct {
+	gain     float64 // 期望價值 − 實際花費
+	variance float64 // 價值變異數
+	units    []int   // 各活動分配的單位數
+}
+
+// paretoStates 保留期望價值與變異數的柏拉圖最適組合（依變異數由小到大）
+func paretoStates(states []portfolioState) []portfolioState {
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].variance != states[j].variance {
+			return states[i].variance < states[j].variance
+		}
+		return states[i].gain > states[j].gain
+	})
+
+	var frontier []portfolioState
+	for _, state := range states {
+		if len(frontier) == 0 || state.gain > frontier[len(frontier)-1].gain {
+			frontier = append(frontier, state)
+		}
+	}
+	return frontier
+}
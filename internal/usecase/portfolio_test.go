@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// tableModel 依分配單位數查表的價值模型
+type tableModel struct {
+	name       string
+	valuations []Valuation // 第 k 個為分配 k+1 個單位的價值
+}
+
+func (m *tableModel) Name() string {
+	return m.name
+}
+
+func (m *tableModel) Evaluate(ctx context.Context, investments []float64, method domain.PurchaseMethod, discount float64) ([]Valuation, error) {
+	return m.valuations[:len(investments)], nil
+}
+
+// bruteForceAllocation 列舉所有分配單位數的組合，回傳最高的目標值
+func bruteForceAllocation(budget, lambda float64, units int, models []*tableModel) float64 {
+	best := math.Inf(-1)
+	var search func(i, used int, gain, variance float64)
+	search = func(i, used int, gain, variance float64) {
+		if i == len(models) {
+			best = math.Max(best, budget+gain-lambda*math.Sqrt(variance))
+			return
+		}
+		search(i+1, used, gain, variance)
+		for k := 1; used+k <= units; k++ {
+			v := models[i].valuations[k-1]
+			search(i+1, used+k, gain+v.Mean-v.Cost, variance+v.Variance)
+		}
+	}
+	search(0, 0, 0, 0)
+	return best
+}
+
+func TestAllocateMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		units := 1 + rng.Intn(10)
+		step := 100.0
+		budget := float64(units) * step
+
+		var models []*tableModel
+		var valueModels []ValueModel
+		for j := 0; j < 1+rng.Intn(3); j++ {
+			model := &tableModel{name: string(rune('A' + j))}
+			for k := 1; k <= units; k++ {
+				cost := float64(k) * step
+				model.valuations = append(model.valuations, Valuation{
+					Investment: cost,
+					Cost:       cost,
+					Mean:       cost * (0.5 + rng.Float64()),
+					Variance:   cost * cost * rng.Float64(),
+				})
+			}
+			models = append(models, model)
+			valueModels = append(valueModels, model)
+		}
+		lambda := []float64{0, 0.5, 1, 3}[rng.Intn(4)]
+
+		output, err := Allocate(context.Background(), AllocationInput{Budget: budget, Step: step, RiskAversion: lambda}, valueModels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := bruteForceAllocation(budget, lambda, units, models)
+		if math.Abs(output.Objective-want) > 1e-6 {
+			t.Fatalf("第 %d 組（%d 單位、λ = %g）：Objective = %g, want %g", i, units, lambda, output.Objective, want)
+		}
+
+		// 回報的分配與目標值一致
+		if got := output.Mean - lambda*output.StdDev; math.Abs(got-output.Objective) > 1e-6 {
+			t.Fatalf("第 %d 組：期望 %g − λ·標準差 %g = %g，與目標值 %g 不一致", i, output.Mean, output.StdDev, got, output.Objective)
+		}
+		if output.Unspent < -1e-9 {
+			t.Fatalf("第 %d 組：分配超過預算，未花費 %g", i, output.Unspent)
+		}
+	}
+}
+
+func TestAllocateRejectsInvalidInput(t *testing.T) {
+	model := &tableModel{name: "A", valuations: make([]Valuation, maxAllocationUnits+1)}
+	tests := []struct {
+		name   string
+		input  AllocationInput
+		models []ValueModel
+	}{
+		{"預算為 0", AllocationInput{Budget: 0}, []ValueModel{model}},
+		{"風險趨避係數為負", AllocationInput{Budget: 100, RiskAversion: -1}, []ValueModel{model}},
+		{"沒有活動", AllocationInput{Budget: 100}, nil},
+		{"分配單位過細", AllocationInput{Budget: 1000, Step: 1}, []ValueModel{model}},
+		{"分配單位大於預算", AllocationInput{Budget: 100, Step: 200}, []ValueModel{model}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Allocate(context.Background(), tt.input, tt.models); err == nil {
+				t.Error("應回傳錯誤")
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"fmt"
+	"math"
+)
+
+// 計算器使用的獎池機率
+const (
+	RatesPublished = "published" // 公告機率
+	RatesMean      = "mean"      // 事後平均機率
+	RatesSample    = "sample"    // 由事後分佈抽樣的一組機率
+)
+
+// EstimateInput 事後機率估計輸入
+type EstimateInput struct {
+	Log         domain.PullLog
+	PriorWeight *float64 // 公告機率先驗相當於的抽數，nil 表示使用預設值，0 表示不使用先驗（僅依紀錄）
+	Level       float64  // 可信區間的機率（0~1），0 表示使用預設值
+}
+
+// EstimateOutput 事後機率估計輸出
+type EstimateOutput struct {
+	Posteriors []domain.PosteriorRates // 各獎池的事後分佈（依紀錄中首次出現的順序）
+}
+
+// Estimate 以公告機率為 Dirichlet 先驗，結合抽取紀錄估計各獎池的事後機率與可信區間
+func (t *RateTester) Estimate(input EstimateInput) (EstimateOutput, error) {
+	// 1. 檢查抽取紀錄並套用預設參數
+	if err := input.Log.Validate(); err != nil {
+		return EstimateOutput{}, err
+	}
+	weight := float64(domain.DefaultPriorWeight)
+	if input.PriorWeight != nil {
+		weight = *input.PriorWeight
+	}
+	if weight < 0 {
+		return EstimateOutput{}, fmt.Errorf("先驗強度不可為負: %g", weight)
+	}
+	level := input.Level
+	if level == 0 {
+		level = domain.DefaultCredibleLevel
+	}
+
+	// 2. 依獎池合計紀錄並計算事後分佈
+	var output EstimateOutput
+	for _, pool := range input.Log.Pools() {
+		name, rewards, err := t.event.PoolByID(pool)
+		if err != nil {
+			return EstimateOutput{}, err
+		}
+		counts, sessions := input.Log.Totals(pool)
+		posterior, err := domain.NewPosteriorRates(pool, name, rewards, counts, sessions, weight, level)
+		if err != nil {
+			return EstimateOutput{}, err
+		}
+		output.Posteriors = append(output.Posteriors, posterior)
+	}
+	return output, nil
+}
+
+// PosteriorEvent 建立以事後機率取代紀錄中各獎池機率的活動定義，供計算器與模擬器使用
+// mode 為 RatesMean 時使用事後平均機率，RatesSample 時以檢定器的種子由事後分佈抽樣一組機率，
+// RatesPublished 時回傳原活動定義
+func (t *RateTester) PosteriorEvent(ctx context.Context, input EstimateInput, mode string) (domain.EventDefinition, error) {
+	if mode == RatesPublished {
+		return t.event, nil
+	}
+	if mode != RatesMean && mode != RatesSample {
+		return domain.EventDefinition{}, fmt.Errorf("未知的機率模式 %q（可用：%s, %s, %s）", mode, RatesPublished, RatesMean, RatesSample)
+	}
+
+	output, err := t.Estimate(input)
+	if err != nil {
+		return domain.EventDefinition{}, err
+	}
+
+	// 抽樣時所有獎池共用一個亂數串流，相同種子可重現；
+	// 串流與 runChunks 的模擬序號分開，避免與以相同種子建立的計算器第一次模擬使用同一串流
+	rates := make([]map[string]float64, len(output.Posteriors))
+	for i, posterior := range output.Posteriors {
+		rates[i] = posterior.Means()
+	}
+	if mode == RatesSample {
+		if err := ctx.Err(); err != nil {
+			return domain.EventDefinition{}, err
+		}
+		rng := t.engine.source(deriveSeed(t.engine.seed, posteriorStream, 0))
+		for i, posterior := range output.Posteriors {
+			rates[i] = sampleDirichlet(rng, posterior)
+		}
+	}
+
+	event := t.event
+	for i, posterior := range output.Posteriors {
+		if event, err = event.WithPoolRates(posterior.Pool, rates[i]); err != nil {
+			return domain.EventDefinition{}, err
+		}
+	}
+	return event, nil
+}
+
+// posteriorStream 事後機率抽樣使用的串流序號（runChunks 的模擬序號由 1 遞增，不會用到）
+const posteriorStream = 1 << 63
+
+// sampleDirichlet 由事後 Dirichlet 分佈抽樣一組道具機率（0~1）
+// 以各道具獨立的 Gamma(αᵢ, 1) 樣本除以總和取得
+func sampleDirichlet(rng RNG, posterior domain.PosteriorRates) map[string]float64 {
+	samples := make([]float64, len(posterior.Items))
+	var total float64
+	for i, alpha := range posterior.Alphas() {
+		samples[i] = sampleGamma(rng, alpha)
+		total += samples[i]
+	}
+
+	rates := make(map[string]float64, len(samples))
+	for i, item := range posterior.Items {
+		if total > 0 {
+			rates[item.Item] = samples[i] / total
+		}
+	}
+	return rates
+}
+
+// sampleGamma 以 Marsaglia-Tsang 法抽樣 Gamma(shape, 1)
+// shape < 1 時以 Gamma(shape + 1) · U^(1/shape) 計算
+func sampleGamma(rng RNG, shape float64) float64 {
+	if shape <= 0 {
+		return 0
+	}
+	if shape < 1 {
+		return sampleGamma(rng, shape+1) * math.Pow(1-rng.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := sampleNormal(rng)
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := 1 - rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// sampleNormal 以 Box-Muller 法抽樣標準常態分佈
+func sampleNormal(rng RNG) float64 {
+	u1 := 1 - rng.Float64()
+	u2 := rng.Float64()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
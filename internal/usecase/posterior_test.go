@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"math"
+	"testing"
+)
+
+func TestEstimatePriorWeight(t *testing.T) {
+	tester := NewRateTester(loadTestEvent(t))
+	log := domain.PullLog{{Pool: domain.PoolZodiac, Counts: map[string]int{"兔": 30, "龍": 10}}}
+	flat, negative := 0.0, -1.0
+
+	tests := []struct {
+		name        string
+		priorWeight *float64
+		want        float64 // 事後先驗強度
+		rabbitMean  float64 // 兔的事後平均機率 (%)，負值表示不檢查
+	}{
+		{"未指定時使用預設值", nil, domain.DefaultPriorWeight, -1},
+		{"0 表示僅依紀錄", &flat, 0, 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tester.Estimate(EstimateInput{Log: log, PriorWeight: tt.priorWeight})
+			if err != nil {
+				t.Fatal(err)
+			}
+			posterior := output.Posteriors[0]
+			if posterior.PriorWeight != tt.want {
+				t.Errorf("PriorWeight = %g, want %g", posterior.PriorWeight, tt.want)
+			}
+			if tt.rabbitMean < 0 {
+				return
+			}
+			for _, item := range posterior.Items {
+				if item.Item == "兔" && math.Abs(item.Mean-tt.rabbitMean) > 1e-9 {
+					t.Errorf("兔的事後平均 = %g%%, want %g%%", item.Mean, tt.rabbitMean)
+				}
+			}
+		})
+	}
+
+	if _, err := tester.Estimate(EstimateInput{Log: log, PriorWeight: &negative}); err == nil {
+		t.Error("先驗強度為負時應回傳錯誤")
+	}
+}
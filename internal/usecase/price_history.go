@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PriceHistoryRepository 價格歷史的保存位置
+type PriceHistoryRepository interface {
+	// Load 讀取價格歷史
+	Load() (domain.PriceHistory, error)
+	// Save 寫出價格歷史
+	Save(history domain.PriceHistory) error
+}
+
+// PriceHistoryStore 價格歷史讀寫
+type PriceHistoryStore struct {
+	mu   sync.Mutex
+	repo PriceHistoryRepository
+}
+
+// NewPriceHistoryStore 建立價格歷史讀寫
+func NewPriceHistoryStore(repo PriceHistoryRepository) *PriceHistoryStore {
+	return &PriceHistoryStore{repo: repo}
+}
+
+// History 讀取價格歷史
+func (s *PriceHistoryStore) History() (domain.PriceHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repo.Load()
+}
+
+// Record 記錄某日的道具價格並寫出，回傳更新後的價格歷史
+func (s *PriceHistoryStore) Record(date time.Time, prices domain.PriceBook) (domain.PriceHistory, error) {
+	if len(prices) == 0 {
+		return nil, errors.New("價格快照至少需要一個道具")
+	}
+	if err := prices.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, err := s.repo.Load()
+	if err != nil {
+		return nil, err
+	}
+	history = history.Record(date, prices)
+	if err := s.repo.Save(history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// SeriesInput 價值時間序列輸入
+type SeriesInput struct {
+	Event      string // 活動代號（PlanZodiac 或 PlanStarlight）
+	Investment float64
+	Method     domain.PurchaseMethod
+	Discount   float64
+	Inventory  domain.BreathCollection // 新年氣息已持有的氣息
+	From       time.Time               // 起始日期（含），零值表示不限
+	To         time.Time               // 結束日期（含），零值表示不限
+}
+
+// SeriesPoint 單一快照日期的期望價值
+type SeriesPoint struct {
+	Date          time.Time
+	ExpectedValue float64 // 期望價值（新年氣息為本次購買增加的價值）
+	ROI           float64 // 期望報酬率 (%)
+}
+
+// SeriesOutput 價值時間序列輸出
+type SeriesOutput struct {
+	Event     string
+	Purchase  domain.Purchase
+	DrawCount float64
+	Points    []SeriesPoint // 依日期排序
+}
+
+// HistoryValuer 以價格歷史評估固定投入金額在各快照日期的期望價值
+type HistoryValuer struct {
+	calculator *Calculator
+	starlight  *StarlightCalculator
+}
+
+// NewHistoryValuer 建立價格歷史評估器
+func NewHistoryValuer(calculator *Calculator, starlight *StarlightCalculator) *HistoryValuer {
+	return &HistoryValuer{calculator: calculator, starlight: starlight}
+}
+
+// Series 計算 From 至 To 之間每個快照日期的期望價值與報酬率
+// 各道具的價格為該日（含）之前最後一次記錄的價格，抽數與實際花費不隨日期變動
+func (v *HistoryValuer) Series(history domain.PriceHistory, input SeriesInput) (SeriesOutput, error) {
+	// 1. 檢查參數
+	if input.Investment <= 0 {
+		return SeriesOutput{}, errors.New("投入金額必須大於 0")
+	}
+	if !input.From.IsZero() && !input.To.IsZero() && input.To.Before(input.From) {
+		return SeriesOutput{}, errors.New("結束日期不可早於起始日期")
+	}
+
+	// 2. 依活動建立各日期的評估方式
+	output := SeriesOutput{Event: input.Event}
+	var evaluate func(date time.Time) (value, roi float64)
+	switch input.Event {
+	case PlanZodiac:
+		calculatorInput := CalculatorInput{
+			Investment: input.Investment,
+			Method:     input.Method,
+			Discount:   input.Discount,
+			Inventory:  input.Inventory,
+		}
+		expected := v.calculator.CalculateExpected(calculatorInput)
+		output.Purchase = expected.Purchase
+		output.DrawCount = expected.DrawCount
+		evaluate = func(date time.Time) (float64, float64) {
+			result := v.calculator.CalculateExpectedAt(history, date, calculatorInput)
+			return result.AddedValue, result.ROI
+		}
+	case PlanStarlight:
+		sc := v.starlight
+		output.Purchase = sc.purchases.Buy(input.Investment, input.Method, input.Discount)
+		output.DrawCount = output.Purchase.Points / float64(sc.event.CostPerDraw)
+		evaluate = func(date time.Time) (float64, float64) {
+			value := sc.CalculateExpandedEVAt(history, date, output.DrawCount)
+			roi := 0.0
+			if output.Purchase.Cost > 0 {
+				roi = (value - output.Purchase.Cost) / output.Purchase.Cost * 100
+			}
+			return value, roi
+		}
+	default:
+		return SeriesOutput{}, fmt.Errorf("未知的活動 %q", input.Event)
+	}
+
+	// 3. 依序評估每個快照日期
+	for _, date := range history.Dates(input.From, input.To) {
+		value, roi := evaluate(date)
+		output.Points = append(output.Points, SeriesPoint{Date: date, ExpectedValue: value, ROI: roi})
+	}
+	return output, nil
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"sync"
+)
+
+// PriceRepository 道具價格表的保存位置
+type PriceRepository interface {
+	// Load 讀取價格表
+	Load() (domain.PriceBook, error)
+	// Save 寫出價格表
+	Save(prices domain.PriceBook) error
+}
+
+// PriceStore 道具價格表讀寫（CLI、伺服器與網頁共用同一份價格表）
+type PriceStore struct {
+	mu    sync.Mutex
+	repo  PriceRepository
+	items []string
+}
+
+// NewPriceStore 建立道具價格表讀寫，items 為缺少時補上價格 0 的道具
+func NewPriceStore(repo PriceRepository, items []string) *PriceStore {
+	return &PriceStore{repo: repo, items: items}
+}
+
+// Items 缺少時補上價格 0 的道具（依活動定義順序）
+func (s *PriceStore) Items() []string {
+	return s.items
+}
+
+// Prices 讀取價格表（缺少的道具價格為 0）
+func (s *PriceStore) Prices() (domain.PriceBook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prices, err := s.repo.Load()
+	if err != nil {
+		return nil, err
+	}
+	return prices.WithDefaults(s.items), nil
+}
+
+// Update 以 prices 取代價格表並寫出，回傳補上預設值後的價格表
+func (s *PriceStore) Update(prices domain.PriceBook) (domain.PriceBook, error) {
+	if err := prices.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.repo.Save(prices); err != nil {
+		return nil, err
+	}
+	return prices.WithDefaults(s.items), nil
+}
+
+// Merge 將 prices 中的道具價格寫入價格表（其餘道具維持不變）並寫出，回傳補上預設值後的價格表
+// 讀取與寫出在同一個鎖內完成，同時更新不同道具時不會互相覆寫
+func (s *PriceStore) Merge(prices domain.PriceBook) (domain.PriceBook, error) {
+	if err := prices.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.repo.Load()
+	if err != nil {
+		return nil, err
+	}
+	merged := make(domain.PriceBook, len(current)+len(prices))
+	for item, price := range current {
+		merged[item] = price
+	}
+	for item, price := range prices {
+		merged[item] = price
+	}
+
+	if err := s.repo.Save(merged); err != nil {
+		return nil, err
+	}
+	return merged.WithDefaults(s.items), nil
+}
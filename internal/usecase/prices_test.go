@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// memoryPrices 保存在記憶體中的價格表
+type memoryPrices struct {
+	prices domain.PriceBook
+}
+
+func (m *memoryPrices) Load() (domain.PriceBook, error) {
+	prices := make(domain.PriceBook, len(m.prices))
+	for item, price := range m.prices {
+		prices[item] = price
+	}
+	return prices, nil
+}
+
+func (m *memoryPrices) Save(prices domain.PriceBook) error {
+	m.prices = prices
+	return nil
+}
+
+func TestPriceStoreMergeKeepsOtherItems(t *testing.T) {
+	repo := &memoryPrices{prices: domain.PriceBook{"超越": 20000, "璀璨星光": 50000}}
+	store := NewPriceStore(repo, []string{"小吉"})
+
+	saved, err := store.Merge(domain.PriceBook{"超越": 25000, "星力17星強化券": 3000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.PriceBook{"超越": 25000, "璀璨星光": 50000, "星力17星強化券": 3000}
+	for item, price := range want {
+		if repo.prices[item] != price {
+			t.Errorf("保存的 %s = %d, want %d", item, repo.prices[item], price)
+		}
+	}
+	if len(repo.prices) != len(want) {
+		t.Errorf("保存的價格表 = %v, want %v", repo.prices, want)
+	}
+	if price, ok := saved["小吉"]; !ok || price != 0 {
+		t.Errorf("回傳的價格表應補上缺少的道具，小吉 = %d, %v", price, ok)
+	}
+
+	if _, err := store.Merge(domain.PriceBook{"超越": -1}); err == nil {
+		t.Error("負價格應回傳錯誤")
+	}
+}
+
+func TestPriceStoreConcurrentMerge(t *testing.T) {
+	repo := &memoryPrices{prices: domain.PriceBook{}}
+	store := NewPriceStore(repo, nil)
+
+	// 同時更新不同道具時，每個道具的價格都應保留
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := store.Merge(domain.PriceBook{fmt.Sprintf("道具%d", i): i + 1}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(repo.prices) != 50 {
+		t.Errorf("價格表有 %d 個道具, want 50", len(repo.prices))
+	}
+}
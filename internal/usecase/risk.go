@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"math"
+)
+
+// DefaultRiskTrials 未指定模擬次數時，估計百分位數所用的模擬次數
+const DefaultRiskTrials = 2000
+
+// RiskReport 期望值的分散程度
+type RiskReport struct {
+	Analytic  bool           // 變異數是否為解析解（否則為模擬估計）
+	Variance  float64        // 總價值變異數
+	StdDev    float64        // 總價值標準差
+	ROIStdDev float64        // 報酬率標準差 (%)
+	Trials    int            // 百分位數所用的模擬次數
+	Value     domain.Summary // 模擬所得總價值分佈
+	ROI       domain.Summary // 模擬所得報酬率分佈 (%)
+}
+
+// newRiskReport 由模擬樣本建立風險報告（變異數亦以模擬估計）
+func newRiskReport(investment float64, value, roi domain.Summary, trials int) RiskReport {
+	report := RiskReport{
+		Variance: value.Variance,
+		StdDev:   value.StdDev,
+		Trials:   trials,
+		Value:    value,
+		ROI:      roi,
+	}
+	if investment > 0 {
+		report.ROIStdDev = report.StdDev / investment * 100
+	}
+	return report
+}
+
+// withAnalyticVariance 以解析解取代模擬估計的變異數
+func (r RiskReport) withAnalyticVariance(investment float64, variance float64) RiskReport {
+	r.Analytic = true
+	r.Variance = variance
+	r.StdDev = math.Sqrt(math.Max(variance, 0))
+	r.ROIStdDev = 0
+	if investment > 0 {
+		r.ROIStdDev = r.StdDev / investment * 100
+	}
+	return r
+}
+
+// toROI 將總價值樣本轉換為報酬率樣本 (%)
+func toROI(values []float64, investment float64) []float64 {
+	rois := make([]float64, len(values))
+	if investment <= 0 {
+		return rois
+	}
+	for i, value := range values {
+		rois[i] = ((value - investment) / investment) * 100
+	}
+	return rois
+}
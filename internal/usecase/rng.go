@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"fmt"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"runtime"
+	"time"
+)
+
+// RNG 模擬使用的亂數產生器，可替換為不同的亂數來源
+type RNG interface {
+	// Float64 回傳 [0, 1) 之間的亂數
+	Float64() float64
+}
+
+// RNGSource 依種子建立亂數產生器
+type RNGSource func(seed int64) RNG
+
+// 可用的亂數來源名稱
+const (
+	SourceMathRand = "mathrand" // math/rand
+	SourcePCG      = "pcg"      // math/rand/v2 PCG
+)
+
+// RNGSources 依名稱對照的亂數來源
+var RNGSources = map[string]RNGSource{
+	SourceMathRand: NewMathRand,
+	SourcePCG:      NewPCG,
+}
+
+// ParseRNGSource 依名稱取得亂數來源
+func ParseRNGSource(name string) (RNGSource, error) {
+	source, ok := RNGSources[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的亂數來源 %q", name)
+	}
+	return source, nil
+}
+
+// NewMathRand 建立以 math/rand 為來源的亂數產生器
+func NewMathRand(seed int64) RNG {
+	return rand.New(rand.NewSource(seed))
+}
+
+// NewPCG 建立以 math/rand/v2 PCG 為來源的亂數產生器
+func NewPCG(seed int64) RNG {
+	return randv2.New(randv2.NewPCG(uint64(seed), pcgStream))
+}
+
+// pcgStream PCG 第二組種子（固定值，使同一種子產生相同序列）
+const pcgStream = 0x9e3779b97f4a7c15
+
+// maxSafeSeed 自動產生種子的上限（2^53 - 1），確保經 JSON 傳給瀏覽器後仍能完整還原
+const maxSafeSeed = 1<<53 - 1
+
+// NewSeed 以目前時間產生種子
+func NewSeed() int64 {
+	return time.Now().UnixNano() & maxSafeSeed
+}
+
+// Option 計算器與模擬器的建立選項
+type Option func(*options)
+
+// options 建立選項
+type options struct {
+	seed      int64
+	source    RNGSource
+	workers   int
+	purchases domain.PurchaseRegistry
+}
+
+// WithSeed 指定亂數種子，相同種子可重現相同的模擬結果
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+// WithSource 指定亂數來源（預設為 math/rand）
+func WithSource(source RNGSource) Option {
+	return func(o *options) {
+		if source != nil {
+			o.source = source
+		}
+	}
+}
+
+// WithWorkers 指定平行模擬的 worker 數量（預設為 GOMAXPROCS）
+// worker 數量只影響執行速度，不影響模擬結果
+func WithWorkers(workers int) Option {
+	return func(o *options) {
+		if workers > 0 {
+			o.workers = workers
+		}
+	}
+}
+
+// WithPurchases 指定購買方式規則表（預設為 domain.DefaultPurchaseRegistry）
+func WithPurchases(purchases domain.PurchaseRegistry) Option {
+	return func(o *options) {
+		if purchases != nil {
+			o.purchases = purchases
+		}
+	}
+}
+
+// newOptions 套用建立選項，未指定種子時以目前時間產生
+func newOptions(opts []Option) options {
+	o := options{
+		seed:      NewSeed(),
+		source:    NewMathRand,
+		workers:   runtime.GOMAXPROCS(0),
+		purchases: domain.DefaultPurchaseRegistry(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
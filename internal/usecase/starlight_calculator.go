@@ -2,22 +2,62 @@ package usecase
 
 import (
 	"MSCashItemExpected/internal/domain"
-	"math/rand"
+	"context"
 	"time"
 )
 
 // StarlightCalculator 星光錦囊計算器
 type StarlightCalculator struct {
-	rng *rand.Rand
+	event     domain.StarlightEvent
+	gacha     *GachaEngine // 活動對應的多階段抽獎模型與模擬引擎
+	purchases domain.PurchaseRegistry
 }
 
 // NewStarlightCalculator 建立新的計算器
-func NewStarlightCalculator() *StarlightCalculator {
+func NewStarlightCalculator(event domain.StarlightEvent, opts ...Option) *StarlightCalculator {
+	o := newOptions(opts)
 	return &StarlightCalculator{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		event:     event,
+		gacha:     &GachaEngine{model: event.Model(), engine: newEngine(o)},
+		purchases: o.purchases,
 	}
 }
 
+// WithSeed 以相同活動定義與引擎設定，建立使用指定種子的新計算器
+func (sc *StarlightCalculator) WithSeed(seed int64) *StarlightCalculator {
+	return &StarlightCalculator{
+		event:     sc.event,
+		gacha:     sc.gacha.WithSeed(seed),
+		purchases: sc.purchases,
+	}
+}
+
+// Seed 取得計算器使用的亂數種子
+func (sc *StarlightCalculator) Seed() int64 {
+	return sc.gacha.Seed()
+}
+
+// Event 取得計算器使用的活動定義
+func (sc *StarlightCalculator) Event() domain.StarlightEvent {
+	return sc.event
+}
+
+// Model 取得計算器使用的多階段抽獎模型
+func (sc *StarlightCalculator) Model() domain.GachaModel {
+	return sc.gacha.Model()
+}
+
+// Purchases 取得計算器使用的購買方式規則表
+func (sc *StarlightCalculator) Purchases() domain.PurchaseRegistry {
+	return sc.purchases
+}
+
+// DrawCount 計算投入金額加上結轉點數實際可抽的整數次數
+func (sc *StarlightCalculator) DrawCount(investment float64, method domain.PurchaseMethod, discount float64, carryPoints float64) int {
+	purchase := sc.purchases.Buy(investment, method, discount)
+	return domain.PlanDraws(purchase.Points+carryPoints, float64(sc.event.CostPerDraw)).Draws
+}
+
 // CalculateEV 計算獎池的期望值
 // 公式：EV = Σ(機率 × 價格)
 func (sc *StarlightCalculator) CalculateEV(pool []domain.Reward) float64 {
@@ -45,85 +85,40 @@ type ExpandedItem struct {
 
 // CalculateExpandedExpected 計算展開所有階段後的期望道具數量
 // 會將「玲瓏星光」展開到星光結晶體、星光原石、星光水晶、璀璨星光
+// 玲瓏星光視為可分割（期望數量直接除以合成數量）
 func (sc *StarlightCalculator) CalculateExpandedExpected(drawCount float64) []ExpandedItem {
-	items := make(map[string]float64)
-
-	// 輔助函數：累加道具
-	addItem := func(name string, count float64) {
-		items[name] += count
-	}
-
-	// 第一階段：星光錦囊
-	for _, reward := range domain.Stage1Pool {
-		if reward.Name == "玲瓏星光" {
-			// 玲瓏星光需要展開
-			// 4個玲瓏星光 = 1個星光結晶體
-			crystalCount := drawCount * (reward.Probability / 100) / 4
-
-			// 第二階段：星光結晶體
-			for _, r2 := range domain.StagePools[2] {
-				if r2.Name == "星光原石" {
-					roughCount := crystalCount * (r2.Probability / 100)
-
-					// 第三階段：星光原石
-					for _, r3 := range domain.StagePools[3] {
-						if r3.Name == "星光水晶" {
-							pureCount := roughCount * (r3.Probability / 100)
-
-							// 第四階段：星光水晶
-							for _, r4 := range domain.StagePools[4] {
-								if r4.Name == "璀璨星光" {
-									brilliantCount := pureCount * (r4.Probability / 100)
-
-									// 第五階段：璀璨星光（最終獎品）
-									for _, r5 := range domain.StagePools[5] {
-										addItem(r5.Name, brilliantCount*(r5.Probability/100))
-									}
-								} else {
-									addItem(r4.Name, pureCount*(r4.Probability/100))
-								}
-							}
-						} else {
-							addItem(r3.Name, roughCount*(r3.Probability/100))
-						}
-					}
-				} else {
-					addItem(r2.Name, crystalCount*(r2.Probability/100))
-				}
-			}
-		} else {
-			addItem(reward.Name, drawCount*(reward.Probability/100))
-		}
-	}
-
-	// 轉換為 slice
-	var result []ExpandedItem
-	for name, expected := range items {
-		result = append(result, ExpandedItem{Name: name, Expected: expected})
-	}
-
-	return result
+	items, _ := sc.CalculateMergedExpected(drawCount, false)
+	return items
 }
 
 // SimulateStage1 第一階段模擬器
 // 模擬大量開啟第一階段錦囊後的結果分佈
-func (sc *StarlightCalculator) SimulateStage1(count int, pool []domain.Reward) domain.SimulationResult {
-	results := make(map[string]int)
-	crystalCount := 0
+func (sc *StarlightCalculator) SimulateStage1(ctx context.Context, count int, pool []domain.Reward) (domain.SimulationResult, error) {
+	chunks, err := runChunks(ctx, sc.gacha.engine, count, drawChunkSize, func(rng RNG, size int) map[string]int {
+		results := make(map[string]int)
+		for i := 0; i < size; i++ {
+			results[drawReward(rng, pool).Name]++
+		}
+		return results
+	})
+	if err != nil {
+		return domain.SimulationResult{}, err
+	}
 
-	for i := 0; i < count; i++ {
-		reward := sc.drawFromPool(pool)
-		results[reward.Name]++
-		if reward.Name == "玲瓏星光" {
-			crystalCount++
+	// 依區塊順序合併
+	results := make(map[string]int)
+	for _, chunk := range chunks {
+		for name, n := range chunk {
+			results[name] += n
 		}
 	}
+	crystalCount := results[sc.event.CrystalItem]
 
 	// 計算理論期望的玲瓏星光數量
-	theoreticalCrystal := float64(count) * (10.0 / 100) // 10% 機率
+	theoreticalCrystal := float64(count) * (domain.Probability(pool, sc.event.CrystalItem) / 100)
 
 	// 計算總投入成本
-	totalCost := count * domain.StarlightCost
+	totalCost := count * sc.event.CostPerDraw
 
 	return domain.SimulationResult{
 		DrawCount:     count,
@@ -131,86 +126,35 @@ func (sc *StarlightCalculator) SimulateStage1(count int, pool []domain.Reward) d
 		CrystalCount:  crystalCount,
 		TheoreticalEV: theoreticalCrystal,
 		TotalCost:     totalCost,
-	}
+	}, nil
 }
 
 // SimulateLadder 階梯升級模擬器
-// 處理從第二階段到第五階段的邏輯
-func (sc *StarlightCalculator) SimulateLadder(initialCount int) domain.LadderResult {
-	result := domain.LadderResult{
-		InitialCount: initialCount,
-		Rewards:      make(map[string]int),
+// 以抽獎引擎開啟 initialCount 個星光結晶體，每個沿模型的開啟關係逐階段升級，直到抽到非升級道具或抵達最終階段
+func (sc *StarlightCalculator) SimulateLadder(ctx context.Context, initialCount int) (domain.LadderResult, error) {
+	output, err := sc.gacha.SimulateOpens(ctx, sc.ladderPool(), initialCount)
+	if err != nil {
+		return domain.LadderResult{}, err
 	}
 
-	// 當前持有的錦囊數量（從第2階段開始）
-	currentCount := initialCount
-
-	// 第2階段：星光結晶體 -> 星光原石
-	for i := 0; i < currentCount; i++ {
-		reward := sc.drawFromPool(domain.StagePools[2])
-		if reward.Name == domain.UpgradeItems[2] { // 星光原石
-			// 升級成功，計入下一階段
-			result.Rewards["星光原石"]++
-		} else {
-			// 失敗，記錄獎品並終止
-			result.Stage2Failures++
-			result.Rewards[reward.Name]++
-		}
-	}
-
-	// 第3階段：星光原石 -> 星光水晶
-	stage3Count := result.Rewards["星光原石"]
-	delete(result.Rewards, "星光原石") // 原石已消耗
-	for i := 0; i < stage3Count; i++ {
-		reward := sc.drawFromPool(domain.StagePools[3])
-		if reward.Name == domain.UpgradeItems[3] { // 星光水晶
-			result.Rewards["星光水晶"]++
-		} else {
-			result.Stage3Failures++
-			result.Rewards[reward.Name]++
-		}
-	}
-
-	// 第4階段：星光水晶 -> 璀璨星光
-	stage4Count := result.Rewards["星光水晶"]
-	delete(result.Rewards, "星光水晶")
-	for i := 0; i < stage4Count; i++ {
-		reward := sc.drawFromPool(domain.StagePools[4])
-		if reward.Name == domain.UpgradeItems[4] { // 璀璨星光
-			result.Rewards["璀璨星光"]++
-		} else {
-			result.Stage4Failures++
-			result.Rewards[reward.Name]++
-		}
-	}
-
-	// 第5階段：璀璨星光 -> 最終獎品
-	stage5Count := result.Rewards["璀璨星光"]
-	delete(result.Rewards, "璀璨星光")
-	result.Stage5Success = stage5Count
-
-	for i := 0; i < stage5Count; i++ {
-		reward := sc.drawFromPool(domain.StagePools[5])
-		result.Rewards[reward.Name]++
+	// 依階段順序由停留次數推算各階段的進入次數（升級道具已消耗，只記錄最後停留階段的獎品）
+	result := domain.LadderResult{
+		InitialCount: initialCount,
+		Rewards:      output.Items,
 	}
-
-	return result
-}
-
-// drawFromPool 根據權重從獎池中抽取一個獎品
-func (sc *StarlightCalculator) drawFromPool(pool []domain.Reward) domain.Reward {
-	roll := sc.rng.Float64() * 100
-	var cumulative float64
-
-	for _, reward := range pool {
-		cumulative += reward.Probability
-		if roll < cumulative {
-			return reward
-		}
+	entered := initialCount
+	for _, stage := range sc.event.Stages() {
+		stops := output.Stops[domain.StagePoolID(stage)]
+		result.Stages = append(result.Stages, domain.LadderStageResult{
+			Stage:   stage,
+			Item:    sc.event.StageItem(stage),
+			Entered: entered,
+			Stops:   stops,
+		})
+		entered -= stops
 	}
 
-	// 應該不會到這裡，但以防萬一返回最後一個
-	return pool[len(pool)-1]
+	return result, nil
 }
 
 // CalculateSurvivalRate 計算存活率
@@ -218,32 +162,28 @@ func (sc *StarlightCalculator) CalculateSurvivalRate(result domain.LadderResult)
 	if result.InitialCount == 0 {
 		return 0
 	}
-	return float64(result.Stage5Success) / float64(result.InitialCount) * 100
+	return float64(result.Success()) / float64(result.InitialCount) * 100
 }
 
-// CalculateTheoreticalSurvival 計算理論存活率
-// 每階段 50% 升級成功，需要通過 3 個升級檢查點
-// 第2層→第3層(50%) → 第3層→第4層(50%) → 第4層→第5層(50%)
+// CalculateTheoreticalSurvival 計算理論存活率 (%)
+// 由各階段升級道具的機率相乘：第2層→第3層 × 第3層→第4層 × 第4層→第5層
 func (sc *StarlightCalculator) CalculateTheoreticalSurvival() float64 {
-	// 0.5 ^ 3 = 0.125 = 12.5%
-	return 0.5 * 0.5 * 0.5 * 100
+	return sc.ladderSurvival()
 }
 
 // CalculateExpandedEV 計算展開後的期望總價值
 func (sc *StarlightCalculator) CalculateExpandedEV(drawCount float64, prices map[string]int) float64 {
-	items := sc.CalculateExpandedExpected(drawCount)
-	var totalEV float64
-	for _, item := range items {
-		if price, ok := prices[item.Name]; ok {
-			totalEV += item.Expected * float64(price)
-		}
-	}
-	return totalEV
+	return sc.CalculateMergedEV(drawCount, prices, false)
+}
+
+// CalculateExpandedEVAt 以價格歷史中某日的道具價格計算展開後的期望總價值
+func (sc *StarlightCalculator) CalculateExpandedEVAt(history domain.PriceHistory, date time.Time, drawCount float64) float64 {
+	return sc.CalculateExpandedEV(drawCount, history.At(date))
 }
 
 // IsZeroValueItem 檢查是否為價值為0的道具
 func (sc *StarlightCalculator) IsZeroValueItem(name string) bool {
-	for _, item := range domain.ZeroValueItems {
+	for _, item := range sc.event.ZeroValueItems {
 		if item == name {
 			return true
 		}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSimulateLadderStages(t *testing.T) {
+	event := loadTestEvent(t).Starlight
+	calculator := NewStarlightCalculator(event, WithSeed(1))
+
+	const initial = 20000
+	result, err := calculator.SimulateLadder(context.Background(), initial)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stages := event.Stages()
+	if len(result.Stages) != len(stages) {
+		t.Fatalf("Stages = %d 個階段, want %d", len(result.Stages), len(stages))
+	}
+
+	// 每個結晶體恰好停留在一個階段，且下一階段的進入次數等於上一階段未停留的次數
+	entered, stops := initial, 0
+	for i, stage := range result.Stages {
+		if stage.Stage != stages[i] || stage.Item != event.StageItem(stages[i]) {
+			t.Errorf("第 %d 個階段 = %d（%s）, want %d（%s）", i+1, stage.Stage, stage.Item, stages[i], event.StageItem(stages[i]))
+		}
+		if stage.Entered != entered {
+			t.Errorf("第%d層 Entered = %d, want %d", stage.Stage, stage.Entered, entered)
+		}
+		entered -= stage.Stops
+		stops += stage.Stops
+	}
+	if stops != initial || entered != 0 {
+		t.Errorf("各階段停留次數合計 %d, want %d", stops, initial)
+	}
+
+	last := result.Stages[len(result.Stages)-1]
+	if result.Success() != last.Stops {
+		t.Errorf("Success() = %d, want 最終階段停留次數 %d", result.Success(), last.Stops)
+	}
+
+	// 抵達最終階段的比例應落在理論存活率的 5 個標準誤內
+	p := calculator.CalculateTheoreticalSurvival() / 100
+	stderr := math.Sqrt(p * (1 - p) / initial)
+	if got := calculator.CalculateSurvivalRate(result) / 100; math.Abs(got-p) > 5*stderr {
+		t.Errorf("存活率 = %g, want %g（標準誤 %g）", got, p, stderr)
+	}
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"sort"
+)
+
+// StarlightExpectedInput 星光錦囊期望值計算輸入
+type StarlightExpectedInput struct {
+	Investment float64
+	Method     domain.PurchaseMethod
+	Discount   float64
+	Prices     map[string]int
+	Trials     int  // 估計百分位數的模擬次數，0 表示使用預設值
+	Integer    bool // 玲瓏星光是否以整數合成（不足一組保留並以其價格計價）
+	// IntegerDraws 以整數抽數計算（不足一抽的點數保留為剩餘點數），否則視抽數為可分割
+	IntegerDraws bool
+	CarryPoints  float64 // 前次購買或其他活動結轉的點數
+}
+
+// StarlightExpectedOutput 星光錦囊期望值計算輸出
+type StarlightExpectedOutput struct {
+	Points        float64
+	Purchase      domain.Purchase // 購買方式與點卡組合
+	DrawCount     float64
+	Residual      float64 // 不足一抽的剩餘點數（整數抽數時）
+	CostPerDraw   float64
+	ExpectedItems []ExpandedItem
+	ExpectedValue float64
+	ROI           float64
+	Merge         CrystalMerge // 玲瓏星光合成結果
+	Risk          RiskReport   // 總價值與報酬率的分散程度
+	Seed          int64        // 模擬使用的亂數種子
+}
+
+// CalculateExpected 計算投入金額展開所有階段後的期望道具與報酬率
+func (sc *StarlightCalculator) CalculateExpected(ctx context.Context, input StarlightExpectedInput) (StarlightExpectedOutput, error) {
+	// 1. 計算可得點數（點卡面額時為預算內點數最多的組合）
+	purchase := sc.purchases.Buy(input.Investment, input.Method, input.Discount)
+	points := purchase.Points + input.CarryPoints
+	cost := purchase.Cost
+
+	// 2. 計算可抽次數（整數抽數時無條件捨去，剩餘點數可結轉）
+	drawCount := points / float64(sc.event.CostPerDraw)
+	residual := 0.0
+	if input.IntegerDraws {
+		plan := domain.PlanDraws(points, float64(sc.event.CostPerDraw))
+		drawCount = float64(plan.Draws)
+		residual = plan.Residual
+	}
+
+	// 3. 計算每抽實際成本
+	costPerDraw := 0.0
+	if drawCount > 0 {
+		costPerDraw = cost / drawCount
+	}
+
+	// 4. 計算展開後的期望道具數量（依數量由多到少排序）
+	items, merge := sc.CalculateMergedExpected(drawCount, input.Integer)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Expected > items[j].Expected
+	})
+
+	// 5. 計算期望總價值
+	expectedValue := sc.CalculateMergedEV(drawCount, input.Prices, input.Integer)
+
+	// 6. 計算報酬率
+	roi := 0.0
+	if cost > 0 {
+		roi = ((expectedValue - cost) / cost) * 100
+	}
+
+	// 7. 計算總價值與報酬率的分散程度
+	risk, err := sc.CalculateRisk(ctx, drawCount, cost, input.Prices, input.Trials, input.Integer)
+	if err != nil {
+		return StarlightExpectedOutput{}, err
+	}
+
+	return StarlightExpectedOutput{
+		Points:        points,
+		Purchase:      purchase,
+		DrawCount:     drawCount,
+		Residual:      residual,
+		CostPerDraw:   costPerDraw,
+		ExpectedItems: items,
+		ExpectedValue: expectedValue,
+		ROI:           roi,
+		Merge:         merge,
+		Risk:          risk,
+		Seed:          sc.Seed(),
+	}, nil
+}
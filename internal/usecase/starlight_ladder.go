@@ -0,0 +1,83 @@
+package usecase
+
+import "MSCashItemExpected/internal/domain"
+
+// LadderStage 階梯單一階段的精確機率
+type LadderStage struct {
+	Stage   int
+	Reach   float64 // 單一星光結晶體抵達此階段的機率 (%)
+	Upgrade float64 // 抵達後抽到升級道具的條件機率 (%)，最終階段為 0
+	Stop    float64 // 停留在此階段（未升級）的機率 (%)
+}
+
+// LadderReward 單一最終獎品的精確分佈
+type LadderReward struct {
+	Name        string
+	Probability float64                  // 單一星光結晶體最終獲得此獎品的機率 (%)
+	Count       domain.CountDistribution // K 個星光結晶體獲得此獎品數量的分佈
+}
+
+// LadderDistribution 星光結晶體階梯的精確機率分佈
+//
+// 每個星光結晶體各階段皆為獨立的類別抽取，最終必停在恰好一個獎品上，
+// 因此 K 個結晶體中任一獎品（或抵達最終階段）的數量皆服從二項分佈。
+type LadderDistribution struct {
+	Crystals  int
+	Stages    []LadderStage
+	FinalItem string                   // 進入最終階段的升級道具（璀璨星光）
+	Survival  float64                  // 單一星光結晶體取得 FinalItem 的機率 (%)
+	Final     domain.CountDistribution // K 個星光結晶體取得 FinalItem 數量的分佈
+	Rewards   []LadderReward           // 各最終獎品（依機率由高到低）
+}
+
+// CalculateLadderDistribution 計算開啟 crystals 個星光結晶體的精確結果分佈
+func (sc *StarlightCalculator) CalculateLadderDistribution(crystals int) LadderDistribution {
+	stages := sc.event.Stages()
+	dist := LadderDistribution{Crystals: crystals}
+
+	// 1. 逐階段計算抵達與停留機率
+	reach := 1.0
+	for _, stage := range stages {
+		upgrade := domain.Probability(sc.event.StagePools[stage], sc.event.UpgradeItems[stage]) / 100
+		dist.Stages = append(dist.Stages, LadderStage{
+			Stage:   stage,
+			Reach:   reach * 100,
+			Upgrade: upgrade * 100,
+			Stop:    reach * (1 - upgrade) * 100,
+		})
+		reach *= upgrade
+	}
+
+	// 2. 最終階段（璀璨星光）的存活機率與數量分佈
+	if len(stages) > 1 {
+		dist.FinalItem = sc.event.UpgradeItems[stages[len(stages)-2]]
+		dist.Survival = sc.ladderSurvival()
+	}
+	dist.Final = domain.Binomial(crystals, dist.Survival/100)
+
+	// 3. 各最終獎品的機率與數量分佈
+	for _, item := range sc.gacha.Model().OpenDistribution(sc.ladderPool(), crystals) {
+		dist.Rewards = append(dist.Rewards, LadderReward{
+			Name:        item.Name,
+			Probability: item.Probability,
+			Count:       item.Count,
+		})
+	}
+
+	return dist
+}
+
+// ladderOutcomes 計算單一星光結晶體最終停在各獎品的機率（0~1）
+// 同名獎品出現在多個階段時合併計算
+func (sc *StarlightCalculator) ladderOutcomes() map[string]float64 {
+	return sc.gacha.Model().Outcomes(sc.ladderPool())
+}
+
+// ladderSurvival 計算單一星光結晶體抵達最終階段的機率 (%)
+func (sc *StarlightCalculator) ladderSurvival() float64 {
+	stages := sc.event.Stages()
+	if len(stages) == 0 {
+		return 100
+	}
+	return sc.gacha.Model().Reach(sc.ladderPool())[domain.StagePoolID(stages[len(stages)-1])] * 100
+}
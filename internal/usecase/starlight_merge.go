@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"math"
+)
+
+// CrystalMerge 玲瓏星光合成星光結晶體的期望結果
+type CrystalMerge struct {
+	Integer  bool    // 是否以整數合成（每 CrystalsPerMerge 個合成一個，不足一組保留）
+	Crystals float64 // 期望玲瓏星光數量
+	Merges   float64 // 期望合成的星光結晶體數量
+	Leftover float64 // 期望剩餘（無法合成）的玲瓏星光數量
+}
+
+// CalculateMerge 計算抽 drawCount 次後玲瓏星光的期望合成結果
+//
+// 非整數模式下玲瓏星光視為可分割，期望數量直接除以 CrystalsPerMerge；
+// 整數模式下以整數抽數 N = floor(drawCount) 計算，玲瓏星光數量 C ~ B(N, q)，
+// 合成數量為 E[floor(C / k)]，剩餘為 E[C mod k]。
+func (sc *StarlightCalculator) CalculateMerge(drawCount float64, integer bool) CrystalMerge {
+	k := sc.event.CrystalsPerMerge
+	q := domain.Probability(sc.event.Stage1Pool, sc.event.CrystalItem) / 100
+
+	if !integer {
+		crystals := drawCount * q
+		return CrystalMerge{
+			Crystals: crystals,
+			Merges:   crystals / float64(k),
+		}
+	}
+
+	merge := CrystalMerge{Integer: true}
+	for _, mass := range domain.Binomial(int(math.Floor(drawCount)), q).Mass {
+		p := mass.Probability / 100
+		merge.Crystals += p * float64(mass.Count)
+		merge.Merges += p * float64(mass.Count/k)
+		merge.Leftover += p * float64(mass.Count%k)
+	}
+	return merge
+}
+
+// CalculateMergedExpected 計算展開所有階段後的期望道具數量與玲瓏星光合成結果
+// 整數模式下剩餘的玲瓏星光會以道具「玲瓏星光」列出，並依其價格計價
+func (sc *StarlightCalculator) CalculateMergedExpected(drawCount float64, integer bool) ([]ExpandedItem, CrystalMerge) {
+	merge := sc.CalculateMerge(drawCount, integer)
+
+	// 非整數模式即模型的可分割期望值
+	items := sc.gacha.Model().Expected(drawCount)
+	if integer {
+		items = make(map[string]float64)
+
+		// 第一階段：星光錦囊（與玲瓏星光同樣以整數抽數計算，玲瓏星光另行合成）
+		draws := math.Floor(drawCount)
+		for _, reward := range sc.event.Stage1Pool {
+			if reward.Name != sc.event.CrystalItem {
+				items[reward.Name] += draws * (reward.Probability / 100)
+			}
+		}
+
+		// 合成的星光結晶體沿階梯展開到最終獎品
+		for name, p := range sc.ladderOutcomes() {
+			items[name] += merge.Merges * p
+		}
+
+		// 無法合成的玲瓏星光
+		if merge.Leftover > 0 {
+			items[sc.event.CrystalItem] += merge.Leftover
+		}
+	}
+
+	// 轉換為 slice
+	var result []ExpandedItem
+	for name, expected := range items {
+		result = append(result, ExpandedItem{Name: name, Expected: expected})
+	}
+
+	return result, merge
+}
+
+// CalculateMergedEV 計算展開後的期望總價值，integer 指定是否以整數合成玲瓏星光
+func (sc *StarlightCalculator) CalculateMergedEV(drawCount float64, prices map[string]int, integer bool) float64 {
+	items, _ := sc.CalculateMergedExpected(drawCount, integer)
+	var totalEV float64
+	for _, item := range items {
+		if price, ok := prices[item.Name]; ok {
+			totalEV += item.Expected * float64(price)
+		}
+	}
+	return totalEV
+}
+
+// integerMergeVariance 計算整數合成模式下總價值的解析變異數
+//
+// 以玲瓏星光數量 C 為條件：其餘 N - C 抽為去除玲瓏星光後的多項分佈，
+// 合成 floor(C / k) 個獨立階梯，剩餘 C mod k 個玲瓏星光以其價格計價，
+// 再以全變異數公式 Var(T) = E[Var(T | C)] + Var(E[T | C]) 合併。
+func (sc *StarlightCalculator) integerMergeVariance(drawCount float64, prices map[string]int) float64 {
+	n := int(math.Floor(drawCount))
+	k := sc.event.CrystalsPerMerge
+	qc := domain.Probability(sc.event.Stage1Pool, sc.event.CrystalItem) / 100
+	if qc >= 1 {
+		qc = 1
+	}
+
+	// 去除玲瓏星光後單抽價值的一階與二階動差
+	var a, b float64
+	if qc < 1 {
+		for _, reward := range sc.event.Stage1Pool {
+			if reward.Name == sc.event.CrystalItem {
+				continue
+			}
+			q := reward.Probability / 100 / (1 - qc)
+			v := float64(prices[reward.Name])
+			a += q * v
+			b += q * v * v
+		}
+	}
+
+	l1, l2 := sc.ladderMoments(2, prices)
+	varL := l2 - l1*l1
+	crystalPrice := float64(prices[sc.event.CrystalItem])
+
+	var meanT, secondT, expectedVar float64
+	for _, mass := range domain.Binomial(n, qc).Mass {
+		p := mass.Probability / 100
+		c := mass.Count
+		others := float64(n - c)
+		merges := float64(c / k)
+
+		mean := others*a + merges*l1 + float64(c%k)*crystalPrice
+		meanT += p * mean
+		secondT += p * mean * mean
+		expectedVar += p * (others*(b-a*a) + merges*varL)
+	}
+
+	return expectedVar + (secondT - meanT*meanT)
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"math"
+	"testing"
+)
+
+// ladderItems 不經由抽獎模型，直接將 merges 個星光結晶體沿階梯逐階段展開為最終道具的期望數量
+func ladderItems(event domain.StarlightEvent, merges float64) map[string]float64 {
+	items := make(map[string]float64)
+	reach := merges
+	for _, stage := range event.Stages() {
+		var next float64
+		for _, reward := range event.StagePools[stage] {
+			amount := reach * reward.Probability / 100
+			if _, hasNext := event.StagePools[stage+1]; hasNext && reward.Name == event.UpgradeItems[stage] {
+				next += amount
+				continue
+			}
+			items[reward.Name] += amount
+		}
+		reach = next
+	}
+	return items
+}
+
+// testStarlightPrices 測試用道具價格：各有價值道具依序給予不同價格，玲瓏星光另行計價
+func testStarlightPrices(event domain.StarlightEvent) map[string]int {
+	prices := map[string]int{event.CrystalItem: 777}
+	for i, name := range event.ValuableItems {
+		prices[name] = 1000 * (i + 1)
+	}
+	return prices
+}
+
+// itemsValue 依價格計算道具的總價值
+func itemsValue(items map[string]float64, prices map[string]int) float64 {
+	var total float64
+	for name, n := range items {
+		total += n * float64(prices[name])
+	}
+	return total
+}
+
+func TestCalculateMergedEV(t *testing.T) {
+	event := loadTestEvent(t).Starlight
+	calculator := NewStarlightCalculator(event)
+	prices := testStarlightPrices(event)
+
+	k := event.CrystalsPerMerge
+	q := domain.Probability(event.Stage1Pool, event.CrystalItem) / 100
+	var stage1 float64 // 每抽第一階段非玲瓏星光道具的期望價值
+	for _, reward := range event.Stage1Pool {
+		if reward.Name != event.CrystalItem {
+			stage1 += reward.Probability / 100 * float64(prices[reward.Name])
+		}
+	}
+	ladder := itemsValue(ladderItems(event, 1), prices) // 每個星光結晶體的期望價值
+
+	tests := []struct {
+		name    string
+		draws   float64
+		integer bool
+	}{
+		{"可分割", 100, false},
+		{"可分割小數抽數", 12.5, false},
+		{"整數合成", 100, true},
+		{"整數合成抽數不足一組", 3, true},
+		{"整數合成不抽", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want float64
+			if tt.integer {
+				// 玲瓏星光數量 C ~ B(N, q)：合成 floor(C / k) 個，剩餘 C mod k 個以其價格計價
+				n := int(tt.draws)
+				want = float64(n) * stage1
+				for c := 0; c <= n; c++ {
+					lg, _ := math.Lgamma(float64(n + 1))
+					lc, _ := math.Lgamma(float64(c + 1))
+					lr, _ := math.Lgamma(float64(n - c + 1))
+					p := math.Exp(lg - lc - lr + float64(c)*math.Log(q) + float64(n-c)*math.Log1p(-q))
+					want += p * (float64(c/k)*ladder + float64(c%k)*float64(prices[event.CrystalItem]))
+				}
+			} else {
+				want = tt.draws*stage1 + tt.draws*q/float64(k)*ladder
+			}
+
+			if got := calculator.CalculateMergedEV(tt.draws, prices, tt.integer); math.Abs(got-want) > 1e-6 {
+				t.Errorf("CalculateMergedEV(%g, integer=%v) = %g, want %g", tt.draws, tt.integer, got, want)
+			}
+		})
+	}
+}
+
+func TestCalculateMergedEVIntegerFloorsDraws(t *testing.T) {
+	event := loadTestEvent(t).Starlight
+	calculator := NewStarlightCalculator(event)
+	prices := testStarlightPrices(event)
+
+	// 整數模式下不足一抽的點數不會產生第一階段道具
+	if got, want := calculator.CalculateMergedEV(10.7, prices, true), calculator.CalculateMergedEV(10, prices, true); math.Abs(got-want) > 1e-9 {
+		t.Errorf("CalculateMergedEV(10.7) = %g, want 與 10 抽相同的 %g", got, want)
+	}
+}
+
+func TestCalculateMerge(t *testing.T) {
+	event := loadTestEvent(t).Starlight
+	calculator := NewStarlightCalculator(event)
+	k := float64(event.CrystalsPerMerge)
+	q := domain.Probability(event.Stage1Pool, event.CrystalItem) / 100
+
+	merge := calculator.CalculateMerge(200, true)
+	if math.Abs(merge.Crystals-200*q) > 1e-9 {
+		t.Errorf("Crystals = %g, want %g", merge.Crystals, 200*q)
+	}
+	// 玲瓏星光全數用於合成或保留：C = k·合成數 + 剩餘
+	if math.Abs(k*merge.Merges+merge.Leftover-merge.Crystals) > 1e-9 {
+		t.Errorf("合成 %g、剩餘 %g 與玲瓏星光 %g 不一致", merge.Merges, merge.Leftover, merge.Crystals)
+	}
+	if fractional := calculator.CalculateMerge(200, false); merge.Merges >= fractional.Merges {
+		t.Errorf("整數合成數 %g 應少於可分割的 %g", merge.Merges, fractional.Merges)
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+// LadderDecision 階梯單一階段的開啟或出售決策
+type LadderDecision struct {
+	Stage     int
+	Item      string  // 開啟此階段的道具（星光結晶體、星光原石…）
+	SellValue float64 // 直接出售（保留）的價值
+	OpenValue float64 // 開啟的期望價值（後續階段依最佳策略）
+	Open      bool    // 最佳策略是否開啟
+}
+
+// LadderPolicy 階梯的最佳開啟策略
+type LadderPolicy struct {
+	Crystals     int
+	Decisions    []LadderDecision // 各階段決策（由第2階段起）
+	AlwaysOpenEV float64          // 每個星光結晶體一律開到底的期望價值
+	OptimalEV    float64          // 每個星光結晶體依最佳策略的期望價值
+	Gain         float64          // 最佳策略相較一律開啟增加的總期望價值（× Crystals）
+}
+
+// CalculateLadderPolicy 計算階梯每個階段開啟或出售的最佳策略
+//
+// 每個階段的中間道具可直接以 prices 中的價格出售，或開啟後依獎池抽取；
+// 抽到升級道具時進入下一階段再做決策。由最終階段往回推（逆向歸納）：
+//
+//	V(s) = max(出售價格, Σ p(獎品) × (升級道具 ? V(s+1) : 獎品價格))
+//
+// 出售與開啟期望價值相同時選擇開啟。
+func (sc *StarlightCalculator) CalculateLadderPolicy(crystals int, prices map[string]int) LadderPolicy {
+	stages := sc.event.Stages()
+	decisions := make([]LadderDecision, len(stages))
+
+	// 由最終階段往回推
+	next := 0.0
+	for i := len(stages) - 1; i >= 0; i-- {
+		stage := stages[i]
+		var open float64
+		for _, reward := range sc.event.StagePools[stage] {
+			p := reward.Probability / 100
+			if reward.Name == sc.event.UpgradeItems[stage] {
+				open += p * next
+				continue
+			}
+			open += p * float64(prices[reward.Name])
+		}
+
+		item := sc.event.StageItem(stage)
+		sell := float64(prices[item])
+		decisions[i] = LadderDecision{
+			Stage:     stage,
+			Item:      item,
+			SellValue: sell,
+			OpenValue: open,
+			Open:      open >= sell,
+		}
+		next = max(open, sell)
+	}
+
+	alwaysOpen, _ := sc.ladderMoments(2, prices)
+	return LadderPolicy{
+		Crystals:     crystals,
+		Decisions:    decisions,
+		AlwaysOpenEV: alwaysOpen,
+		OptimalEV:    next,
+		Gain:         (next - alwaysOpen) * float64(crystals),
+	}
+}
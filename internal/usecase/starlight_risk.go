@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"math"
+)
+
+// CalculateRisk 計算展開後總價值的變異數與百分位數
+//
+// 變異數為解析解：第一階段各道具數量服從多項分佈，玲瓏星光與期望值計算一致視為可分割，
+// 每 CrystalsPerMerge 個合成一個星光結晶體，總價值為第一階段價值 S 加上 M 個獨立階梯價值 L 的複合和：
+//
+//	Var(T) = Var(S) + E[M]·Var(L) + Var(M)·E[L]² + 2·Cov(S, M)·E[L]
+//
+// 百分位數則以 trials 次模擬估計（不足一組的玲瓏星光以期望價值計）。
+// integer 為 true 時改以整數合成計算，剩餘的玲瓏星光以其價格計價（見 integerMergeVariance）。
+func (sc *StarlightCalculator) CalculateRisk(ctx context.Context, drawCount float64, investment float64, prices map[string]int, trials int, integer bool) (RiskReport, error) {
+	if trials <= 0 {
+		trials = DefaultRiskTrials
+	}
+
+	// 平行模擬估計百分位數
+	values, err := sc.simulateValues(ctx, int(math.Floor(drawCount)), prices, trials, integer)
+	if err != nil {
+		return RiskReport{}, err
+	}
+	report := newRiskReport(investment, domain.Summarize(values), domain.Summarize(toROI(values, investment)), trials)
+
+	if integer {
+		return report.withAnalyticVariance(investment, sc.integerMergeVariance(drawCount, prices)), nil
+	}
+	return report.withAnalyticVariance(investment, sc.analyticVariance(drawCount, prices)), nil
+}
+
+// analyticVariance 計算展開後總價值的解析變異數
+func (sc *StarlightCalculator) analyticVariance(drawCount float64, prices map[string]int) float64 {
+	n := drawCount
+	k := float64(sc.event.CrystalsPerMerge)
+
+	// 第一階段（不含玲瓏星光）價值的一階與二階動差
+	var a, b, qc float64
+	for _, reward := range sc.event.Stage1Pool {
+		q := reward.Probability / 100
+		if reward.Name == sc.event.CrystalItem {
+			qc += q
+			continue
+		}
+		v := float64(prices[reward.Name])
+		a += q * v
+		b += q * v * v
+	}
+
+	// 一個星光結晶體的階梯價值動差
+	l1, l2 := sc.ladderMoments(2, prices)
+	varL := l2 - l1*l1
+
+	varS := n * (b - a*a)
+	meanM := n * qc / k
+	varM := n * qc * (1 - qc) / (k * k)
+	covSM := -n * qc * a / k
+
+	return varS + meanM*varL + varM*l1*l1 + 2*covSM*l1
+}
+
+// ladderMoments 計算從指定階段開啟一個道具所得價值的一階與二階動差
+// 升級道具會被消耗並進入下一階段，本身不計價值
+func (sc *StarlightCalculator) ladderMoments(stage int, prices map[string]int) (mean, second float64) {
+	return sc.gacha.Model().Moments(domain.StagePoolID(stage), prices)
+}
+
+// simulateValues 以抽獎引擎平行模擬 trials 次抽 drawCount 次並開啟所有合成的星光結晶體，回傳依模擬順序排列的總價值
+// 不足一組的玲瓏星光以每個 E[L] ÷ CrystalsPerMerge 計價，與期望值計算一致；
+// integer 為 true 時改以玲瓏星光本身的價格計價
+func (sc *StarlightCalculator) simulateValues(ctx context.Context, drawCount int, prices map[string]int, trials int, integer bool) ([]float64, error) {
+	crystalValue := float64(prices[sc.event.CrystalItem])
+	if !integer {
+		l1, _ := sc.ladderMoments(2, prices)
+		crystalValue = l1 / float64(sc.event.CrystalsPerMerge)
+	}
+
+	// 依模型道具順序加總，結果與 worker 數量無關
+	names := sc.gacha.Model().Items()
+	return sc.gacha.simulateValues(ctx, drawCount, trials, prices, func(items map[string]int) float64 {
+		var value float64
+		for _, name := range names {
+			if name == sc.event.CrystalItem {
+				value += float64(items[name]) * crystalValue
+				continue
+			}
+			value += float64(items[name]) * float64(prices[name])
+		}
+		return value
+	})
+}
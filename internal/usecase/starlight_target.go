@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"fmt"
+)
+
+// StarlightTargetInput 星光錦囊目標反推輸入
+type StarlightTargetInput struct {
+	Method     domain.PurchaseMethod
+	Discount   float64
+	Item       string  // 目標道具（可為階梯中間道具，如璀璨星光）
+	Count      int     // 目標數量
+	Confidence float64 // 信心水準 (%)
+	Trials     int     // 模擬次數
+	MaxDraws   int     // 每次模擬的抽數上限，0 表示使用預設值
+}
+
+// SolveTarget 反推以指定信心水準取得 Count 個目標道具所需的投入金額
+// 玲瓏星光湊滿合成數量時立即合成並開啟階梯，階梯中途獲得的升級道具也計入目標
+func (sc *StarlightCalculator) SolveTarget(ctx context.Context, input StarlightTargetInput) (TargetOutput, error) {
+	if !sc.isObtainable(input.Item) {
+		return TargetOutput{}, fmt.Errorf("獎池中沒有道具 %q", input.Item)
+	}
+	if err := validateTarget(input.Count, input.Confidence, input.Trials); err != nil {
+		return TargetOutput{}, err
+	}
+
+	maxDraws := input.MaxDraws
+	if maxDraws <= 0 {
+		maxDraws = DefaultMaxTargetDraws
+	}
+
+	// 每次模擬記錄取得目標所需的抽數
+	hits, err := collectHits(ctx, sc.gacha.engine, input.Trials, func(rng RNG) int {
+		return sc.gacha.drawsToObtain(rng, input.Item, input.Count, maxDraws)
+	})
+	if err != nil {
+		return TargetOutput{}, err
+	}
+
+	output, err := solveTarget(hits, maxDraws, input.Confidence, float64(sc.event.CostPerDraw), sc.purchases.Rule(input.Method), input.Discount)
+	if err != nil {
+		return TargetOutput{}, err
+	}
+	output.Seed = sc.Seed()
+	return output, nil
+}
+
+// ladderPool 星光結晶體開啟的第一個階梯階段獎池代號
+func (sc *StarlightCalculator) ladderPool() string {
+	return sc.gacha.Model().Opens[sc.event.MergedItem]
+}
+
+// isObtainable 檢查道具是否出現在任一獎池中
+func (sc *StarlightCalculator) isObtainable(item string) bool {
+	if domain.Probability(sc.event.Stage1Pool, item) > 0 {
+		return true
+	}
+	for _, pool := range sc.event.StagePools {
+		if domain.Probability(pool, item) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"errors"
+	"math"
+	"sort"
+)
+
+// DefaultMaxTargetDraws 反推目標時每次模擬的抽數上限
+const DefaultMaxTargetDraws = 100000
+
+// validateTarget 檢查目標反推的共同參數
+func validateTarget(count int, confidence float64, trials int) error {
+	if count <= 0 {
+		return errors.New("目標數量必須大於 0")
+	}
+	if confidence <= 0 || confidence > 100 {
+		return errors.New("信心水準必須介於 0 與 100 之間")
+	}
+	if trials <= 0 {
+		return errors.New("模擬次數必須大於 0")
+	}
+	return nil
+}
+
+// TargetOutput 目標反推輸出
+type TargetOutput struct {
+	Reached     bool            // 是否在抽數上限內達到信心水準
+	DrawCount   int             // 所需抽數
+	Points      float64         // 所需點數
+	Investment  float64         // 所需投入金額
+	Purchase    domain.Purchase // 最低花費的購買方式與點卡組合（含超出所需的點數）
+	Probability float64         // 以所需抽數達成目標的模擬機率 (%)
+	MeanDraws   float64         // 達成目標的平均抽數（未達成的模擬以上限計）
+	Trials      int             // 模擬次數
+	Seed        int64           // 使用的亂數種子
+}
+
+// collectHits 平行執行 trials 次模擬，依模擬順序回傳每次達成目標所需的抽數
+func collectHits(ctx context.Context, engine *Engine, trials int, drawsToHit func(rng RNG) int) ([]int, error) {
+	chunks, err := runChunks(ctx, engine, trials, trialChunkSize, func(rng RNG, size int) []int {
+		hits := make([]int, size)
+		for t := range hits {
+			hits[t] = drawsToHit(rng)
+		}
+		return hits
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]int, 0, trials)
+	for _, chunk := range chunks {
+		hits = append(hits, chunk...)
+	}
+	return hits, nil
+}
+
+// solveTarget 由每次模擬達成目標所需的抽數，求出達到信心水準的抽數與投入金額
+// hits 中超過 maxDraws 的值代表在上限內未達成
+func solveTarget(hits []int, maxDraws int, confidence float64, costPerDraw float64, rule domain.PurchaseRule, discount float64) (TargetOutput, error) {
+	output := TargetOutput{Trials: len(hits)}
+	if len(hits) == 0 {
+		return output, nil
+	}
+
+	sort.Ints(hits)
+
+	var total float64
+	for _, h := range hits {
+		total += float64(min(h, maxDraws))
+	}
+	output.MeanDraws = total / float64(len(hits))
+
+	// 取第 confidence 百分位的抽數（至少有 confidence% 的模擬在此抽數內達成）
+	index := int(math.Ceil(confidence/100*float64(len(hits)))) - 1
+	index = max(0, min(index, len(hits)-1))
+	draws := hits[index]
+	if draws > maxDraws {
+		return output, nil
+	}
+
+	// 以最低花費的點卡組合取得所需點數
+	purchase, err := rule.Cheapest(float64(draws)*costPerDraw, discount)
+	if err != nil {
+		return TargetOutput{}, err
+	}
+
+	reached := sort.SearchInts(hits, draws+1)
+	output.Reached = true
+	output.DrawCount = draws
+	output.Investment = purchase.Cost
+	output.Points = purchase.Points
+	output.Purchase = purchase
+	output.Probability = float64(reached) / float64(len(hits)) * 100
+	return output, nil
+}
@@ -0,0 +1,301 @@
+package usecase
+
+import (
+	"MSCashItemExpected/internal/domain"
+	"context"
+	"sort"
+)
+
+// ZodiacSimulator 新年氣息模擬器
+type ZodiacSimulator struct {
+	event     domain.ZodiacEvent
+	model     domain.GachaModel // 活動對應的多階段抽獎模型
+	engine    *Engine
+	purchases domain.PurchaseRegistry
+}
+
+// NewZodiacSimulator 建立新年氣息模擬器
+func NewZodiacSimulator(event domain.ZodiacEvent, opts ...Option) *ZodiacSimulator {
+	o := newOptions(opts)
+	return &ZodiacSimulator{
+		event:     event,
+		model:     ZodiacModel(event),
+		engine:    newEngine(o),
+		purchases: o.purchases,
+	}
+}
+
+// WithSeed 以相同活動定義與引擎設定，建立使用指定種子的新模擬器
+func (s *ZodiacSimulator) WithSeed(seed int64) *ZodiacSimulator {
+	return &ZodiacSimulator{
+		event:     s.event,
+		model:     s.model,
+		engine:    s.engine.WithSeed(seed),
+		purchases: s.purchases,
+	}
+}
+
+// Seed 取得模擬器使用的亂數種子
+func (s *ZodiacSimulator) Seed() int64 {
+	return s.engine.Seed()
+}
+
+// Event 取得模擬器使用的活動定義
+func (s *ZodiacSimulator) Event() domain.ZodiacEvent {
+	return s.event
+}
+
+// Model 取得模擬器使用的多階段抽獎模型
+func (s *ZodiacSimulator) Model() domain.GachaModel {
+	return s.model
+}
+
+// DrawCount 計算投入金額加上結轉點數實際可抽的整數次數
+func (s *ZodiacSimulator) DrawCount(investment float64, method domain.PurchaseMethod, discount float64, carryPoints float64) int {
+	purchase := s.purchases.Buy(investment, method, discount)
+	return domain.PlanDraws(purchase.Points+carryPoints, s.event.CostPerDraw).Draws
+}
+
+// ZodiacSimulationInput 新年氣息模擬輸入
+type ZodiacSimulationInput struct {
+	Investment  float64
+	Method      domain.PurchaseMethod
+	Discount    float64
+	BoxValues   domain.BoxValues
+	Inventory   domain.BreathCollection // 已持有的氣息
+	Trials      int                     // 模擬次數
+	CarryPoints float64                 // 前次購買或其他活動結轉的點數
+}
+
+// ZodiacSimulationOutput 新年氣息模擬輸出
+type ZodiacSimulationOutput struct {
+	Points            float64
+	Purchase          domain.Purchase // 購買方式與點卡組合
+	Residual          float64         // 不足一抽的剩餘點數
+	Boxes             BoxDistribution
+	Value             domain.Summary // 總價值分佈（含已持有氣息）
+	ROI               domain.Summary // 報酬率分佈 (%)，僅計入本次購買增加的價值
+	ProfitProbability float64        // 增加的價值不低於投入金額的機率 (%)
+	Seed              int64          // 使用的亂數種子
+}
+
+// BoxDistribution 心願箱數量分佈（蒙地卡羅模擬）
+type BoxDistribution struct {
+	Trials     int                               // 模擬次數
+	DrawCount  int                               // 每次模擬的抽數（無條件捨去）
+	Boxes      map[domain.BoxType]domain.Summary // 各心願箱數量的統計摘要
+	AtLeastOne map[domain.BoxType]float64        // 至少獲得一個的機率 (%)
+	Optimized  float64                           // 最佳組合優於固定優先順序的模擬比例 (%)
+}
+
+// Simulate 模擬投入金額實際抽取 Trials 次，統計心願箱、總價值與報酬率分佈
+func (s *ZodiacSimulator) Simulate(ctx context.Context, input ZodiacSimulationInput) (ZodiacSimulationOutput, error) {
+	// 1. 計算可得點數與實際可抽次數（不足一抽的點數保留為剩餘點數）
+	purchase := s.purchases.Buy(input.Investment, input.Method, input.Discount)
+	points := purchase.Points + input.CarryPoints
+	cost := purchase.Cost
+	plan := domain.PlanDraws(points, s.event.CostPerDraw)
+	drawCount := plan.Draws
+
+	// 2. 已持有氣息本身可湊成的價值
+	inventoryValue := optimizeBoxes(s.event, input.Inventory, input.BoxValues).Value
+
+	// 3. 平行模擬並統計心願箱分佈與每次模擬的總價值
+	boxes, values, err := s.run(ctx, drawCount, input.Trials, input.BoxValues, input.Inventory)
+	if err != nil {
+		return ZodiacSimulationOutput{}, err
+	}
+
+	// 4. 計算報酬率（僅計入本次購買增加的價值）
+	rois := make([]float64, len(values))
+	profits := 0
+	for i, value := range values {
+		addedValue := value - inventoryValue
+		if cost > 0 {
+			rois[i] = ((addedValue - cost) / cost) * 100
+		}
+		if addedValue >= cost {
+			profits++
+		}
+	}
+
+	output := ZodiacSimulationOutput{
+		Points:   points,
+		Purchase: purchase,
+		Residual: plan.Residual,
+		Boxes:    boxes,
+		Value:    domain.Summarize(values),
+		ROI:      domain.Summarize(rois),
+		Seed:     s.Seed(),
+	}
+	if input.Trials > 0 {
+		output.ProfitProbability = float64(profits) / float64(input.Trials) * 100
+	}
+
+	return output, nil
+}
+
+// zodiacChunk 一個模擬區塊的結果
+type zodiacChunk struct {
+	boxes     map[domain.BoxType][]float64 // 各心願箱每次模擬的數量
+	values    []float64                    // 每次模擬的總價值
+	hits      map[domain.BoxType]int       // 至少獲得一個的模擬次數
+	optimized int                          // 最佳組合優於固定優先順序的模擬次數
+}
+
+// run 平行執行模擬並統計心願箱分佈，同時回傳每次模擬的總價值（依模擬順序）
+func (s *ZodiacSimulator) run(ctx context.Context, drawCount int, trials int, values domain.BoxValues, inventory domain.BreathCollection) (BoxDistribution, []float64, error) {
+	chunks, err := runChunks(ctx, s.engine, trials, trialChunkSize, func(rng RNG, size int) zodiacChunk {
+		chunk := zodiacChunk{
+			boxes:  make(map[domain.BoxType][]float64),
+			values: make([]float64, 0, size),
+			hits:   make(map[domain.BoxType]int),
+		}
+		for t := 0; t < size; t++ {
+			breaths := s.DrawBreaths(rng, drawCount).Add(inventory)
+			assembly := optimizeBoxes(s.event, breaths, values)
+			if assembly.Strategy == StrategyOptimal {
+				chunk.optimized++
+			}
+
+			for _, boxType := range s.event.BoxPriority {
+				chunk.boxes[boxType] = append(chunk.boxes[boxType], assembly.Boxes[boxType])
+				if assembly.Boxes[boxType] >= 1 {
+					chunk.hits[boxType]++
+				}
+			}
+			chunk.values = append(chunk.values, assembly.Value)
+		}
+		return chunk
+	})
+	if err != nil {
+		return BoxDistribution{}, nil, err
+	}
+
+	// 依區塊順序合併
+	samples := make(map[domain.BoxType][]float64)
+	hits := make(map[domain.BoxType]int)
+	totals := make([]float64, 0, max(trials, 0))
+	optimized := 0
+	for _, chunk := range chunks {
+		for _, boxType := range s.event.BoxPriority {
+			samples[boxType] = append(samples[boxType], chunk.boxes[boxType]...)
+			hits[boxType] += chunk.hits[boxType]
+		}
+		totals = append(totals, chunk.values...)
+		optimized += chunk.optimized
+	}
+
+	result := BoxDistribution{
+		Trials:     trials,
+		DrawCount:  drawCount,
+		Boxes:      make(map[domain.BoxType]domain.Summary),
+		AtLeastOne: make(map[domain.BoxType]float64),
+	}
+	for _, boxType := range s.event.BoxPriority {
+		result.Boxes[boxType] = domain.Summarize(samples[boxType])
+		if trials > 0 {
+			result.AtLeastOne[boxType] = float64(hits[boxType]) / float64(trials) * 100
+		}
+	}
+	if trials > 0 {
+		result.Optimized = float64(optimized) / float64(trials) * 100
+	}
+
+	return result, totals, nil
+}
+
+// valueVariances 模擬 trials 次逐抽至 drawCounts 中最多的抽數，回傳各抽數下總價值（含已持有氣息）的變異數
+// 同一次模擬的各抽數共用前段的抽取結果，因此只需模擬一次最多的抽數
+func (s *ZodiacSimulator) valueVariances(ctx context.Context, drawCounts []int, trials int, values domain.BoxValues, inventory domain.BreathCollection) ([]float64, error) {
+	order := make([]int, len(drawCounts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return drawCounts[order[a]] < drawCounts[order[b]] })
+
+	// 1. 各區塊以 Welford 演算法累計各抽數總價值的平均與平方差和
+	type momentChunk struct {
+		n    float64
+		mean []float64
+		m2   []float64
+	}
+	chunks, err := runChunks(ctx, s.engine, trials, trialChunkSize, func(rng RNG, size int) momentChunk {
+		chunk := momentChunk{n: float64(size), mean: make([]float64, len(drawCounts)), m2: make([]float64, len(drawCounts))}
+		for t := 1; t <= size; t++ {
+			breaths := domain.NewBreathCollection().Add(inventory)
+			drawn, valued := 0, -1
+			var value float64
+			for _, i := range order {
+				for ; drawn < drawCounts[i]; drawn++ {
+					breaths[s.drawZodiac(rng)]++
+				}
+				// 抽數相同的投入金額沿用同一個湊箱結果
+				if drawn != valued {
+					value, valued = optimizeBoxes(s.event, breaths, values).Value, drawn
+				}
+				delta := value - chunk.mean[i]
+				chunk.mean[i] += delta / float64(t)
+				chunk.m2[i] += delta * (value - chunk.mean[i])
+			}
+		}
+		return chunk
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 依區塊順序合併平均與平方差和
+	var n float64
+	mean := make([]float64, len(drawCounts))
+	m2 := make([]float64, len(drawCounts))
+	for _, chunk := range chunks {
+		total := n + chunk.n
+		for i := range drawCounts {
+			delta := chunk.mean[i] - mean[i]
+			mean[i] += delta * chunk.n / total
+			m2[i] += chunk.m2[i] + delta*delta*n*chunk.n/total
+		}
+		n = total
+	}
+
+	variances := make([]float64, len(drawCounts))
+	for i := range variances {
+		if n > 0 {
+			variances[i] = m2[i] / n
+		}
+	}
+	return variances, nil
+}
+
+// DrawBreaths 實際抽 drawCount 次，回傳各生肖獲得數量
+func (s *ZodiacSimulator) DrawBreaths(rng RNG, drawCount int) domain.BreathCollection {
+	breaths := domain.NewBreathCollection()
+
+	for i := 0; i < drawCount; i++ {
+		breaths[s.drawZodiac(rng)]++
+	}
+
+	return breaths
+}
+
+// DrawsToAssemble 逐抽模擬直到可湊成 count 個指定心願箱，回傳所需抽數
+// 超過 maxDraws 仍未湊齊時回傳 maxDraws + 1
+func (s *ZodiacSimulator) DrawsToAssemble(rng RNG, boxType domain.BoxType, count int, inventory domain.BreathCollection, maxDraws int) int {
+	requirements := s.event.BoxRequirements[boxType]
+	breaths := inventory.Clone()
+
+	for draws := 0; draws <= maxDraws; draws++ {
+		if breaths.Min(requirements) >= float64(count) {
+			return draws
+		}
+		breaths[s.drawZodiac(rng)]++
+	}
+	return maxDraws + 1
+}
+
+// drawZodiac 根據生肖機率從模型的入口獎池抽取一個生肖
+func (s *ZodiacSimulator) drawZodiac(rng RNG) domain.Zodiac {
+	pool, _ := s.model.Pool(s.model.Entry)
+	return domain.Zodiac(drawReward(rng, pool.Rewards).Name)
+}
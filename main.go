@@ -2,11 +2,15 @@ package main
 
 import (
 	"MSCashItemExpected/internal/adapter"
+	"MSCashItemExpected/internal/repository"
 	"MSCashItemExpected/internal/usecase"
 	"embed"
+	"flag"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
+	"os"
 	"os/exec"
 	"runtime"
 )
@@ -15,24 +19,83 @@ import (
 var staticFiles embed.FS
 
 func main() {
+	eventPath := flag.String("event", "", "活動定義檔路徑（預設使用內嵌版本）")
+	purchasePath := flag.String("purchase", "", "購買方式定義檔路徑（預設為不限面額的購買方式）")
+	pricesPath := flag.String("prices", repository.DefaultPricesPath, "道具價格檔路徑（JSON 或 CSV，網頁與 /api/prices 讀寫此檔）")
+	historyPath := flag.String("price-history", "price_history.json", "價格歷史檔路徑（/api/prices/history 讀寫此檔）")
+	addr := flag.String("addr", "127.0.0.1:5278", "監聽位址（預設僅限本機連線，如需讓其他裝置連線可設為 :5278）")
+	flag.Parse()
+
+	// 載入活動定義
+	event, err := repository.LoadEvent(*eventPath)
+	if err != nil {
+		fmt.Printf("載入活動定義失敗: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 載入購買方式
+	purchases, err := repository.LoadPurchases(*purchasePath)
+	if err != nil {
+		fmt.Printf("載入購買方式失敗: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 載入道具價格表（檔案不存在時使用預設價格）
+	prices := usecase.NewPriceStore(repository.NewPriceFile(*pricesPath), event.PricedItems())
+	if _, err := prices.Prices(); err != nil {
+		fmt.Printf("載入道具價格失敗: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 載入價格歷史
+	history := usecase.NewPriceHistoryStore(repository.NewPriceHistoryFile(*historyPath))
+	if _, err := history.History(); err != nil {
+		fmt.Printf("載入價格歷史失敗: %v\n", err)
+		os.Exit(1)
+	}
+
 	// 初始化各層（依賴注入）
-	calculator := usecase.NewCalculator()
-	handler := adapter.NewHandler(calculator)
+	calculator := usecase.NewCalculator(event.Zodiac, usecase.WithPurchases(purchases))
+	starlightCalculator := usecase.NewStarlightCalculator(event.Starlight, usecase.WithPurchases(purchases))
+	zodiacSimulator := usecase.NewZodiacSimulator(event.Zodiac, usecase.WithPurchases(purchases))
+	planner := usecase.NewPlanner(event, usecase.WithPurchases(purchases))
+	handler := adapter.NewHandler(event, calculator, starlightCalculator, zodiacSimulator, planner, prices, history, usecase.NewRateTester(event))
 
 	// 設定 API 路由
 	http.HandleFunc("/api/calculate", handler.Calculate)
+	http.HandleFunc("/api/zodiac/simulate", handler.ZodiacSimulate)
+	http.HandleFunc("/api/zodiac/target", handler.ZodiacTarget)
+	http.HandleFunc("/api/starlight/expected", handler.StarlightExpected)
+	http.HandleFunc("/api/starlight/simulate", handler.StarlightSimulate)
+	http.HandleFunc("/api/starlight/ladder", handler.StarlightLadder)
+	http.HandleFunc("/api/starlight/policy", handler.StarlightPolicy)
+	http.HandleFunc("/api/starlight/target", handler.StarlightTarget)
+	http.HandleFunc("/api/plan", handler.Plan)
+	http.HandleFunc("/api/portfolio", handler.Portfolio)
+	http.HandleFunc("/api/event", handler.Event)
+	http.HandleFunc("/api/purchases", handler.Purchases)
+	http.HandleFunc("/api/prices", handler.Prices)
+	http.HandleFunc("/api/prices/history", handler.PriceHistory)
+	http.HandleFunc("/api/value/history", handler.ValueHistory)
+	http.HandleFunc("/api/rates/test", handler.RateTest)
+	http.HandleFunc("/api/rates/estimate", handler.RateEstimate)
+	http.HandleFunc("/api/luck", handler.Luck)
 
 	// 設定靜態檔案服務
 	staticFS, _ := fs.Sub(staticFiles, "static")
 	http.Handle("/", http.FileServer(http.FS(staticFS)))
 
-	port := "5278"
-	url := fmt.Sprintf("http://localhost:%s", port)
+	url, err := browserURL(*addr)
+	if err != nil {
+		fmt.Printf("監聽位址無效: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("=================================")
-	fmt.Println("  新年氣息期望值計算機")
+	fmt.Println("  現金道具期望值計算機")
 	fmt.Println("=================================")
-	fmt.Printf("伺服器啟動於 %s\n", url)
+	fmt.Printf("活動資料版本: %s\n", event.Version)
+	fmt.Printf("伺服器啟動於 %s（監聽 %s）\n", url, *addr)
 	fmt.Println("按 Ctrl+C 結束程式")
 	fmt.Println()
 
@@ -40,11 +103,23 @@ func main() {
 	go openBrowser(url)
 
 	// 啟動 HTTP 伺服器
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := http.ListenAndServe(*addr, nil); err != nil {
 		fmt.Printf("伺服器啟動失敗: %v\n", err)
 	}
 }
 
+// browserURL 由監聽位址取得瀏覽器開啟的網址，監聽所有介面時以 localhost 開啟
+func browserURL(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port), nil
+}
+
 // openBrowser 開啟預設瀏覽器
 func openBrowser(url string) {
 	var err error
@@ -3,29 +3,250 @@
 // ============================================
 
 /**
- * 根據購買方式計算可得點數
+ * 讀取 JSON 資料（本機伺服器的 API；GitHub Pages 為同路徑的靜態快照）
+ * @param {string} path - 資料路徑
+ * @returns {Promise<Object>} 解析後的資料
+ */
+async function fetchData(path) {
+    const response = await fetch(path);
+    if (!response.ok) {
+        throw new Error(`讀取 ${path} 失敗（${response.status}）`);
+    }
+    return response.json();
+}
+
+let eventPromise = null;
+let purchasesPromise = null;
+
+/**
+ * 讀取活動定義（機率、獎池與每抽成本，僅讀取一次）
+ * @returns {Promise<Object>} 與活動定義檔相同格式的活動定義
+ */
+function loadEvent() {
+    if (!eventPromise) {
+        eventPromise = fetchData('api/event').catch(e => {
+            eventPromise = null;
+            throw e;
+        });
+    }
+    return eventPromise;
+}
+
+/**
+ * 讀取購買方式規則（僅讀取一次）
+ * @returns {Promise<Object>} 購買方式代號 -> 規則
+ */
+function loadPurchases() {
+    if (!purchasesPromise) {
+        purchasesPromise = fetchData('api/purchases').then(methods => {
+            const rules = {};
+            for (const rule of methods) {
+                rules[rule.method] = rule;
+            }
+            return rules;
+        }).catch(e => {
+            purchasesPromise = null;
+            throw e;
+        });
+    }
+    return purchasesPromise;
+}
+
+/**
+ * 依進位方式處理點數
+ */
+function applyRounding(rounding, points) {
+    switch (rounding) {
+        case 'round':
+            return Math.round(points);
+        case 'floor':
+            return Math.floor(points);
+        case 'ceil':
+            return Math.ceil(points);
+        default:
+            return points;
+    }
+}
+
+/**
+ * 最大公因數
+ */
+function gcd(a, b) {
+    while (b !== 0) {
+        [a, b] = [b, a % b];
+    }
+    return a;
+}
+
+// 點卡組合搜尋的狀態數上限
+const MAX_CARD_STATES = 1000000;
+
+/**
+ * 在售價總和不超過 budget 的點卡組合中，求點數（含贈送）最多者
+ * @returns {{points: number, price: number}} 點數與售價總和
+ */
+function bestCards(denominations, budget) {
+    const total = d => d.points + d.bonus;
+
+    // 點數/售價比最佳的不限張數面額
+    let ratio = null;
+    for (const d of denominations) {
+        if (d.limit === 0 && (!ratio || total(d) / d.price > total(ratio) / ratio.price)) {
+            ratio = d;
+        }
+    }
+
+    // 所有面額皆有張數上限時，超過全部點卡售價的預算用不到
+    if (!ratio) {
+        budget = Math.min(budget, denominations.reduce((sum, d) => sum + d.limit * d.price, 0));
+    }
+    if (budget <= 0) {
+        return { points: 0, price: 0 };
+    }
+
+    // 以所有面額售價的最大公因數為單位，降低狀態數
+    const unit = denominations.reduce((u, d) => gcd(u, d.price), 0);
+    const states = Math.floor(budget / unit);
+
+    // 預算過大時，先以點數/售價比最佳的不限張數面額填滿超出的部分
+    if (states > MAX_CARD_STATES && ratio) {
+        const n = Math.floor((states - MAX_CARD_STATES) * unit / ratio.price) + 1;
+        const rest = bestCards(denominations, budget - n * ratio.price);
+        return { points: rest.points + n * total(ratio), price: rest.price + n * ratio.price };
+    }
+
+    // best[c]：售價恰為 c 個單位時的最多點數；有張數上限的面額拆成 1, 2, 4…張的組別
+    const best = new Float64Array(states + 1).fill(-Infinity);
+    best[0] = 0;
+    for (const d of denominations) {
+        const step = d.price / unit;
+        if (d.limit === 0) {
+            for (let c = step; c <= states; c++) {
+                best[c] = Math.max(best[c], best[c - step] + total(d));
+            }
+            continue;
+        }
+        for (let size = 1, left = d.limit; left > 0; size *= 2) {
+            size = Math.min(size, left);
+            left -= size;
+            for (let c = states; c >= size * step; c--) {
+                best[c] = Math.max(best[c], best[c - size * step] + size * total(d));
+            }
+        }
+    }
+
+    // 點數最多的總售價（點數相同取售價較低者）
+    let chosen = 0;
+    for (let c = 1; c <= states; c++) {
+        if (best[c] > best[chosen]) {
+            chosen = c;
+        }
+    }
+    return { points: best[chosen], price: chosen * unit };
+}
+
+/**
+ * 根據購買方式計算實際花費與可得點數（規則與伺服器 /api/purchases 相同）
  * @param {number} investment - 投入金額
- * @param {string} method - 購買方式 (card, cardreader, original, gift)
+ * @param {string} method - 購買方式代號 (card, cardreader, original, gift)
  * @param {number} discount - 折扣數
- * @returns {number} 可得點數
+ * @returns {Promise<{cost: number, points: number}>} 實際花費與可得點數
  */
-function calculatePoints(investment, method, discount) {
-    switch (method) {
-        case 'card':
-            // 點卡 xx 折：投入金額 ÷ 折數（四捨五入）
-            return discount > 0 ? Math.round(investment / discount) : investment;
-        case 'cardreader':
-            // 讀卡機 5% 回饋
-            return investment * 1.05;
-        case 'original':
-            // 原價
-            return investment;
-        case 'gift':
-            // 送禮 xx 折：投入金額 ÷ 折數
-            return discount > 0 ? Math.round(investment / discount) : investment;
-        default:
-            return investment;
+async function calculatePurchase(investment, method, discount) {
+    const rules = await loadPurchases();
+    const rule = rules[method] || { denominations: [], use_discount: false, bonus_rate: 0, rounding: 'none', max_investment: 0 };
+
+    const d = rule.use_discount && discount > 0 ? discount : 1;
+    let budget = Math.max(investment, 0);
+    if (rule.max_investment > 0) {
+        budget = Math.min(budget, rule.max_investment);
+    }
+
+    let cost = budget;
+    let raw = budget / d;
+    if (rule.denominations && rule.denominations.length > 0) {
+        // 點卡面額：在預算內求點數最多的組合
+        const cards = bestCards(rule.denominations, Math.floor(budget / d + 1e-9));
+        cost = cards.price * d;
+        raw = cards.points;
     }
+
+    return { cost: cost, points: applyRounding(rule.rounding, raw * (1 + rule.bonus_rate)) };
+}
+
+// ============================================
+// 道具價格表（由本機伺服器 /api/prices 保存）
+// ============================================
+
+/**
+ * 讀取伺服器保存的道具價格表
+ * @returns {Promise<Object|null>} 道具名稱 -> 價格，無伺服器時（如 GitHub Pages）回傳 null
+ */
+async function loadPriceBook() {
+    try {
+        const response = await fetch('api/prices');
+        if (!response.ok) {
+            return null;
+        }
+        const data = await response.json();
+        return data.prices;
+    } catch (e) {
+        return null;
+    }
+}
+
+/**
+ * 將道具價格合併至伺服器保存的價格表（只更新傳入的道具，不會覆寫其他分頁同時保存的價格）
+ * @param {Object} prices - 道具名稱 -> 價格
+ * @returns {Promise<Object>} 保存後的價格表
+ */
+async function savePriceBook(prices) {
+    const response = await fetch('api/prices', {
+        method: 'PATCH',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ prices: prices })
+    });
+    if (!response.ok) {
+        throw new Error(await response.text());
+    }
+    const data = await response.json();
+    return data.prices;
+}
+
+/**
+ * 以保存的價格表填入輸入框，並啟用儲存按鈕（無伺服器時按鈕維持隱藏）
+ * @param {Object} inputMap - 道具名稱 -> 輸入框ID
+ * @param {string} buttonId - 儲存按鈕ID
+ */
+async function bindPriceBook(inputMap, buttonId) {
+    const button = document.getElementById(buttonId);
+    const prices = await loadPriceBook();
+    if (!button || !prices) return;
+
+    for (const [item, inputId] of Object.entries(inputMap)) {
+        const input = document.getElementById(inputId);
+        if (input && prices[item] > 0) {
+            input.value = prices[item];
+        }
+    }
+
+    button.style.display = 'block';
+    button.addEventListener('click', async function() {
+        const updated = {};
+        for (const [item, inputId] of Object.entries(inputMap)) {
+            const input = document.getElementById(inputId);
+            if (input) {
+                updated[item] = Math.max(0, Math.round(parseFloat(input.value) || 0));
+            }
+        }
+
+        try {
+            await savePriceBook(updated);
+            alert('已儲存價格');
+        } catch (e) {
+            alert('儲存價格失敗：' + e.message);
+        }
+    });
 }
 
 // ============================================
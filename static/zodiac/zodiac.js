@@ -2,38 +2,42 @@
 // 新年氣息 - 常數定義
 // ============================================
 
-// 生肖機率 (%)
-const ZODIAC_RATES = {
-    '馬': 0.15,
-    '羊': 0.20,
-    '猴': 0.25,
-    '雞': 0.80,
-    '狗': 0.90,
-    '豬': 1.00,
-    '鼠': 2.50,
-    '牛': 3.00,
-    '虎': 3.50,
-    '兔': 29.20,
-    '龍': 29.25,
-    '蛇': 29.25
+// 機率、心願箱組成、優先順序與每抽成本由活動定義（/api/event）取得
+
+// 生肖到已持有氣息輸入框ID的映射（按機率從低到高）
+const INVENTORY_INPUT_MAP = {
+    '馬': 'inv-horse',
+    '羊': 'inv-goat',
+    '猴': 'inv-monkey',
+    '雞': 'inv-rooster',
+    '狗': 'inv-dog',
+    '豬': 'inv-pig',
+    '鼠': 'inv-rat',
+    '牛': 'inv-ox',
+    '虎': 'inv-tiger',
+    '兔': 'inv-rabbit',
+    '龍': 'inv-dragon',
+    '蛇': 'inv-snake'
 };
 
-// 心願箱組成（需湊齊各1個）
-const BOX_REQUIREMENTS = {
-    '小吉': ['兔', '龍', '蛇'],
-    '中吉': ['兔', '龍', '蛇', '虎', '牛', '鼠'],
-    '大吉': ['兔', '龍', '蛇', '虎', '牛', '鼠', '豬', '狗', '雞'],
-    '超越': ['兔', '龍', '蛇', '虎', '牛', '鼠', '豬', '狗', '雞', '猴', '羊', '馬']
+// 生肖順序（按機率從低到高）
+const ZODIAC_ORDER = Object.keys(INVENTORY_INPUT_MAP);
+
+// 心願箱到價值輸入框ID的映射（價格表以心願箱類型為鍵）
+const BOX_INPUT_MAP = {
+    '小吉': 'box-small',
+    '中吉': 'box-medium',
+    '大吉': 'box-large',
+    '超越': 'box-super'
 };
 
-// 心願箱優先順序（高價值優先）
-const BOX_PRIORITY = ['超越', '大吉', '中吉', '小吉'];
-
-// 每抽成本（點數）
-const ZODIAC_COST_PER_DRAW = 27;
-
-// 生肖順序（按機率從低到高）
-const ZODIAC_ORDER = ['馬', '羊', '猴', '雞', '狗', '豬', '鼠', '牛', '虎', '兔', '龍', '蛇'];
+// 心願箱到價值欄位的映射
+const BOX_VALUE_KEYS = {
+    '小吉': 'small',
+    '中吉': 'medium',
+    '大吉': 'large',
+    '超越': 'super'
+};
 
 // ============================================
 // 新年氣息 - 計算函數
@@ -42,9 +46,9 @@ const ZODIAC_ORDER = ['馬', '羊', '猴', '雞', '狗', '豬', '鼠', '牛', '
 /**
  * 計算期望獲得的氣息數量
  */
-function calculateExpectedBreaths(drawCount) {
+function calculateExpectedBreaths(event, drawCount) {
     const breaths = {};
-    for (const [zodiac, rate] of Object.entries(ZODIAC_RATES)) {
+    for (const [zodiac, rate] of Object.entries(event.rates)) {
         breaths[zodiac] = drawCount * (rate / 100);
     }
     return breaths;
@@ -53,19 +57,15 @@ function calculateExpectedBreaths(drawCount) {
 /**
  * 計算期望心願箱數量（貪心算法，優先湊高價值）
  */
-function calculateExpectedBoxes(breaths) {
+function calculateExpectedBoxes(event, breaths) {
     // 複製一份，避免修改原始資料
     const remaining = { ...breaths };
-    const result = {
-        '超越': 0,
-        '大吉': 0,
-        '中吉': 0,
-        '小吉': 0
-    };
+    const result = {};
 
     // 按優先順序湊箱
-    for (const boxType of BOX_PRIORITY) {
-        const requirements = BOX_REQUIREMENTS[boxType];
+    for (const boxType of event.box_priority) {
+        result[boxType] = 0;
+        const requirements = event.box_requirements[boxType];
 
         // 找出可湊的箱數（取最小值）
         let minCount = Infinity;
@@ -87,40 +87,143 @@ function calculateExpectedBoxes(breaths) {
     return result;
 }
 
+/**
+ * 取得指定生肖中的最小數量
+ */
+function minBreaths(breaths, zodiacs) {
+    return Math.min(...zodiacs.map(zodiac => breaths[zodiac] || 0));
+}
+
+/**
+ * 將心願箱依組成由少到多排序，並確認每一種都包含前一種的所有生肖（不為巢狀時回傳 null）
+ */
+function boxChain(event) {
+    const chain = [...event.box_priority].sort(
+        (a, b) => event.box_requirements[a].length - event.box_requirements[b].length
+    );
+    for (let j = 1; j < chain.length; j++) {
+        const contains = new Set(event.box_requirements[chain[j]]);
+        if (!event.box_requirements[chain[j - 1]].every(zodiac => contains.has(zodiac))) {
+            return null;
+        }
+    }
+    return chain.length > 0 ? chain : null;
+}
+
+/**
+ * 在給定氣息下找出總價值最高的心願箱組合（與伺服器的湊箱方式相同）
+ *
+ * 心願箱組成為巢狀結構（小吉 ⊂ 中吉 ⊂ 大吉 ⊂ 超越），令 y_j 為第 j 層以上的箱數、
+ * d_j 為相鄰兩層的價值差，總價值即為 Σ d_j·y_j，y_j 不超過該層以下各層生肖的最小數量且隨層數遞減，
+ * 最佳解的 y_j 必落在 0 或某一層的上限。組成不為巢狀或最佳組合沒有比貪心算法更好時，回報貪心算法的結果。
+ */
+function optimizeBoxes(event, breaths, boxValues) {
+    const greedy = calculateExpectedBoxes(event, breaths);
+    const greedyValue = calculateZodiacExpectedValue(greedy, boxValues);
+
+    const chain = boxChain(event);
+    if (!chain) {
+        return greedy;
+    }
+
+    // 各層上限與相鄰兩層的價值差
+    const value = boxType => boxValues[BOX_VALUE_KEYS[boxType]] || 0;
+    const caps = chain.map(boxType => minBreaths(breaths, event.box_requirements[boxType]));
+    const deltas = chain.map((boxType, j) => value(boxType) - (j > 0 ? value(chain[j - 1]) : 0));
+
+    // 列舉遞減的 y 組合
+    let best = null;
+    let bestValue = -Infinity;
+    const current = new Array(chain.length).fill(0);
+
+    function search(j, upper, total) {
+        if (j === chain.length) {
+            if (total > bestValue) {
+                bestValue = total;
+                best = [...current];
+            }
+            return;
+        }
+        // caps 隨層數遞減，因此 caps[m]（m ≥ j）皆不超過本層上限
+        for (const y of [0, ...caps.slice(j)]) {
+            if (y > upper) {
+                continue;
+            }
+            current[j] = y;
+            search(j + 1, y, total + deltas[j] * y);
+        }
+    }
+    search(0, Infinity, 0);
+
+    if (bestValue <= greedyValue + 1e-9) {
+        return greedy;
+    }
+
+    // y 轉回各心願箱數量：x_j = y_j - y_{j+1}
+    const boxes = {};
+    chain.forEach((boxType, j) => {
+        boxes[boxType] = best[j] - (j + 1 < chain.length ? best[j + 1] : 0);
+    });
+    return boxes;
+}
+
+/**
+ * 合併已持有氣息與新抽氣息（未填寫的生肖視為 0）
+ */
+function addBreaths(breaths, inventory) {
+    const total = {};
+    for (const zodiac of ZODIAC_ORDER) {
+        total[zodiac] = (breaths[zodiac] || 0) + (inventory[zodiac] || 0);
+    }
+    return total;
+}
+
 /**
  * 計算期望總價值
  */
 function calculateZodiacExpectedValue(boxes, boxValues) {
-    return boxes['小吉'] * boxValues.small +
-           boxes['中吉'] * boxValues.medium +
-           boxes['大吉'] * boxValues.large +
-           boxes['超越'] * boxValues.super;
+    let total = 0;
+    for (const [boxType, count] of Object.entries(boxes)) {
+        total += count * (boxValues[BOX_VALUE_KEYS[boxType]] || 0);
+    }
+    return total;
 }
 
 /**
  * 新年氣息主計算函數
  */
-function calculateZodiac(investment, method, discount, boxValues) {
-    // 1. 計算可得點數
-    const points = calculatePoints(investment, method, discount);
+async function calculateZodiac(investment, method, discount, boxValues, inventory) {
+    const event = (await loadEvent()).zodiac;
+
+    // 1. 計算實際花費與可得點數
+    const purchase = await calculatePurchase(investment, method, discount);
+    const cost = purchase.cost;
+    const points = purchase.points;
 
     // 2. 計算可抽次數
-    const drawCount = points / ZODIAC_COST_PER_DRAW;
+    const drawCount = points / event.cost_per_draw;
 
     // 3. 計算每個氣息的實際成本
-    const costPerBreath = drawCount > 0 ? investment / drawCount : 0;
+    const costPerBreath = drawCount > 0 ? cost / drawCount : 0;
 
     // 4. 計算期望獲得各氣息數量
-    const expectedBreaths = calculateExpectedBreaths(drawCount);
-
-    // 5. 計算期望可湊心願箱數量（貪心算法）
-    const expectedBoxes = calculateExpectedBoxes(expectedBreaths);
+    const expectedBreaths = calculateExpectedBreaths(event, drawCount);
+
+    // 5. 計算期望可湊心願箱數量（已持有 + 新抽，依心願箱價值求最佳組合）
+    const expectedBoxes = optimizeBoxes(event, addBreaths(expectedBreaths, inventory), boxValues);
+    const inventoryBoxes = optimizeBoxes(event, addBreaths({}, inventory), boxValues);
+    const newBoxes = {};
+    for (const boxType of event.box_priority) {
+        newBoxes[boxType] = (expectedBoxes[boxType] || 0) - (inventoryBoxes[boxType] || 0);
+    }
 
-    // 6. 計算期望總價值
+    // 6. 計算期望總價值與本次購買增加的價值
     const expectedValue = calculateZodiacExpectedValue(expectedBoxes, boxValues);
+    const inventoryValue = calculateZodiacExpectedValue(inventoryBoxes, boxValues);
+    const addedValue = expectedValue - inventoryValue;
 
-    // 7. 計算報酬率
-    const roi = investment > 0 ? ((expectedValue - investment) / investment) * 100 : 0;
+    // 7. 計算報酬率（僅計入本次購買增加的價值）
+    const roi = cost > 0 ? ((addedValue - cost) / cost) * 100 : 0;
 
     return {
         points: points,
@@ -128,8 +231,13 @@ function calculateZodiac(investment, method, discount, boxValues) {
         cost_per_breath: costPerBreath,
         expected_breaths: expectedBreaths,
         expected_boxes: expectedBoxes,
+        inventory_boxes: inventoryBoxes,
+        new_boxes: newBoxes,
         expected_value: expectedValue,
-        roi: roi
+        inventory_value: inventoryValue,
+        added_value: addedValue,
+        roi: roi,
+        has_inventory: Object.keys(inventory).length > 0
     };
 }
 
@@ -138,12 +246,14 @@ function calculateZodiac(investment, method, discount, boxValues) {
 // ============================================
 
 document.addEventListener('DOMContentLoaded', function() {
+    bindPriceBook(BOX_INPUT_MAP, 'save-box-values-btn');
+
     const calculateBtn = document.getElementById('calculate-btn');
     const resultDiv = document.getElementById('result');
 
     if (!calculateBtn) return;
 
-    calculateBtn.addEventListener('click', function() {
+    calculateBtn.addEventListener('click', async function() {
         const investment = parseFloat(document.getElementById('investment').value) || 0;
         const method = document.querySelector('input[name="method"]:checked').value;
 
@@ -161,13 +271,26 @@ document.addEventListener('DOMContentLoaded', function() {
             super: parseFloat(document.getElementById('box-super').value) || 0
         };
 
+        // 收集已持有氣息
+        const inventory = {};
+        for (const [zodiac, inputId] of Object.entries(INVENTORY_INPUT_MAP)) {
+            const count = parseFloat(document.getElementById(inputId).value) || 0;
+            if (count > 0) {
+                inventory[zodiac] = count;
+            }
+        }
+
         if (investment <= 0) {
             alert('請輸入投入資金');
             return;
         }
 
-        const result = calculateZodiac(investment, method, discount, boxValues);
-        displayZodiacResult(result);
+        try {
+            const result = await calculateZodiac(investment, method, discount, boxValues, inventory);
+            displayZodiacResult(result);
+        } catch (e) {
+            alert('計算失敗：' + e.message);
+        }
     });
 
     function displayZodiacResult(result) {
@@ -198,6 +321,16 @@ document.addEventListener('DOMContentLoaded', function() {
         document.getElementById('r-box-medium').textContent = (result.expected_boxes['中吉'] || 0).toFixed(4);
         document.getElementById('r-box-small').textContent = (result.expected_boxes['小吉'] || 0).toFixed(4);
 
+        // 本次購買新解鎖心願箱（有已持有氣息時才顯示）
+        const newSection = document.getElementById('r-new-section');
+        newSection.style.display = result.has_inventory ? 'block' : 'none';
+        document.getElementById('r-new-super').textContent = (result.new_boxes['超越'] || 0).toFixed(4);
+        document.getElementById('r-new-large').textContent = (result.new_boxes['大吉'] || 0).toFixed(4);
+        document.getElementById('r-new-medium').textContent = (result.new_boxes['中吉'] || 0).toFixed(4);
+        document.getElementById('r-new-small').textContent = (result.new_boxes['小吉'] || 0).toFixed(4);
+        document.getElementById('r-inventory-value').textContent = result.inventory_value.toFixed(2) + ' 元';
+        document.getElementById('r-added-value').textContent = result.added_value.toFixed(2) + ' 元';
+
         // 期望總價值與報酬率
         document.getElementById('r-value').textContent = result.expected_value.toFixed(2) + ' 元';
 